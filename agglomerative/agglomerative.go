@@ -0,0 +1,260 @@
+package agglomerative
+
+import (
+	"math"
+	"mygoml"
+	"sort"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// Merge is a step of the dendrogram. Clusters are numbered like the data
+// points for the single point clusters, and n+i for the cluster made by
+// merge i when there are n data points.
+type Merge struct {
+	Left, Right int
+	Distance    float64
+	// Size is the number of data points of the merged cluster
+	Size int
+}
+
+// Model starts with a cluster per data point and repeatedly merges the two
+// closest clusters. It keeps the whole dendrogram, which takes memory
+// quadratic in the number of data points to build.
+type Model struct {
+	Linkage Linkage
+	// ClusterCount is the number of clusters to stop at, 2 when 0
+	ClusterCount int
+	// DistanceThreshold, when positive, stops merging when the closest
+	// clusters are further apart than it, instead of at ClusterCount
+	DistanceThreshold float64
+	dendrogram        []Merge
+	labels            []int
+}
+
+// Clustering builds the dendrogram of ds and cuts it as set by
+// ClusterCount or DistanceThreshold.
+func (m *Model) Clustering(ds mygoml.UnsupervisedDataSet) []mygoml.Cluster {
+	dps := ds.DataPoints()
+	m.dendrogram, m.labels = nil, nil
+	if len(dps) == 0 {
+		return nil
+	}
+	X := make([][]float64, len(dps))
+	for i, dp := range dps {
+		X[i] = dp.Features()
+	}
+	m.dendrogram = m.build(X)
+
+	if m.DistanceThreshold > 0 {
+		m.labels = m.CutDistance(m.DistanceThreshold)
+	} else {
+		count := m.ClusterCount
+		if count <= 0 {
+			count = 2
+		}
+		m.labels = m.Cut(count)
+	}
+
+	var clusters []*mygoml.BasicCluster
+	for i, label := range m.labels {
+		if label == len(clusters) {
+			clusters = append(clusters, &mygoml.BasicCluster{})
+		}
+		clusters[label].Add(dps[i])
+	}
+	var out []mygoml.Cluster
+	for _, c := range clusters {
+		out = append(out, c)
+	}
+	return out
+}
+
+// build merges the clusters with the nearest-neighbor chain algorithm,
+// which follows a chain of nearest neighbors until two clusters are each
+// other's nearest and merges them. It finds the same merges as always
+// merging the closest pair since all the linkages are reducible, but in
+// another order, so the merges are sorted by distance at the end.
+func (m *Model) build(X [][]float64) []Merge {
+	n := len(X)
+	d := make([][]float64, n)
+	for i := range d {
+		d[i] = make([]float64, n)
+		for j := 0; j < i; j++ {
+			d[i][j] = floats.Distance(X[i], X[j], 2)
+			d[j][i] = d[i][j]
+		}
+	}
+
+	// a cluster lives in the slot of one of its data points
+	size := make([]float64, n)
+	active := make([]int, n)
+	for i := range active {
+		size[i] = 1
+		active[i] = i
+	}
+	type step struct {
+		a, b     int
+		distance float64
+	}
+	steps := make([]step, 0, n-1)
+	var chain []int
+	for len(active) > 1 {
+		if len(chain) == 0 {
+			chain = append(chain, active[0])
+		}
+		var a, b int
+		for {
+			a = chain[len(chain)-1]
+			// prefer the previous cluster of the chain on ties, which
+			// keeps the chain from cycling
+			b = -1
+			best := math.Inf(1)
+			if len(chain) > 1 {
+				b = chain[len(chain)-2]
+				best = d[a][b]
+			}
+			for _, k := range active {
+				if k != a && d[a][k] < best {
+					b, best = k, d[a][k]
+				}
+			}
+			if len(chain) > 1 && b == chain[len(chain)-2] {
+				break
+			}
+			chain = append(chain, b)
+		}
+		chain = chain[:len(chain)-2]
+		steps = append(steps, step{a, b, d[a][b]})
+
+		// the merged cluster takes the slot of b
+		for _, k := range active {
+			if k != a && k != b {
+				d[b][k] = m.Linkage.update(d[a][k], d[b][k], d[a][b], size[a], size[b], size[k])
+				d[k][b] = d[b][k]
+			}
+		}
+		size[b] += size[a]
+		for i, k := range active {
+			if k == a {
+				active = append(active[:i], active[i+1:]...)
+				break
+			}
+		}
+	}
+
+	sort.SliceStable(steps, func(i, j int) bool {
+		return steps[i].distance < steps[j].distance
+	})
+	u := newUnion(n)
+	merges := make([]Merge, len(steps))
+	for i, s := range steps {
+		left, right := u.id[u.find(s.a)], u.id[u.find(s.b)]
+		if left > right {
+			left, right = right, left
+		}
+		root := u.union(s.a, s.b)
+		u.id[root] = n + i
+		merges[i] = Merge{Left: left, Right: right, Distance: s.distance, Size: u.size[root]}
+	}
+	return merges
+}
+
+// Dendrogram returns the merges of the last clustering, closest first.
+func (m *Model) Dendrogram() []Merge {
+	return m.dendrogram
+}
+
+// Labels returns the cluster of every data point of the last clustering,
+// in the order of Clustering.
+func (m *Model) Labels() []int {
+	return m.labels
+}
+
+// Cut returns the cluster of every data point when the dendrogram is cut
+// into count clusters. Clusters are numbered in the order of their first
+// data point.
+func (m *Model) Cut(count int) []int {
+	n := len(m.dendrogram) + 1
+	if count < 1 {
+		count = 1
+	}
+	if count > n {
+		count = n
+	}
+	return m.cut(n - count)
+}
+
+// CutDistance returns the cluster of every data point when the dendrogram
+// is cut at threshold, so that no merge is further apart than it.
+func (m *Model) CutDistance(threshold float64) []int {
+	merges := sort.Search(len(m.dendrogram), func(i int) bool {
+		return m.dendrogram[i].Distance > threshold
+	})
+	return m.cut(merges)
+}
+
+// cut replays the first merges of the dendrogram.
+func (m *Model) cut(merges int) []int {
+	if m.dendrogram == nil {
+		return nil
+	}
+	n := len(m.dendrogram) + 1
+	u := newUnion(n)
+	// the data points under every cluster of the dendrogram so far
+	first := make([]int, 2*n-1)
+	for i := 0; i < n; i++ {
+		first[i] = i
+	}
+	for i, merge := range m.dendrogram[:merges] {
+		u.union(first[merge.Left], first[merge.Right])
+		first[n+i] = first[merge.Left]
+	}
+	labels := make([]int, n)
+	numbers := make(map[int]int)
+	for i := range labels {
+		root := u.find(i)
+		if _, ok := numbers[root]; !ok {
+			numbers[root] = len(numbers)
+		}
+		labels[i] = numbers[root]
+	}
+	return labels
+}
+
+// union is a disjoint-set forest over the data points.
+type union struct {
+	parent []int
+	size   []int
+	// id is the dendrogram number of the cluster of every root
+	id []int
+}
+
+func newUnion(n int) *union {
+	u := &union{parent: make([]int, n), size: make([]int, n), id: make([]int, n)}
+	for i := range u.parent {
+		u.parent[i] = i
+		u.size[i] = 1
+		u.id[i] = i
+	}
+	return u
+}
+
+func (u *union) find(i int) int {
+	for u.parent[i] != i {
+		u.parent[i] = u.parent[u.parent[i]]
+		i = u.parent[i]
+	}
+	return i
+}
+
+// union joins the sets of a and b and returns the new root.
+func (u *union) union(a, b int) int {
+	ra, rb := u.find(a), u.find(b)
+	if u.size[ra] < u.size[rb] {
+		ra, rb = rb, ra
+	}
+	u.parent[rb] = ra
+	u.size[ra] += u.size[rb]
+	return ra
+}
@@ -0,0 +1,53 @@
+package agglomerative
+
+import (
+	"mygoml"
+	"testing"
+)
+
+func TestClustering(t *testing.T) {
+	d := mygoml.Grids(0, 10)
+	expected := []int{0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 1, 1, 1, 1, 1, 1, 1}
+	for _, linkage := range []Linkage{Single, Complete, Average, Ward} {
+		t.Run(linkage.String(), func(t *testing.T) {
+			m := &Model{Linkage: linkage}
+			clusters := m.Clustering(d)
+			if len(clusters) != 2 || len(clusters[0].Members()) != 9 || len(clusters[1].Members()) != 9 {
+				t.Fatalf("expected two clusters of 9 data points, got %d clusters", len(clusters))
+			}
+			mygoml.DeepEqual(t, "labels", expected, m.Labels())
+
+			merges := m.Dendrogram()
+			if len(merges) != len(d)-1 || merges[len(merges)-1].Size != len(d) {
+				t.Fatalf("expected %d merges ending with all data points, got %v", len(d)-1, merges)
+			}
+			for i := 1; i < len(merges); i++ {
+				if merges[i].Distance < merges[i-1].Distance {
+					t.Errorf("expected merges sorted by distance, got %v", merges)
+					break
+				}
+			}
+			mygoml.DeepEqual(t, "cut 1", make([]int, len(d)), m.Cut(1))
+			mygoml.DeepEqual(t, "cut distance", expected, m.CutDistance(5))
+		})
+	}
+}
+
+func TestDendrogram(t *testing.T) {
+	d := mygoml.Samples{{0}, {1}, {3}, {7}}
+	tests := map[Linkage][]float64{
+		Single:   {1, 2, 4},
+		Complete: {1, 3, 7},
+		Average:  {1, 2.5, 17.0 / 3},
+	}
+	for linkage, distances := range tests {
+		m := &Model{Linkage: linkage, ClusterCount: 1}
+		m.Clustering(d)
+		for i, merge := range m.Dendrogram() {
+			mygoml.FloatEqual(t, linkage.String(), distances[i], merge.Distance)
+			if merge.Size != i+2 {
+				t.Errorf("%v: expected merge %d to hold %d data points, got %d", linkage, i, i+2, merge.Size)
+			}
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package agglomerative
+
+import (
+	"fmt"
+	"math"
+)
+
+// Linkage is the distance between two clusters, computed from the
+// Euclidean distances between their members.
+type Linkage int
+
+const (
+	// Single is the distance between the closest members
+	Single Linkage = iota
+	// Complete is the distance between the farthest members
+	Complete
+	// Average is the mean distance between the members
+	Average
+	// Ward merges the clusters that least increase the within-cluster
+	// variance
+	Ward
+)
+
+func (l Linkage) String() string {
+	switch l {
+	case Single:
+		return "single"
+	case Complete:
+		return "complete"
+	case Average:
+		return "average"
+	case Ward:
+		return "ward"
+	}
+	return fmt.Sprintf("Linkage(%d)", int(l))
+}
+
+// update returns the distance between cluster k and the union of clusters
+// a and b with the Lance-Williams formula, from the distances dak, dbk and
+// dab and the sizes of the clusters.
+func (l Linkage) update(dak, dbk, dab, na, nb, nk float64) float64 {
+	switch l {
+	case Single:
+		return math.Min(dak, dbk)
+	case Complete:
+		return math.Max(dak, dbk)
+	case Average:
+		return (na*dak + nb*dbk) / (na + nb)
+	}
+	squared := ((na+nk)*dak*dak + (nb+nk)*dbk*dbk - nk*dab*dab) / (na + nb + nk)
+	return math.Sqrt(math.Max(squared, 0))
+}
@@ -0,0 +1,57 @@
+package agglomerative
+
+import (
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+)
+
+// leafOrder returns the data points in the order they appear at the bottom
+// of the dendrogram, so that no branches cross.
+func leafOrder(merges []Merge) []int {
+	n := len(merges) + 1
+	var order []int
+	var visit func(id int)
+	visit = func(id int) {
+		if id < n {
+			order = append(order, id)
+			return
+		}
+		visit(merges[id-n].Left)
+		visit(merges[id-n].Right)
+	}
+	visit(2*n - 2)
+	return order
+}
+
+// AddDendrogram draws merges on p, the data points spread along X and
+// every merge drawn as a bracket at the height of its distance. It returns
+// the data points in the order of their X positions, 0 to n-1.
+func AddDendrogram(p *plot.Plot, merges []Merge) ([]int, error) {
+	if len(merges) == 0 {
+		return nil, nil
+	}
+	n := len(merges) + 1
+	order := leafOrder(merges)
+	x := make([]float64, 2*n-1)
+	height := make([]float64, 2*n-1)
+	for position, i := range order {
+		x[i] = float64(position)
+	}
+	for i, merge := range merges {
+		l, r := merge.Left, merge.Right
+		bracket := plotter.XYs{
+			{X: x[l], Y: height[l]},
+			{X: x[l], Y: merge.Distance},
+			{X: x[r], Y: merge.Distance},
+			{X: x[r], Y: height[r]},
+		}
+		line, err := plotter.NewLine(bracket)
+		if err != nil {
+			return nil, err
+		}
+		p.Add(line)
+		x[n+i] = (x[l] + x[r]) / 2
+		height[n+i] = merge.Distance
+	}
+	return order, nil
+}
@@ -0,0 +1,114 @@
+package autodiff
+
+import (
+	"mygoml/graddesc"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Node is a matrix valued expression. Scalars are 1x1 matrices.
+type Node struct {
+	value    *mat.Dense
+	grad     *mat.Dense
+	parents  []*Node
+	backward func(grad *mat.Dense)
+}
+
+func newNode(value *mat.Dense, backward func(grad *mat.Dense), parents ...*Node) *Node {
+	return &Node{value: value, parents: parents, backward: backward}
+}
+
+// Constant wraps a matrix that gradients are not needed for.
+func Constant(m mat.Matrix) *Node {
+	return newNode(mat.DenseCopyOf(m), nil)
+}
+
+func Scalar(v float64) *Node {
+	return newNode(mat.NewDense(1, 1, []float64{v}), nil)
+}
+
+// Variable wraps a matrix whose gradient is accumulated by Backward.
+func Variable(m mat.Matrix) *Node {
+	n := Constant(m)
+	n.backward = func(*mat.Dense) {}
+	return n
+}
+
+func (n *Node) Value() mat.Matrix {
+	return n.value
+}
+
+func (n *Node) Dims() (int, int) {
+	return n.value.Dims()
+}
+
+// Grad returns the gradient computed by the last Backward call.
+func (n *Node) Grad() mat.Matrix {
+	if n.grad == nil {
+		r, c := n.Dims()
+		return mat.NewDense(r, c, nil)
+	}
+	return n.grad
+}
+
+func (n *Node) accumulate(grad mat.Matrix) {
+	if n.backward == nil {
+		return
+	}
+	if n.grad == nil {
+		n.grad = mat.DenseCopyOf(grad)
+		return
+	}
+	n.grad.Add(n.grad, grad)
+}
+
+// needsGrad reports whether a gradient can flow from n to a Variable.
+func (n *Node) needsGrad() bool {
+	return n.backward != nil
+}
+
+// Backward computes d(root)/d(node) for every node reachable from root,
+// root being a scalar.
+func Backward(root *Node) {
+	var order []*Node
+	visited := map[*Node]bool{}
+	var visit func(n *Node)
+	visit = func(n *Node) {
+		if visited[n] {
+			return
+		}
+		visited[n] = true
+		for _, p := range n.parents {
+			visit(p)
+		}
+		n.grad = nil
+		order = append(order, n)
+	}
+	visit(root)
+
+	root.accumulate(mat.NewDense(1, 1, []float64{1}))
+	for i := len(order) - 1; i >= 0; i-- {
+		n := order[i]
+		if n.grad != nil && n.backward != nil {
+			n.backward(n.grad)
+		}
+	}
+}
+
+// NewFunction builds a graddesc.Function from a scalar loss expression of
+// x, a 1 x inputSize row vector. Use Slice and Reshape to get parameter
+// matrices out of x.
+func NewFunction(inputSize int, loss func(x *Node) *Node) graddesc.Function {
+	return graddesc.Function{
+		InputSize: inputSize,
+		Mapper: func(x []float64) []float64 {
+			out := loss(Constant(mat.NewDense(1, inputSize, x)))
+			return []float64{out.value.At(0, 0)}
+		},
+		Gradient: func(x []float64) []float64 {
+			v := Variable(mat.NewDense(1, inputSize, x))
+			Backward(loss(v))
+			return mat.Row(nil, 0, v.Grad())
+		},
+	}
+}
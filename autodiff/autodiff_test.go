@@ -0,0 +1,131 @@
+package autodiff
+
+import (
+	"math/rand"
+	"mygoml/graddesc"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func randomPoint(rnd *rand.Rand, n int, low, high float64) []float64 {
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = low + (high-low)*rnd.Float64()
+	}
+	return x
+}
+
+func checkGradient(t *testing.T, f graddesc.Function, x []float64) {
+	t.Helper()
+	check, err := graddesc.CheckGradient(f, x, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, i := check.MaxRelativeError(); e > 1e-6 {
+		t.Errorf("relative error %g at %d: analytic %v, numeric %v", e, i, check.Analytic, check.Numeric)
+	}
+}
+
+func TestOpGradients(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	// x holds two 2x3 matrices a and b; every result is weighted by a
+	// fixed random matrix before summing so that each entry gets its own
+	// upstream gradient
+	split := func(x *Node) (*Node, *Node) {
+		return Reshape(Slice(x, 0, 1, 0, 6), 2, 3), Reshape(Slice(x, 0, 1, 6, 12), 2, 3)
+	}
+	ops := map[string]func(a, b *Node) *Node{
+		"add":           Add,
+		"sub":           Sub,
+		"mul elem":      MulElem,
+		"div elem":      DivElem,
+		"add row":       func(a, b *Node) *Node { return Add(a, Slice(b, 1, 2, 0, 3)) },
+		"mul column":    func(a, b *Node) *Node { return MulElem(a, Slice(b, 0, 2, 2, 3)) },
+		"div scalar":    func(a, b *Node) *Node { return DivElem(Slice(b, 0, 1, 1, 2), a) },
+		"mul":           func(a, b *Node) *Node { return Mul(a, T(b)) },
+		"scale":         func(a, b *Node) *Node { return Scale(-2.5, a) },
+		"neg":           func(a, b *Node) *Node { return Neg(b) },
+		"transpose":     func(a, b *Node) *Node { return T(a) },
+		"sum":           func(a, b *Node) *Node { return Sum(a) },
+		"mean":          func(a, b *Node) *Node { return Mean(a) },
+		"row sum":       func(a, b *Node) *Node { return RowSum(b) },
+		"exp":           func(a, b *Node) *Node { return Exp(a) },
+		"log":           func(a, b *Node) *Node { return Log(a) },
+		"sin":           func(a, b *Node) *Node { return Sin(a) },
+		"cos":           func(a, b *Node) *Node { return Cos(a) },
+		"square":        func(a, b *Node) *Node { return Square(a) },
+		"abs":           func(a, b *Node) *Node { return Abs(Sub(a, b)) },
+		"sigmoid":       func(a, b *Node) *Node { return Sigmoid(a) },
+		"tanh":          func(a, b *Node) *Node { return Tanh(a) },
+		"relu":          func(a, b *Node) *Node { return ReLU(Sub(a, b)) },
+		"log sum exp":   func(a, b *Node) *Node { return LogSumExp(a) },
+		"log softmax":   func(a, b *Node) *Node { return LogSoftmax(a) },
+		"softmax":       func(a, b *Node) *Node { return Softmax(a) },
+		"slice":         func(a, b *Node) *Node { return Slice(a, 0, 2, 1, 3) },
+		"reshape":       func(a, b *Node) *Node { return Reshape(a, 3, 2) },
+		"reused inputs": func(a, b *Node) *Node { return MulElem(Add(a, b), a) },
+	}
+	for name, f := range ops {
+		t.Run(name, func(t *testing.T) {
+			// positive inputs keep Log and DivElem defined
+			x := randomPoint(rnd, 12, 0.5, 2)
+			r, c := f(split(Constant(mat.NewDense(1, 12, x)))).Dims()
+			weights := Constant(mat.NewDense(r, c, randomPoint(rnd, r*c, -1, 1)))
+			fn := NewFunction(12, func(x *Node) *Node {
+				return Sum(MulElem(f(split(x)), weights))
+			})
+			checkGradient(t, fn, x)
+		})
+	}
+}
+
+// softmaxLoss is the mean cross entropy of a linear softmax model with an
+// L2 penalty, the weights being the 3x2 matrix read from x.
+func softmaxLoss(X, Y mat.Matrix, alpha float64) func(x *Node) *Node {
+	return func(x *Node) *Node {
+		W := Reshape(x, 3, 2)
+		Z := Mul(Constant(X), W)
+		r, _ := X.Dims()
+		ce := Scale(-1/float64(r), Sum(MulElem(Constant(Y), LogSoftmax(Z))))
+		return Add(ce, Scale(alpha, Sum(Square(W))))
+	}
+}
+
+func TestEndToEndLoss(t *testing.T) {
+	rnd := rand.New(rand.NewSource(2))
+	n := 20
+	X := mat.NewDense(n, 3, nil)
+	Y := mat.NewDense(n, 2, nil)
+	for i := 0; i < n; i++ {
+		x0, x1 := rnd.NormFloat64(), rnd.NormFloat64()
+		X.SetRow(i, []float64{x0, x1, 1})
+		if x0+x1 > 0 {
+			Y.Set(i, 1, 1)
+		} else {
+			Y.Set(i, 0, 1)
+		}
+	}
+	f := NewFunction(6, softmaxLoss(X, Y, 0.1))
+
+	t.Run("gradient", func(t *testing.T) {
+		checkGradient(t, f, randomPoint(rnd, 6, -1, 1))
+	})
+
+	t.Run("minimize", func(t *testing.T) {
+		start := make([]float64, 6)
+		result, err := (&graddesc.LBFGSMinimizer{}).Minimize(f, start)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if before, after := f.Mapper(start)[0], f.Mapper(result.X)[0]; after >= before {
+			t.Errorf("expected the loss to decrease from %g, got %g", before, after)
+		}
+		for i, g := range f.Gradient(result.X) {
+			if g > 1e-4 || g < -1e-4 {
+				t.Errorf("expected a zero gradient at the minimum, got %g at %d", g, i)
+			}
+		}
+	})
+}
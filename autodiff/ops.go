@@ -0,0 +1,300 @@
+package autodiff
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func anyNeedsGrad(nodes ...*Node) bool {
+	for _, n := range nodes {
+		if n.needsGrad() {
+			return true
+		}
+	}
+	return false
+}
+
+// op creates the node of an operation, dropping backward when none of the
+// inputs lead to a Variable.
+func op(value *mat.Dense, backward func(grad *mat.Dense), parents ...*Node) *Node {
+	if !anyNeedsGrad(parents...) {
+		return newNode(value, nil)
+	}
+	return newNode(value, backward, parents...)
+}
+
+// broadcastDims returns the shape of an elementwise operation on a and b.
+// Either side may be 1x1, a row vector or a column vector that is repeated.
+func broadcastDims(a, b *Node) (int, int) {
+	ar, ac := a.Dims()
+	br, bc := b.Dims()
+	r, c := ar, ac
+	if ar == 1 {
+		r = br
+	}
+	if ac == 1 {
+		c = bc
+	}
+	if (ar != r && ar != 1) || (br != r && br != 1) || (ac != c && ac != 1) || (bc != c && bc != 1) {
+		panic(fmt.Sprintf("autodiff: cannot broadcast %dx%d and %dx%d", ar, ac, br, bc))
+	}
+	return r, c
+}
+
+func expand(m *mat.Dense, r, c int) *mat.Dense {
+	mr, mc := m.Dims()
+	if mr == r && mc == c {
+		return m
+	}
+	out := mat.NewDense(r, c, nil)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			out.Set(i, j, m.At(i%mr, j%mc))
+		}
+	}
+	return out
+}
+
+// reduce sums a broadcast gradient back to an r x c shape.
+func reduce(g *mat.Dense, r, c int) *mat.Dense {
+	gr, gc := g.Dims()
+	if gr == r && gc == c {
+		return g
+	}
+	out := mat.NewDense(r, c, nil)
+	for i := 0; i < gr; i++ {
+		for j := 0; j < gc; j++ {
+			out.Set(i%r, j%c, out.At(i%r, j%c)+g.At(i, j))
+		}
+	}
+	return out
+}
+
+func elementwise(a, b *Node, f func(x, y float64) float64, dfdx, dfdy func(x, y float64) float64) *Node {
+	r, c := broadcastDims(a, b)
+	av, bv := expand(a.value, r, c), expand(b.value, r, c)
+	value := mat.NewDense(r, c, nil)
+	value.Apply(func(i, j int, _ float64) float64 {
+		return f(av.At(i, j), bv.At(i, j))
+	}, value)
+	return op(value, func(grad *mat.Dense) {
+		if a.needsGrad() {
+			var ga mat.Dense
+			ga.Apply(func(i, j int, g float64) float64 {
+				return g * dfdx(av.At(i, j), bv.At(i, j))
+			}, grad)
+			ar, ac := a.Dims()
+			a.accumulate(reduce(&ga, ar, ac))
+		}
+		if b.needsGrad() {
+			var gb mat.Dense
+			gb.Apply(func(i, j int, g float64) float64 {
+				return g * dfdy(av.At(i, j), bv.At(i, j))
+			}, grad)
+			br, bc := b.Dims()
+			b.accumulate(reduce(&gb, br, bc))
+		}
+	}, a, b)
+}
+
+// unary applies f elementwise, df getting both the input and the output.
+func unary(a *Node, f func(x float64) float64, df func(x, y float64) float64) *Node {
+	var value mat.Dense
+	value.Apply(func(i, j int, x float64) float64 {
+		return f(x)
+	}, a.value)
+	return op(&value, func(grad *mat.Dense) {
+		var ga mat.Dense
+		ga.Apply(func(i, j int, g float64) float64 {
+			return g * df(a.value.At(i, j), value.At(i, j))
+		}, grad)
+		a.accumulate(&ga)
+	}, a)
+}
+
+func Add(a, b *Node) *Node {
+	return elementwise(a, b,
+		func(x, y float64) float64 { return x + y },
+		func(x, y float64) float64 { return 1 },
+		func(x, y float64) float64 { return 1 })
+}
+
+func Sub(a, b *Node) *Node {
+	return elementwise(a, b,
+		func(x, y float64) float64 { return x - y },
+		func(x, y float64) float64 { return 1 },
+		func(x, y float64) float64 { return -1 })
+}
+
+func MulElem(a, b *Node) *Node {
+	return elementwise(a, b,
+		func(x, y float64) float64 { return x * y },
+		func(x, y float64) float64 { return y },
+		func(x, y float64) float64 { return x })
+}
+
+func DivElem(a, b *Node) *Node {
+	return elementwise(a, b,
+		func(x, y float64) float64 { return x / y },
+		func(x, y float64) float64 { return 1 / y },
+		func(x, y float64) float64 { return -x / (y * y) })
+}
+
+// Mul is the matrix product a*b.
+func Mul(a, b *Node) *Node {
+	var value mat.Dense
+	value.Mul(a.value, b.value)
+	return op(&value, func(grad *mat.Dense) {
+		if a.needsGrad() {
+			var ga mat.Dense
+			ga.Mul(grad, b.value.T())
+			a.accumulate(&ga)
+		}
+		if b.needsGrad() {
+			var gb mat.Dense
+			gb.Mul(a.value.T(), grad)
+			b.accumulate(&gb)
+		}
+	}, a, b)
+}
+
+func Scale(s float64, a *Node) *Node {
+	return unary(a,
+		func(x float64) float64 { return s * x },
+		func(x, y float64) float64 { return s })
+}
+
+func Neg(a *Node) *Node {
+	return Scale(-1, a)
+}
+
+func T(a *Node) *Node {
+	value := mat.DenseCopyOf(a.value.T())
+	return op(value, func(grad *mat.Dense) {
+		a.accumulate(grad.T())
+	}, a)
+}
+
+func Sum(a *Node) *Node {
+	value := mat.NewDense(1, 1, []float64{mat.Sum(a.value)})
+	return op(value, func(grad *mat.Dense) {
+		r, c := a.Dims()
+		a.accumulate(expand(grad, r, c))
+	}, a)
+}
+
+func Mean(a *Node) *Node {
+	r, c := a.Dims()
+	return Scale(1/float64(r*c), Sum(a))
+}
+
+// RowSum sums every row into an r x 1 column vector.
+func RowSum(a *Node) *Node {
+	r, c := a.Dims()
+	value := mat.NewDense(r, 1, nil)
+	for i := 0; i < r; i++ {
+		value.Set(i, 0, mat.Sum(a.value.RowView(i)))
+	}
+	return op(value, func(grad *mat.Dense) {
+		a.accumulate(expand(grad, r, c))
+	}, a)
+}
+
+func Exp(a *Node) *Node {
+	return unary(a, math.Exp, func(x, y float64) float64 { return y })
+}
+
+func Log(a *Node) *Node {
+	return unary(a, math.Log, func(x, y float64) float64 { return 1 / x })
+}
+
+func Sin(a *Node) *Node {
+	return unary(a, math.Sin, func(x, y float64) float64 { return math.Cos(x) })
+}
+
+func Cos(a *Node) *Node {
+	return unary(a, math.Cos, func(x, y float64) float64 { return -math.Sin(x) })
+}
+
+func Square(a *Node) *Node {
+	return unary(a,
+		func(x float64) float64 { return x * x },
+		func(x, y float64) float64 { return 2 * x })
+}
+
+func Abs(a *Node) *Node {
+	return unary(a, math.Abs, func(x, y float64) float64 {
+		if x > 0 {
+			return 1
+		} else if x < 0 {
+			return -1
+		}
+		return 0
+	})
+}
+
+func Sigmoid(a *Node) *Node {
+	return unary(a,
+		func(x float64) float64 { return 1 / (1 + math.Exp(-x)) },
+		func(x, y float64) float64 { return y * (1 - y) })
+}
+
+func Tanh(a *Node) *Node {
+	return unary(a, math.Tanh, func(x, y float64) float64 { return 1 - y*y })
+}
+
+func ReLU(a *Node) *Node {
+	return unary(a,
+		func(x float64) float64 { return math.Max(0, x) },
+		func(x, y float64) float64 {
+			if x > 0 {
+				return 1
+			}
+			return 0
+		})
+}
+
+// LogSumExp computes log(sum(exp(row))) of every row in a stable way.
+func LogSumExp(a *Node) *Node {
+	r, _ := a.Dims()
+	max := mat.NewDense(r, 1, nil)
+	for i := 0; i < r; i++ {
+		max.Set(i, 0, mat.Max(a.value.RowView(i)))
+	}
+	shift := Constant(max)
+	return Add(Log(RowSum(Exp(Sub(a, shift)))), shift)
+}
+
+// LogSoftmax is the row-wise log of the softmax of a.
+func LogSoftmax(a *Node) *Node {
+	return Sub(a, LogSumExp(a))
+}
+
+func Softmax(a *Node) *Node {
+	return Exp(LogSoftmax(a))
+}
+
+// Slice returns the rows [i, k) and columns [j, l) of a.
+func Slice(a *Node, i, k, j, l int) *Node {
+	value := mat.DenseCopyOf(a.value.Slice(i, k, j, l))
+	return op(value, func(grad *mat.Dense) {
+		r, c := a.Dims()
+		ga := mat.NewDense(r, c, nil)
+		ga.Slice(i, k, j, l).(*mat.Dense).Copy(grad)
+		a.accumulate(ga)
+	}, a)
+}
+
+// Reshape reads a in row-major order into an r x c matrix.
+func Reshape(a *Node, r, c int) *Node {
+	ar, ac := a.Dims()
+	if ar*ac != r*c {
+		panic(fmt.Sprintf("autodiff: cannot reshape %dx%d into %dx%d", ar, ac, r, c))
+	}
+	value := mat.NewDense(r, c, mat.DenseCopyOf(a.value).RawMatrix().Data)
+	return op(value, func(grad *mat.Dense) {
+		a.accumulate(mat.NewDense(ar, ac, mat.DenseCopyOf(grad).RawMatrix().Data))
+	}, a)
+}
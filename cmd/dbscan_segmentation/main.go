@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/jpeg"
+	"mygoml"
+	"mygoml/dbscan"
+	"os"
+)
+
+type ImagePoint struct {
+	x, y  int
+	color color.Color
+}
+
+func (ip ImagePoint) Features() []float64 {
+	r, g, b, _ := ip.color.RGBA()
+	return []float64{float64(r >> 8), float64(g >> 8), float64(b >> 8)}
+}
+
+type Image struct {
+	img image.Image
+}
+
+func (im Image) DataPoints() []mygoml.UnsupervisedDataPoint {
+	var out []mygoml.UnsupervisedDataPoint
+	rect := im.img.Bounds()
+	for x := rect.Min.X; x < rect.Max.X; x++ {
+		for y := rect.Min.Y; y < rect.Max.Y; y++ {
+			c := im.img.At(x, y)
+			out = append(out, ImagePoint{x, y, c})
+		}
+	}
+	return out
+}
+
+// meanColor averages the colors of the members of a cluster.
+func meanColor(members []mygoml.UnsupervisedDataPoint) color.RGBA {
+	var sum [3]float64
+	for _, m := range members {
+		for i, v := range m.Features() {
+			sum[i] = sum[i] + v
+		}
+	}
+	n := float64(len(members))
+	return color.RGBA{R: uint8(sum[0] / n), G: uint8(sum[1] / n), B: uint8(sum[2] / n), A: 255}
+}
+
+func main() {
+	imageFile, err := os.Open("cmd/kmeans_app/object_segmentation/girl3.jpg")
+	if err != nil {
+		panic(err)
+	}
+	defer imageFile.Close()
+
+	img, _, err := image.Decode(imageFile)
+	if err != nil {
+		panic(err)
+	}
+	ds := Image{img}
+
+	// the number of regions follows from the colors of the image
+	model := dbscan.Model{Eps: 2, MinPts: 60}
+	clusters := model.Clustering(ds)
+	fmt.Printf("%d regions, %d noise pixels\n", len(clusters), len(model.Noise()))
+
+	// paint every region with its mean color and the noise in black
+	rect := img.Bounds()
+	newImg := image.NewRGBA(rect)
+	for _, c := range clusters {
+		members := c.Members()
+		mean := meanColor(members)
+		for _, m := range members {
+			rm, _ := m.(ImagePoint)
+			newImg.Set(rm.x, rm.y, mean)
+		}
+	}
+	for _, m := range model.Noise() {
+		rm, _ := m.(ImagePoint)
+		newImg.Set(rm.x, rm.y, color.Black)
+	}
+
+	newImgFile, err := os.Create("cmd/dbscan_segmentation/girl3_dbscan.jpg")
+	if err != nil {
+		panic(err)
+	}
+	defer newImgFile.Close()
+	jpeg.Encode(newImgFile, newImg, &jpeg.Options{Quality: 80})
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"math/rand"
+	"mygoml"
+	"mygoml/tree"
+	"os"
+	"time"
+)
+
+type IrisDataPoint struct {
+	Measures []float64
+	Type     int
+}
+
+func (dp IrisDataPoint) Features() []float64 {
+	return dp.Measures
+}
+
+func (dp IrisDataPoint) Target() []float64 {
+	return []float64{float64(dp.Type)}
+}
+
+type IrisSet []IrisDataPoint
+
+func (s IrisSet) DataPoints() []mygoml.SupervisedDataPoint {
+	var out []mygoml.SupervisedDataPoint
+	for _, v := range s {
+		out = append(out, v)
+	}
+	return out
+}
+
+func ReadIris(filepath string) (IrisSet, []string, error) {
+	file, err := os.Open(filepath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	labelToType := make(map[string]int)
+	var labels []string
+	var dataset IrisSet
+	s := bufio.NewScanner(file)
+	for s.Scan() {
+		var a, b, c, d float64
+		var label string
+		if _, err := fmt.Sscanf(s.Text(), "%f,%f,%f,%f,%s", &a, &b, &c, &d, &label); err != nil {
+			continue
+		}
+		t, ok := labelToType[label]
+		if !ok {
+			t = len(labels)
+			labelToType[label] = t
+			labels = append(labels, label)
+		}
+		dataset = append(dataset, IrisDataPoint{Measures: []float64{a, b, c, d}, Type: t})
+	}
+	return dataset, labels, s.Err()
+}
+
+func main() {
+	rand.Seed(time.Now().UnixNano())
+	names := []string{"sepal length", "sepal width", "petal length", "petal width"}
+
+	// read data from file
+	dataset, labels, err := ReadIris("datasets/iris.data")
+	if err != nil {
+		panic(err)
+	}
+	rand.Shuffle(len(dataset), func(i, j int) { dataset[i], dataset[j] = dataset[j], dataset[i] })
+	train, test := dataset[:100], dataset[100:]
+
+	// grow a full tree and look at where pruning would cut it
+	model := &tree.Model{Criterion: tree.Gini}
+	if err := model.Train(train); err != nil {
+		panic(err)
+	}
+	alphas, impurities := model.PruningPath()
+	fmt.Println("######## Pruning Path ############")
+	for i := range alphas {
+		fmt.Printf("alpha %.4f: leaf impurity %.4f\n", alphas[i], impurities[i])
+	}
+
+	// train a pruned tree
+	model.CCPAlpha = 0.01
+	model.MaxDepth = 5
+	if err := model.Train(train); err != nil {
+		panic(err)
+	}
+	fmt.Printf("\n######## Tree (depth %d, %d leaves) ############\n", model.Depth(), model.Leaves())
+	fmt.Print(model.Text(names))
+	fmt.Println("\n######## Feature Importances ############")
+	for i, v := range model.FeatureImportances() {
+		fmt.Printf("%s: %.3f\n", names[i], v)
+	}
+
+	// test model
+	var predictions, targets []float64
+	for _, d := range test {
+		p, _ := model.Predict(d.Features())
+		predictions = append(predictions, p...)
+		targets = append(targets, d.Target()...)
+	}
+	fmt.Printf("\nAccuracy: %.2f%% (%d classes: %v)\n", mygoml.Accuracy(predictions, targets), len(labels), labels)
+
+	if err := os.WriteFile("cmd/decision_tree/tree.dot", []byte(model.DOT(names)), 0644); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"mygoml"
+	"mygoml/gmm"
+	"time"
+
+	"gonum.org/v1/plot"
+
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+
+	"gonum.org/v1/plot/vg/draw"
+
+	"golang.org/x/exp/rand"
+
+	"gonum.org/v1/gonum/stat/distmv"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+type RandomPoint struct {
+	X float64
+	Y float64
+}
+
+func NewRandomPoint(src []float64) RandomPoint {
+	if len(src) != 2 {
+		panic("source slice must have the length of 2")
+	}
+	var rp RandomPoint
+	rp.X = src[0]
+	rp.Y = src[1]
+	return rp
+}
+
+func (rp RandomPoint) Features() []float64 {
+	return []float64{rp.X, rp.Y}
+}
+
+type RandomPointSet []RandomPoint
+
+func (rps RandomPointSet) DataPoints() []mygoml.UnsupervisedDataPoint {
+	var out []mygoml.UnsupervisedDataPoint
+	for _, v := range rps {
+		out = append(out, v)
+	}
+	return out
+}
+
+type PointCluster []RandomPoint
+
+func ClusterToPointCluster(cluster mygoml.Cluster) PointCluster {
+	var pc PointCluster
+	members := cluster.Members()
+	for _, m := range members {
+		if v, ok := m.(RandomPoint); ok {
+			pc = append(pc, v)
+		} else {
+			return make([]RandomPoint, 0)
+		}
+	}
+	return pc
+}
+
+func (pc PointCluster) Len() int {
+	return len([]RandomPoint(pc))
+}
+
+func (pc PointCluster) XY(i int) (x, y float64) {
+	return pc[i].X, pc[i].Y
+}
+
+func (pc *PointCluster) Plotter(shape draw.GlyphDrawer, color color.RGBA) *plotter.Scatter {
+	scatter, err := plotter.NewScatter(pc)
+	if err != nil {
+		panic(err)
+	}
+
+	scatter.GlyphStyle.Color = color
+	scatter.GlyphStyle.Shape = shape
+	return scatter
+}
+
+func main() {
+	// define variables
+	var rps RandomPointSet
+
+	// seed random generator
+	s := rand.NewSource(uint64(time.Now().Unix()))
+
+	// create centers
+	var C1, C2, C3 PointCluster
+
+	// generate points around centers with different shapes
+	N := 300
+	ND1, _ := distmv.NewNormal([]float64{2, 2}, mat.NewSymDense(2, []float64{2, 1.5, 1.5, 2}), s)
+	ND2, _ := distmv.NewNormal([]float64{8, 3}, mat.NewSymDense(2, []float64{0.3, 0, 0, 0.3}), s)
+	ND3, _ := distmv.NewNormal([]float64{3, 8}, mat.NewSymDense(2, []float64{3, 0, 0, 0.5}), s)
+	for i := 0; i < N; i++ {
+		rp1 := NewRandomPoint(ND1.Rand(nil))
+		rp2 := NewRandomPoint(ND2.Rand(nil))
+		rp3 := NewRandomPoint(ND3.Rand(nil))
+
+		C1 = append(C1, rp1)
+		C2 = append(C2, rp2)
+		C3 = append(C3, rp3)
+		rps = append(rps, rp1, rp2, rp3)
+	}
+
+	// plot them out
+	p, err := plot.New()
+	if err != nil {
+		panic(err)
+	}
+	p.Title.Text = "Gaussian Mixture"
+	p.X.Label.Text = "X"
+	p.Y.Label.Text = "Y"
+	p.Add(plotter.NewGrid())
+	p.Add(C1.Plotter(draw.TriangleGlyph{}, color.RGBA{R: 255, A: 255}))
+	p.Add(C2.Plotter(draw.RingGlyph{}, color.RGBA{G: 255, A: 255}))
+	p.Add(C3.Plotter(draw.SquareGlyph{}, color.RGBA{B: 255, A: 255}))
+	if err := p.Save(4*vg.Inch, 4*vg.Inch, "cmd/gmm_clustering/gmm_clustering_data.png"); err != nil {
+		panic(err)
+	}
+
+	// choose the number of components with the lowest BIC
+	var model *gmm.Model
+	var clusters []mygoml.Cluster
+	best := 0.0
+	for k := 1; k <= 6; k++ {
+		m := &gmm.Model{Components: k, Covariance: gmm.Full, Inits: 3}
+		c := m.Clustering(rps)
+		bic, err := m.BIC(rps)
+		if err != nil {
+			panic(err)
+		}
+		aic, _ := m.AIC(rps)
+		fmt.Printf("%d components: BIC %.1f, AIC %.1f\n", k, bic, aic)
+		if model == nil || bic < best {
+			model, clusters, best = m, c, bic
+		}
+	}
+	fmt.Printf("chose %d components, weights %.2f\n", len(clusters), model.Weights())
+
+	// plot clusters
+	p, err = plot.New()
+	if err != nil {
+		panic(err)
+	}
+	p.Title.Text = "Gaussian Mixture"
+	p.X.Label.Text = "X"
+	p.Y.Label.Text = "Y"
+	p.Add(plotter.NewGrid())
+	glyphs := []draw.GlyphDrawer{draw.TriangleGlyph{}, draw.RingGlyph{}, draw.SquareGlyph{}, draw.CrossGlyph{}, draw.PlusGlyph{}, draw.CircleGlyph{}}
+	colors := []color.RGBA{{R: 255, A: 255}, {G: 255, A: 255}, {B: 255, A: 255}, {R: 255, G: 165, A: 255}, {R: 128, B: 128, A: 255}, {A: 255}}
+	for i, c := range clusters {
+		pc := ClusterToPointCluster(c)
+		p.Add(pc.Plotter(glyphs[i], colors[i]))
+	}
+	if err := p.Save(4*vg.Inch, 4*vg.Inch, "cmd/gmm_clustering/gmm_clustering_final.png"); err != nil {
+		panic(err)
+	}
+
+	// plot data drawn from the fitted mixture
+	samples, _, err := model.Sample(3*N, s)
+	if err != nil {
+		panic(err)
+	}
+	var drawn PointCluster
+	for _, x := range samples {
+		drawn = append(drawn, NewRandomPoint(x))
+	}
+	p, err = plot.New()
+	if err != nil {
+		panic(err)
+	}
+	p.Title.Text = "Gaussian Mixture Samples"
+	p.X.Label.Text = "X"
+	p.Y.Label.Text = "Y"
+	p.Add(plotter.NewGrid())
+	p.Add(drawn.Plotter(draw.CircleGlyph{}, color.RGBA{B: 255, A: 255}))
+	if err := p.Save(4*vg.Inch, 4*vg.Inch, "cmd/gmm_clustering/gmm_clustering_samples.png"); err != nil {
+		panic(err)
+	}
+}
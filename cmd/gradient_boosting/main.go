@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"math/rand"
+	"mygoml"
+	"mygoml/ensemble"
+	"os"
+	"time"
+)
+
+type IrisDataPoint struct {
+	Measures []float64
+	Type     int
+}
+
+func (dp IrisDataPoint) Features() []float64 {
+	return dp.Measures
+}
+
+func (dp IrisDataPoint) Target() []float64 {
+	return []float64{float64(dp.Type)}
+}
+
+type IrisSet []IrisDataPoint
+
+func (s IrisSet) DataPoints() []mygoml.SupervisedDataPoint {
+	var out []mygoml.SupervisedDataPoint
+	for _, v := range s {
+		out = append(out, v)
+	}
+	return out
+}
+
+func ReadIris(filepath string) (IrisSet, []string, error) {
+	file, err := os.Open(filepath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	labelToType := make(map[string]int)
+	var labels []string
+	var dataset IrisSet
+	s := bufio.NewScanner(file)
+	for s.Scan() {
+		var a, b, c, d float64
+		var label string
+		if _, err := fmt.Sscanf(s.Text(), "%f,%f,%f,%f,%s", &a, &b, &c, &d, &label); err != nil {
+			continue
+		}
+		t, ok := labelToType[label]
+		if !ok {
+			t = len(labels)
+			labelToType[label] = t
+			labels = append(labels, label)
+		}
+		dataset = append(dataset, IrisDataPoint{Measures: []float64{a, b, c, d}, Type: t})
+	}
+	return dataset, labels, s.Err()
+}
+
+func main() {
+	rand.Seed(time.Now().UnixNano())
+	names := []string{"sepal length", "sepal width", "petal length", "petal width"}
+
+	// read data from file
+	dataset, labels, err := ReadIris("datasets/iris.data")
+	if err != nil {
+		panic(err)
+	}
+	rand.Shuffle(len(dataset), func(i, j int) { dataset[i], dataset[j] = dataset[j], dataset[i] })
+	train, test := dataset[:100], dataset[100:]
+
+	// define model, a fifth of the training data decides when to stop
+	model := &ensemble.GradientBoosting{
+		Loss:                ensemble.SoftmaxLoss,
+		Rounds:              500,
+		LearningRate:        0.1,
+		MaxDepth:            2,
+		Subsample:           0.8,
+		ValidationFraction:  0.2,
+		NoImprovementRounds: 20,
+	}
+
+	// train model
+	if err := model.Train(train); err != nil {
+		panic(err)
+	}
+	trainLoss, validLoss := model.TrainLoss(), model.ValidationLoss()
+	fmt.Printf("kept %d rounds, train loss %.4f, validation loss %.4f\n",
+		model.Stages(), trainLoss[model.Stages()-1], validLoss[model.Stages()-1])
+	for i, v := range model.FeatureImportances() {
+		fmt.Printf("%s: %.3f\n", names[i], v)
+	}
+
+	// test model
+	var predictions, targets []float64
+	for _, d := range test {
+		p, _ := model.Predict(d.Features())
+		proba, _ := model.PredictProba(d.Features())
+		fmt.Printf("Predicted: %s (%.2f), Ground Truth: %s\n", labels[int(p[0])], proba[int(p[0])], labels[d.Type])
+		predictions = append(predictions, p...)
+		targets = append(targets, d.Target()...)
+	}
+	fmt.Printf("Accuracy: %.2f%%\n", mygoml.Accuracy(predictions, targets))
+}
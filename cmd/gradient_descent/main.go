@@ -2,22 +2,15 @@ package main
 
 import (
 	"fmt"
-	"math"
+	"mygoml/autodiff"
 	"mygoml/graddesc"
 )
 
 func main() {
-	myFunc := graddesc.Function{
-		InputSize: 1,
-		Mapper: func(x []float64) []float64 {
-			a := x[0]
-			return []float64{a*a + 10*math.Sin(a)}
-		},
-		Gradient: func(x []float64) []float64 {
-			a := x[0]
-			return []float64{2*a + 10*math.Cos(a)}
-		},
-	}
+	// f(a) = a^2 + 10*sin(a), the gradient comes from autodiff
+	myFunc := autodiff.NewFunction(1, func(a *autodiff.Node) *autodiff.Node {
+		return autodiff.Add(autodiff.Square(a), autodiff.Scale(10, autodiff.Sin(a)))
+	})
 
 	out := make(chan string)
 	// base
@@ -28,11 +21,9 @@ func main() {
 			MaxStep:       100,
 			EpochProvider: &batchProvider,
 		}
-		var optimizedX []float64
-
 		op.Updater = &graddesc.BaseUpdater{}
-		optimizedX = op.Optimize([]float64{5})
-		out <- fmt.Sprintf("without momentum = %v\n", optimizedX)
+		result, err := op.Optimize([]float64{5})
+		out <- fmt.Sprintf("without momentum = %v (%v, %v)\n", result.X, result.StopReason, err)
 	}()
 
 	// momentum
@@ -42,11 +33,9 @@ func main() {
 			MaxStep:       100,
 			EpochProvider: &batchProvider,
 		}
-		var optimizedX []float64
-
 		op.Updater = &graddesc.MomentumUpdater{Gamma: 0.9, StartVelocity: make([]float64, 1)}
-		optimizedX = op.Optimize([]float64{5})
-		out <- fmt.Sprintf("with momentum = %v\n", optimizedX)
+		result, err := op.Optimize([]float64{5})
+		out <- fmt.Sprintf("with momentum = %v (%v, %v)\n", result.X, result.StopReason, err)
 	}()
 
 	// nag
@@ -56,11 +45,9 @@ func main() {
 			MaxStep:       100,
 			EpochProvider: &batchProvider,
 		}
-		var optimizedX []float64
-
 		op.Updater = &graddesc.NAGUpdater{Gamma: 0.9, StartVelocity: make([]float64, 1)}
-		optimizedX = op.Optimize([]float64{5})
-		out <- fmt.Sprintf("with nag = %v\n", optimizedX)
+		result, err := op.Optimize([]float64{5})
+		out <- fmt.Sprintf("with nag = %v (%v, %v)\n", result.X, result.StopReason, err)
 	}()
 
 	for i := 0; i < 3; i++ {
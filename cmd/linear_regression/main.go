@@ -86,4 +86,13 @@ func main() {
 	predict_160, _ := model.Predict([]float64{160})
 	fmt.Printf("Height: 155, Predicted Weight: %v\n", predict_155)
 	fmt.Printf("Height: 160, Predicted Weight: %v\n", predict_160)
+
+	// diagnostics
+	summary, err := model.Summary(hws)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Print(summary)
+	lower, upper, _ := summary.PredictionInterval([]float64{155}, 0.95)
+	fmt.Printf("Height: 155, 95%% Prediction Interval: [%v, %v]\n", lower[0], upper[0])
 }
@@ -4,6 +4,7 @@ import (
 	"image/color"
 	"math"
 	"mygoml"
+	"mygoml/graddesc"
 	"mygoml/logregres"
 
 	"gonum.org/v1/plot"
@@ -91,8 +92,8 @@ func main() {
 		panic(err)
 	}
 
-	// define model
-	model := &logregres.Model{}
+	// define model, the problem is small enough for newton's method
+	model := &logregres.Model{Minimizer: &graddesc.NewtonMinimizer{}}
 
 	// train model
 	model.Train(ss)
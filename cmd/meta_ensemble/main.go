@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"math/rand"
+	"mygoml"
+	"mygoml/ensemble"
+	"mygoml/knn"
+	"mygoml/logregres"
+	"mygoml/pla"
+	"mygoml/tree"
+	"os"
+	"time"
+)
+
+type IrisDataPoint struct {
+	Measures []float64
+	Type     int
+}
+
+func (dp IrisDataPoint) Features() []float64 {
+	return dp.Measures
+}
+
+func (dp IrisDataPoint) Target() []float64 {
+	return []float64{float64(dp.Type)}
+}
+
+type IrisSet []IrisDataPoint
+
+func (s IrisSet) DataPoints() []mygoml.SupervisedDataPoint {
+	var out []mygoml.SupervisedDataPoint
+	for _, v := range s {
+		out = append(out, v)
+	}
+	return out
+}
+
+func ReadIris(filepath string) (IrisSet, []string, error) {
+	file, err := os.Open(filepath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	labelToType := make(map[string]int)
+	var labels []string
+	var dataset IrisSet
+	s := bufio.NewScanner(file)
+	for s.Scan() {
+		var a, b, c, d float64
+		var label string
+		if _, err := fmt.Sscanf(s.Text(), "%f,%f,%f,%f,%s", &a, &b, &c, &d, &label); err != nil {
+			continue
+		}
+		t, ok := labelToType[label]
+		if !ok {
+			t = len(labels)
+			labelToType[label] = t
+			labels = append(labels, label)
+		}
+		dataset = append(dataset, IrisDataPoint{Measures: []float64{a, b, c, d}, Type: t})
+	}
+	return dataset, labels, s.Err()
+}
+
+// virginica marks the iris setosa and versicolor as 0 and virginica as 1,
+// the binary problem the logistic regression and the perceptron solve.
+func virginica(dataset IrisSet) IrisSet {
+	out := make(IrisSet, len(dataset))
+	for i, d := range dataset {
+		out[i] = IrisDataPoint{Measures: d.Measures, Type: d.Type / 2}
+	}
+	return out
+}
+
+func accuracy(model mygoml.SupervisedModel, test IrisSet) float64 {
+	var predictions, targets []float64
+	for _, d := range test {
+		p, err := model.Predict(d.Features())
+		if err != nil {
+			panic(err)
+		}
+		predictions = append(predictions, p...)
+		targets = append(targets, d.Target()...)
+	}
+	return mygoml.Accuracy(predictions, targets)
+}
+
+func main() {
+	rand.Seed(time.Now().UnixNano())
+
+	// read data from file
+	dataset, _, err := ReadIris("datasets/iris.data")
+	if err != nil {
+		panic(err)
+	}
+	rand.Shuffle(len(dataset), func(i, j int) { dataset[i], dataset[j] = dataset[j], dataset[i] })
+	train, test := dataset[:100], dataset[100:]
+
+	// the models we already have, adapted to the 0 and 1 labels
+	estimators := []ensemble.Estimator{
+		{New: func() mygoml.SupervisedModel { return &knn.Model{K: 5, Norm: 2} }},
+		{
+			New:     func() mygoml.SupervisedModel { return &logregres.Model{} },
+			Decoder: ensemble.Threshold(0.5, 0, 1),
+		},
+		{
+			New: func() mygoml.SupervisedModel { return &pla.Model{Variant: pla.Pocket} },
+			Encoder: func(label float64) []float64 {
+				return []float64{2*label - 1}
+			},
+			Decoder: ensemble.Threshold(0, 0, 1),
+		},
+	}
+	bagging := &ensemble.Bagging{Estimator: estimators[0], Estimators: 25}
+	models := []struct {
+		name  string
+		model mygoml.SupervisedModel
+	}{
+		{"knn", &ensemble.Voting{Estimators: estimators[:1]}},
+		{"logistic regression", &ensemble.Voting{Estimators: estimators[1:2]}},
+		{"pocket perceptron", &ensemble.Voting{Estimators: estimators[2:]}},
+		{"hard voting", &ensemble.Voting{Estimators: estimators}},
+		{"bagged knn", bagging},
+		{"stacking", &ensemble.Stacking{Estimators: estimators, Final: estimators[1]}},
+	}
+	fmt.Println("virginica or not:")
+	for _, m := range models {
+		if err := m.model.Train(virginica(train)); err != nil {
+			panic(err)
+		}
+		fmt.Printf("%-20s accuracy: %.2f%%\n", m.name, accuracy(m.model, virginica(test)))
+	}
+	fmt.Printf("bagged knn out-of-bag error: %.3f\n", bagging.OOBError())
+
+	// boost decision stumps on the three species
+	adaboost := &ensemble.AdaBoost{
+		Estimator: ensemble.Estimator{New: func() mygoml.SupervisedModel { return &tree.Model{MaxDepth: 1} }},
+		Rounds:    50,
+	}
+	if err := adaboost.Train(train); err != nil {
+		panic(err)
+	}
+	fmt.Printf("AdaBoost of %d stumps accuracy: %.2f%%\n", len(adaboost.Models()), accuracy(adaboost, test))
+}
@@ -1,11 +1,15 @@
 package main
 
 import (
+	"fmt"
 	"image/color"
 	"math"
 	"mygoml"
+	"mygoml/graddesc"
+	"mygoml/mlp"
 	"time"
 
+	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/gonum/mat"
 	"gonum.org/v1/gonum/stat/distmv"
 	"gonum.org/v1/plot/vg"
@@ -112,4 +116,48 @@ func main() {
 	if err := p.Save(4*vg.Inch, 4*vg.Inch, "cmd/mlp/mlp_data.png"); err != nil {
 		panic(err)
 	}
+
+	// define model & train
+	model := &mlp.Model{
+		HiddenLayers: []int{100},
+		Activation:   mlp.ReLU,
+		Output:       mlp.Softmax,
+		Loss:         mlp.CrossEntropy,
+		Updater:      &graddesc.MomentumUpdater{Gamma: 0.9},
+		LearningRate: 0.5,
+		MaxStep:      3000,
+		BatchSize:    len(rs),
+	}
+	if err := model.Train(rs); err != nil {
+		panic(err)
+	}
+
+	// predict
+	var predictions, targets []float64
+	labels := make([]RandomSet, LabelNum)
+	for _, v := range rs {
+		p, _ := model.Predict(v.Features())
+		label := floats.MaxIdx(p)
+		labels[label] = append(labels[label], v)
+		predictions = append(predictions, float64(label))
+		targets = append(targets, float64(v.Label))
+	}
+	fmt.Printf("Accuracy: %.2f%%\n", mygoml.Accuracy(predictions, targets))
+
+	// plot predictions
+	p, err = plot.New()
+	if err != nil {
+		panic(err)
+	}
+	p.Title.Text = "MLP Predictions"
+	p.X.Label.Text = "X"
+	p.Y.Label.Text = "Y"
+	p.Add(plotter.NewGrid())
+	p.Add(labels[0].Plotter(draw.CircleGlyph{}, mygoml.Red))
+	p.Add(labels[1].Plotter(draw.CircleGlyph{}, mygoml.Green))
+	p.Add(labels[2].Plotter(draw.CircleGlyph{}, mygoml.Blue))
+
+	if err := p.Save(4*vg.Inch, 4*vg.Inch, "cmd/mlp/mlp_test.png"); err != nil {
+		panic(err)
+	}
 }
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"math/rand"
+	"mygoml"
+	"mygoml/ensemble"
+	"mygoml/logregres"
+	"mygoml/pla"
+	"os"
+	"time"
+)
+
+type IrisDataPoint struct {
+	Measures []float64
+	Type     int
+}
+
+func (dp IrisDataPoint) Features() []float64 {
+	return dp.Measures
+}
+
+func (dp IrisDataPoint) Target() []float64 {
+	return []float64{float64(dp.Type)}
+}
+
+type IrisSet []IrisDataPoint
+
+func (s IrisSet) DataPoints() []mygoml.SupervisedDataPoint {
+	var out []mygoml.SupervisedDataPoint
+	for _, v := range s {
+		out = append(out, v)
+	}
+	return out
+}
+
+func ReadIris(filepath string) (IrisSet, []string, error) {
+	file, err := os.Open(filepath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	labelToType := make(map[string]int)
+	var labels []string
+	var dataset IrisSet
+	s := bufio.NewScanner(file)
+	for s.Scan() {
+		var a, b, c, d float64
+		var label string
+		if _, err := fmt.Sscanf(s.Text(), "%f,%f,%f,%f,%s", &a, &b, &c, &d, &label); err != nil {
+			continue
+		}
+		t, ok := labelToType[label]
+		if !ok {
+			t = len(labels)
+			labelToType[label] = t
+			labels = append(labels, label)
+		}
+		dataset = append(dataset, IrisDataPoint{Measures: []float64{a, b, c, d}, Type: t})
+	}
+	return dataset, labels, s.Err()
+}
+
+func main() {
+	rand.Seed(time.Now().UnixNano())
+
+	// read data from file
+	dataset, labels, err := ReadIris("datasets/iris.data")
+	if err != nil {
+		panic(err)
+	}
+	rand.Shuffle(len(dataset), func(i, j int) { dataset[i], dataset[j] = dataset[j], dataset[i] })
+	train, test := dataset[:100], dataset[100:]
+
+	// binary models lifted to the three species
+	models := []struct {
+		name  string
+		model interface {
+			mygoml.SupervisedModel
+			Scores(features []float64) ([]float64, error)
+		}
+	}{
+		{"one-vs-rest logistic regression", &ensemble.OneVsRest{
+			New: func() mygoml.SupervisedModel { return &logregres.Model{} },
+		}},
+		{"one-vs-one logistic regression", &ensemble.OneVsOne{
+			New: func() mygoml.SupervisedModel { return &logregres.Model{} },
+		}},
+		{"one-vs-rest pocket perceptron", &ensemble.OneVsRest{
+			New:      func() mygoml.SupervisedModel { return &pla.Model{Variant: pla.Pocket} },
+			Negative: -1,
+		}},
+		{"one-vs-one pocket perceptron", &ensemble.OneVsOne{
+			New:      func() mygoml.SupervisedModel { return &pla.Model{Variant: pla.Pocket} },
+			Negative: -1,
+		}},
+	}
+	for _, m := range models {
+		if err := m.model.Train(train); err != nil {
+			panic(err)
+		}
+		var predictions, targets []float64
+		for _, d := range test {
+			p, _ := m.model.Predict(d.Features())
+			predictions = append(predictions, p...)
+			targets = append(targets, d.Target()...)
+		}
+		scores, _ := m.model.Scores(test[0].Features())
+		fmt.Printf("%s accuracy: %.2f%%, scores of a %s: %.2f\n",
+			m.name, mygoml.Accuracy(predictions, targets), labels[test[0].Type], scores)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"mygoml"
+	"mygoml/mnist"
+	"mygoml/naivebayes"
+)
+
+const batchSize = 1000
+
+type MNISTImage mnist.DigitImage
+
+func (m MNISTImage) Features() []float64 {
+	var fs []float64
+	for i := range m.Image {
+		for j := range m.Image[i] {
+			fs = append(fs, float64(m.Image[i][j]))
+		}
+	}
+	return fs
+}
+
+func (m MNISTImage) Target() []float64 {
+	return []float64{float64(m.Digit)}
+}
+
+type MNISTDataset []mnist.DigitImage
+
+func (ds MNISTDataset) DataPoints() []mygoml.SupervisedDataPoint {
+	var out []mygoml.SupervisedDataPoint
+	for _, v := range ds {
+		out = append(out, MNISTImage(v))
+	}
+	return out
+}
+
+type streamingModel interface {
+	mygoml.SupervisedModel
+	PartialFit(dataset mygoml.SupervisedDataSet) error
+}
+
+func accuracy(model mygoml.SupervisedModel, ds MNISTDataset) float64 {
+	correct := 0
+	for _, v := range ds {
+		dp := MNISTImage(v)
+		predicted, err := model.Predict(dp.Features())
+		if err == nil && predicted[0] == dp.Target()[0] {
+			correct++
+		}
+	}
+	return float64(correct) / float64(len(ds))
+}
+
+func main() {
+	trainset, err := mnist.ReadTrainSet("mnist")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	testset, err := mnist.ReadTestSet("mnist")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	models := []struct {
+		name  string
+		model streamingModel
+	}{
+		{"gaussian", &naivebayes.Gaussian{}},
+		{"multinomial", &naivebayes.Multinomial{}},
+		{"complement", &naivebayes.Complement{}},
+		{"bernoulli", &naivebayes.Bernoulli{Binarize: 127}},
+	}
+	for _, m := range models {
+		// feed the training set as a stream of batches
+		for start := 0; start < len(trainset.Data); start += batchSize {
+			end := start + batchSize
+			if end > len(trainset.Data) {
+				end = len(trainset.Data)
+			}
+			if err := m.model.PartialFit(MNISTDataset(trainset.Data[start:end])); err != nil {
+				fmt.Println(err)
+				return
+			}
+		}
+		fmt.Printf("%-12s accuracy: %.4f\n", m.name, accuracy(m.model, MNISTDataset(testset.Data)))
+	}
+}
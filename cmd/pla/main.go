@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"image/color"
 	"mygoml"
 	"mygoml/pla"
@@ -120,11 +121,15 @@ func main() {
 		panic(err)
 	}
 
-	// define model
-	model := &pla.Model{}
+	// define model, the pocket keeps the best weights when the groups overlap
+	model := &pla.Model{Variant: pla.Pocket}
 
 	// train model
-	model.Train(rps)
+	if err := model.Train(rps); err != nil {
+		panic(err)
+	}
+	history := model.ErrorHistory()
+	fmt.Printf("separated: %v, training error %.2f after %d epochs\n", model.Separated(), history[len(history)-1], len(history))
 
 	// test model
 	var pd1, pd2 RandomPointSet
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"math/rand"
+	"mygoml"
+	"mygoml/ensemble"
+	"mygoml/tree"
+	"os"
+	"time"
+)
+
+type IrisDataPoint struct {
+	Measures []float64
+	Type     int
+}
+
+func (dp IrisDataPoint) Features() []float64 {
+	return dp.Measures
+}
+
+func (dp IrisDataPoint) Target() []float64 {
+	return []float64{float64(dp.Type)}
+}
+
+type IrisSet []IrisDataPoint
+
+func (s IrisSet) DataPoints() []mygoml.SupervisedDataPoint {
+	var out []mygoml.SupervisedDataPoint
+	for _, v := range s {
+		out = append(out, v)
+	}
+	return out
+}
+
+func ReadIris(filepath string) (IrisSet, []string, error) {
+	file, err := os.Open(filepath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	labelToType := make(map[string]int)
+	var labels []string
+	var dataset IrisSet
+	s := bufio.NewScanner(file)
+	for s.Scan() {
+		var a, b, c, d float64
+		var label string
+		if _, err := fmt.Sscanf(s.Text(), "%f,%f,%f,%f,%s", &a, &b, &c, &d, &label); err != nil {
+			continue
+		}
+		t, ok := labelToType[label]
+		if !ok {
+			t = len(labels)
+			labelToType[label] = t
+			labels = append(labels, label)
+		}
+		dataset = append(dataset, IrisDataPoint{Measures: []float64{a, b, c, d}, Type: t})
+	}
+	return dataset, labels, s.Err()
+}
+
+type Model interface {
+	mygoml.SupervisedModel
+	OOBError() float64
+	FeatureImportances() []float64
+}
+
+func main() {
+	rand.Seed(time.Now().UnixNano())
+	names := []string{"sepal length", "sepal width", "petal length", "petal width"}
+
+	// read data from file
+	dataset, _, err := ReadIris("datasets/iris.data")
+	if err != nil {
+		panic(err)
+	}
+	rand.Shuffle(len(dataset), func(i, j int) { dataset[i], dataset[j] = dataset[j], dataset[i] })
+	train, test := dataset[:100], dataset[100:]
+
+	accuracy := func(model mygoml.SupervisedModel) float64 {
+		var predictions, targets []float64
+		for _, d := range test {
+			p, _ := model.Predict(d.Features())
+			predictions = append(predictions, p...)
+			targets = append(targets, d.Target()...)
+		}
+		return mygoml.Accuracy(predictions, targets)
+	}
+
+	single := &tree.Model{}
+	if err := single.Train(train); err != nil {
+		panic(err)
+	}
+	fmt.Printf("[Decision Tree] Accuracy: %.2f%%\n", accuracy(single))
+
+	models := map[string]Model{
+		"Random Forest": &ensemble.RandomForest{Trees: 200},
+		"Extra Trees":   &ensemble.ExtraTrees{Trees: 200, Bootstrap: true},
+	}
+	for name, model := range models {
+		if err := model.Train(train); err != nil {
+			panic(err)
+		}
+		fmt.Printf("[%s] Accuracy: %.2f%%, out-of-bag error: %.3f\n", name, accuracy(model), model.OOBError())
+		for i, v := range model.FeatureImportances() {
+			fmt.Printf("\t%s: %.3f\n", names[i], v)
+		}
+	}
+}
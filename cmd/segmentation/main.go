@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/jpeg"
+	"mygoml"
+	"mygoml/meanshift"
+	"mygoml/spectral"
+	"os"
+)
+
+type ImagePoint struct {
+	x, y  int
+	color color.Color
+}
+
+func (ip ImagePoint) Features() []float64 {
+	r, g, b, _ := ip.color.RGBA()
+	return []float64{float64(r >> 8), float64(g >> 8), float64(b >> 8)}
+}
+
+// Image holds every Step-th pixel of both axes of img.
+type Image struct {
+	img  image.Image
+	Step int
+}
+
+func (im Image) DataPoints() []mygoml.UnsupervisedDataPoint {
+	var out []mygoml.UnsupervisedDataPoint
+	rect := im.img.Bounds()
+	for x := rect.Min.X; x < rect.Max.X; x += im.Step {
+		for y := rect.Min.Y; y < rect.Max.Y; y += im.Step {
+			c := im.img.At(x, y)
+			out = append(out, ImagePoint{x, y, c})
+		}
+	}
+	return out
+}
+
+// meanColor averages the colors of the members of a cluster.
+func meanColor(members []mygoml.UnsupervisedDataPoint) color.RGBA {
+	var sum [3]float64
+	for _, m := range members {
+		for i, v := range m.Features() {
+			sum[i] = sum[i] + v
+		}
+	}
+	n := float64(len(members))
+	return color.RGBA{R: uint8(sum[0] / n), G: uint8(sum[1] / n), B: uint8(sum[2] / n), A: 255}
+}
+
+// save paints every pixel of the clusters, a Step sized square each, with
+// the mean color of its cluster.
+func save(path string, ds Image, clusters []mygoml.Cluster) {
+	newImg := image.NewRGBA(ds.img.Bounds())
+	for _, c := range clusters {
+		members := c.Members()
+		mean := meanColor(members)
+		for _, m := range members {
+			rm, _ := m.(ImagePoint)
+			for dx := 0; dx < ds.Step; dx++ {
+				for dy := 0; dy < ds.Step; dy++ {
+					newImg.Set(rm.x+dx, rm.y+dy, mean)
+				}
+			}
+		}
+	}
+
+	newImgFile, err := os.Create(path)
+	if err != nil {
+		panic(err)
+	}
+	defer newImgFile.Close()
+	jpeg.Encode(newImgFile, newImg, &jpeg.Options{Quality: 80})
+}
+
+func main() {
+	imageFile, err := os.Open("cmd/kmeans_app/object_segmentation/girl3.jpg")
+	if err != nil {
+		panic(err)
+	}
+	defer imageFile.Close()
+
+	img, _, err := image.Decode(imageFile)
+	if err != nil {
+		panic(err)
+	}
+
+	// mean-shift finds the number of regions from the colors, seeding from
+	// color bins to stay fast on every pixel
+	full := Image{img, 1}
+	bandwidth := meanshift.EstimateBandwidth(full, 0.1)
+	ms := meanshift.Model{Bandwidth: bandwidth, Kernel: meanshift.Flat, BinSeeding: true, MinBinFreq: 50}
+	clusters := ms.Clustering(full)
+	fmt.Printf("mean-shift: bandwidth %.1f, %d regions\n", bandwidth, len(clusters))
+	save("cmd/segmentation/girl3_meanshift.jpg", full, clusters)
+
+	// spectral clustering needs the eigenvectors of a dense matrix, so it
+	// works on a thumbnail
+	thumbnail := Image{img, 8}
+	sc := spectral.Model{ClusterCount: 4, Affinity: spectral.NearestNeighbors, Neighbors: 15}
+	clusters = sc.Clustering(thumbnail)
+	fmt.Printf("spectral: %d regions from %d pixels\n", len(clusters), len(thumbnail.DataPoints()))
+	save("cmd/segmentation/girl3_spectral.jpg", thumbnail, clusters)
+}
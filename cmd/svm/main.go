@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"mygoml"
+	"mygoml/svm"
+	"time"
+
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat/distmv"
+	"gonum.org/v1/plot/vg"
+
+	"gonum.org/v1/plot"
+
+	"golang.org/x/exp/rand"
+
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+var LabelNum = 3
+
+type RandomPoint struct {
+	X     float64
+	Y     float64
+	Label int
+}
+
+func (rp RandomPoint) Features() []float64 {
+	return []float64{rp.X, rp.Y}
+}
+
+func (rp RandomPoint) Target() []float64 {
+	return []float64{float64(rp.Label)}
+}
+
+type RandomSet []RandomPoint
+
+func (rs RandomSet) DataPoints() []mygoml.SupervisedDataPoint {
+	var out []mygoml.SupervisedDataPoint
+	for _, v := range rs {
+		out = append(out, v)
+	}
+	return out
+}
+
+func (rs RandomSet) Len() int {
+	return len([]RandomPoint(rs))
+}
+
+func (rs RandomSet) XY(i int) (x, y float64) {
+	return rs[i].X, rs[i].Y
+}
+
+func (rs RandomSet) Plotter(shape draw.GlyphDrawer, color color.RGBA) *plotter.Scatter {
+	scatter, err := plotter.NewScatter(rs)
+	if err != nil {
+		panic(err)
+	}
+
+	scatter.GlyphStyle.Color = color
+	scatter.GlyphStyle.Shape = shape
+	return scatter
+}
+
+func evaluate(name string, model mygoml.SupervisedModel, rs RandomSet) []RandomSet {
+	if err := model.Train(rs); err == mygoml.ErrNotConverged {
+		fmt.Printf("%s: %v\n", name, err)
+	} else if err != nil {
+		panic(err)
+	}
+
+	var predictions, targets []float64
+	labels := make([]RandomSet, LabelNum)
+	for _, v := range rs {
+		p, _ := model.Predict(v.Features())
+		label := int(p[0])
+		labels[label] = append(labels[label], v)
+		predictions = append(predictions, p[0])
+		targets = append(targets, float64(v.Label))
+	}
+	fmt.Printf("%s accuracy: %.2f%%\n", name, mygoml.Accuracy(predictions, targets))
+	return labels
+}
+
+func main() {
+	// generate three rings around the origin
+	s := rand.NewSource(uint64(time.Now().Unix()))
+	cov := mat.NewSymDense(1, []float64{1})
+	ND, _ := distmv.NewNormal([]float64{0}, cov, s)
+	rnd := rand.New(s)
+
+	var rs RandomSet
+	N := 100
+	for i := 0; i < LabelNum; i++ {
+		for j := 0; j < N; j++ {
+			r := float64(i+1) + ND.Rand(nil)[0]*0.15
+			t := rnd.Float64() * 2 * math.Pi
+			rs = append(rs, RandomPoint{X: r * math.Cos(t), Y: r * math.Sin(t), Label: i})
+		}
+	}
+
+	// a linear svm cannot separate the rings, an rbf kernel can
+	evaluate("Linear SVM (one-vs-rest)", &svm.LinearModel{Strategy: svm.OneVsRest}, rs)
+	labels := evaluate("RBF SVM (one-vs-one)", &svm.KernelModel{
+		Kernel:   svm.RBFKernel(1),
+		C:        10,
+		Strategy: svm.OneVsOne,
+	}, rs)
+
+	// plot predictions
+	p, err := plot.New()
+	if err != nil {
+		panic(err)
+	}
+	p.Title.Text = "SVM Predictions"
+	p.X.Label.Text = "X"
+	p.Y.Label.Text = "Y"
+	p.Add(plotter.NewGrid())
+	p.Add(labels[0].Plotter(draw.CircleGlyph{}, mygoml.Red))
+	p.Add(labels[1].Plotter(draw.CircleGlyph{}, mygoml.Green))
+	p.Add(labels[2].Plotter(draw.CircleGlyph{}, mygoml.Blue))
+
+	if err := p.Save(4*vg.Inch, 4*vg.Inch, "cmd/svm/svm_test.png"); err != nil {
+		panic(err)
+	}
+}
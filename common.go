@@ -8,6 +8,7 @@ import (
 var ErrDatasetEmpty = errors.New("[dataset empty]: there is no data inside dataset")
 var ErrMaybeInaccurate = errors.New("[maybe inaccurate computation]: the computed solution maybe inaccurate")
 var ErrUnknown = errors.New("[unknown]: unknown error")
+var ErrNotConverged = errors.New("[not converged]: the iterations stopped before reaching the tolerance")
 
 type ErrIncompatibleDataAndModel string
 
@@ -0,0 +1,126 @@
+package dbscan
+
+import (
+	"mygoml"
+)
+
+// Noise is the label of the data points that belong to no cluster.
+const Noise = -1
+
+// result holds the labels of the last clustering, shared by Model and
+// HDBSCAN.
+type result struct {
+	labels []int
+	noise  []mygoml.UnsupervisedDataPoint
+}
+
+// clusters groups dps by label and keeps the noise apart.
+func (r *result) clusters(dps []mygoml.UnsupervisedDataPoint, labels []int) []mygoml.Cluster {
+	r.labels, r.noise = labels, nil
+	var clusters []*mygoml.BasicCluster
+	for i, label := range labels {
+		if label == Noise {
+			r.noise = append(r.noise, dps[i])
+			continue
+		}
+		for label >= len(clusters) {
+			clusters = append(clusters, &mygoml.BasicCluster{})
+		}
+		clusters[label].Add(dps[i])
+	}
+	var out []mygoml.Cluster
+	for _, c := range clusters {
+		out = append(out, c)
+	}
+	return out
+}
+
+// Labels returns the cluster of every data point of the last clustering,
+// in the order of the returned clusters, or Noise.
+func (r *result) Labels() []int {
+	return r.labels
+}
+
+// Noise returns the data points of the last clustering that belong to no
+// cluster.
+func (r *result) Noise() []mygoml.UnsupervisedDataPoint {
+	return r.noise
+}
+
+// Model is DBSCAN: data points with at least MinPts data points within Eps
+// are core points, core points within Eps of each other share a cluster
+// along with the data points within Eps of them, and every other data
+// point is noise. The number of clusters follows from the density of the
+// data.
+type Model struct {
+	// Eps is the radius of the neighborhood of a data point
+	Eps float64
+	// MinPts is the number of data points, itself included, in the
+	// neighborhood of a core point, 5 when 0
+	MinPts int
+	result
+	core []bool
+}
+
+// Clustering returns the clusters of ds, without the noise.
+func (m *Model) Clustering(ds mygoml.UnsupervisedDataSet) []mygoml.Cluster {
+	dps := ds.DataPoints()
+	if len(dps) == 0 {
+		m.result, m.core = result{}, nil
+		return nil
+	}
+	minPts := m.MinPts
+	if minPts <= 0 {
+		minPts = 5
+	}
+	X := make([][]float64, len(dps))
+	for i, dp := range dps {
+		X[i] = dp.Features()
+	}
+	idx := newIndex(X)
+
+	labels := make([]int, len(X))
+	for i := range labels {
+		labels[i] = Noise
+	}
+	m.core = make([]bool, len(X))
+	visited := make([]bool, len(X))
+	count := 0
+	for i := range X {
+		if visited[i] {
+			continue
+		}
+		visited[i] = true
+		neighbors := idx.radius(i, m.Eps)
+		if len(neighbors) < minPts {
+			continue
+		}
+
+		// grow a new cluster from the core point i
+		m.core[i] = true
+		labels[i] = count
+		queue := neighbors
+		for len(queue) > 0 {
+			j := queue[0]
+			queue = queue[1:]
+			if labels[j] == Noise {
+				labels[j] = count
+			}
+			if visited[j] {
+				continue
+			}
+			visited[j] = true
+			if reach := idx.radius(j, m.Eps); len(reach) >= minPts {
+				m.core[j] = true
+				queue = append(queue, reach...)
+			}
+		}
+		count++
+	}
+	return m.clusters(dps, labels)
+}
+
+// Core reports which data points of the last clustering are core points.
+func (m *Model) Core() []bool {
+	return m.core
+}
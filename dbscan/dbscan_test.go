@@ -0,0 +1,143 @@
+package dbscan
+
+import (
+	"math"
+	"math/rand"
+	"mygoml"
+	"sort"
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// blobs returns two 3x3 grids of spacing 0.1, around (0, 0) and (10, 10),
+// followed by two isolated samples.
+func blobs() mygoml.Samples {
+	return append(mygoml.Grids(0, 10), mygoml.Sample{50, 50}, mygoml.Sample{-50, 20})
+}
+
+// checkBlobs checks that the grids of blobs make two clusters and the
+// isolated samples are noise.
+func checkBlobs(t *testing.T, clusters []mygoml.Cluster, labels []int, noise []mygoml.UnsupervisedDataPoint) {
+	t.Helper()
+	if len(clusters) != 2 || len(clusters[0].Members()) != 9 || len(clusters[1].Members()) != 9 {
+		t.Fatalf("expected two clusters of 9 data points, got %d clusters", len(clusters))
+	}
+	for i := 0; i < 18; i++ {
+		if labels[i] != labels[i/9*9] {
+			t.Errorf("expected data sample %d in the cluster of its grid, got labels %v", i, labels)
+			break
+		}
+	}
+	if labels[0] == labels[9] || labels[0] == Noise || labels[9] == Noise {
+		t.Errorf("expected the grids in two clusters, got labels %v", labels)
+	}
+	mygoml.DeepEqual(t, "noise labels", []int{Noise, Noise}, labels[18:])
+	mygoml.DeepEqual(t, "noise", []mygoml.UnsupervisedDataPoint{mygoml.Sample{50, 50}, mygoml.Sample{-50, 20}}, noise)
+}
+
+func TestDBSCAN(t *testing.T) {
+	// the corners of a grid have 3 neighbors within 0.15
+	m := &Model{Eps: 0.15, MinPts: 4}
+	clusters := m.Clustering(blobs())
+	checkBlobs(t, clusters, m.Labels(), m.Noise())
+	for i, core := range m.Core() {
+		if core != (i < 18) {
+			t.Errorf("expected only the grids to be core points, got %v", m.Core())
+			break
+		}
+	}
+
+	// with one more neighbor needed only the middle points of the edges
+	// and the centers are core points, the corners are border points
+	m = &Model{Eps: 0.15, MinPts: 5}
+	checkBlobs(t, m.Clustering(blobs()), m.Labels(), m.Noise())
+	if m.Core()[0] || !m.Core()[1] || !m.Core()[4] {
+		t.Errorf("expected a border corner and core edge and center points, got %v", m.Core()[:9])
+	}
+}
+
+func TestHDBSCAN(t *testing.T) {
+	m := &HDBSCAN{MinClusterSize: 5}
+	clusters := m.Clustering(blobs())
+	checkBlobs(t, clusters, m.Labels(), m.Noise())
+}
+
+// primWeights returns the sorted edge weights of the minimum spanning tree
+// of the mutual reachability distances, found by comparing every pair.
+func primWeights(X [][]float64, core []float64) []float64 {
+	n := len(X)
+	inTree := make([]bool, n)
+	distance := make([]float64, n)
+	for i := range distance {
+		distance[i] = math.Inf(1)
+	}
+	var weights []float64
+	for current := 0; len(weights) < n-1; {
+		inTree[current] = true
+		next := -1
+		for j := range X {
+			if inTree[j] {
+				continue
+			}
+			d := math.Max(floats.Distance(X[current], X[j], 2), math.Max(core[current], core[j]))
+			distance[j] = math.Min(distance[j], d)
+			if next < 0 || distance[j] < distance[next] {
+				next = j
+			}
+		}
+		weights = append(weights, distance[next])
+		current = next
+	}
+	sort.Float64s(weights)
+	return weights
+}
+
+func TestSpanningTree(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, tc := range []struct {
+		name       string
+		n, dims    int
+		minSamples int
+	}{
+		{"plane", 300, 2, 5},
+		{"space", 200, 3, 1},
+		{"duplicates", 100, 2, 3},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			X := make([][]float64, tc.n)
+			for i := range X {
+				X[i] = make([]float64, tc.dims)
+				for j := range X[i] {
+					X[i][j] = rnd.NormFloat64()
+					if tc.name == "duplicates" {
+						X[i][j] = math.Round(X[i][j])
+					}
+				}
+			}
+			idx := newIndex(X)
+			core := make([]float64, len(X))
+			for i := range X {
+				core[i] = idx.kth(i, tc.minSamples)
+			}
+
+			edges := spanningTree(idx, core)
+			u := newUnion(len(X))
+			weights := make([]float64, len(edges))
+			for i, e := range edges {
+				if u.find(e.a) == u.find(e.b) {
+					t.Fatalf("edge %v closes a cycle", e)
+				}
+				u.union(e.a, e.b)
+				weights[i] = e.weight
+			}
+			if !sort.Float64sAreSorted(weights) {
+				t.Error("expected the edges by weight")
+			}
+			expected := primWeights(X, core)
+			if len(weights) != len(expected) || !floats.EqualApprox(weights, expected, 1e-12) {
+				t.Errorf("expected the weights %v, got %v", expected, weights)
+			}
+		})
+	}
+}
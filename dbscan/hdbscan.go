@@ -0,0 +1,356 @@
+package dbscan
+
+import (
+	"math"
+	"mygoml"
+	"sort"
+
+	"gonum.org/v1/gonum/spatial/kdtree"
+)
+
+// HDBSCAN clusters at every density at once and keeps the clusters that
+// persist the longest, so unlike DBSCAN it needs no radius and finds
+// clusters of different densities. It builds the minimum spanning tree of
+// the mutual reachability distances, max(core(a), core(b), d(a, b)) with
+// core(a) the distance to the MinSamples-th nearest neighbor of a, with
+// Borůvka's algorithm over a k-d tree of the data points.
+type HDBSCAN struct {
+	// MinClusterSize is the smallest number of data points of a cluster, 5
+	// when less than 2
+	MinClusterSize int
+	// MinSamples is the neighbor that sets the core distance, itself
+	// included, MinClusterSize when 0
+	MinSamples int
+	// AllowSingleCluster lets the whole data set be selected as a cluster
+	AllowSingleCluster bool
+	result
+}
+
+// edge is an edge of the minimum spanning tree.
+type edge struct {
+	a, b   int
+	weight float64
+}
+
+// condensed is a cluster of the condensed tree, born when its parent split
+// into two clusters of at least MinClusterSize data points.
+type condensed struct {
+	parent   int
+	birth    float64
+	children []int
+	// stability sums how long every data point stayed in the cluster,
+	// lambda being the inverse of the distance
+	stability float64
+}
+
+// Clustering returns the clusters of ds, without the noise.
+func (m *HDBSCAN) Clustering(ds mygoml.UnsupervisedDataSet) []mygoml.Cluster {
+	dps := ds.DataPoints()
+	if len(dps) == 0 {
+		m.result = result{}
+		return nil
+	}
+	minSize := m.MinClusterSize
+	if minSize < 2 {
+		minSize = 5
+	}
+	minSamples := m.MinSamples
+	if minSamples <= 0 {
+		minSamples = minSize
+	}
+	X := make([][]float64, len(dps))
+	for i, dp := range dps {
+		X[i] = dp.Features()
+	}
+	n := len(X)
+	if n < 2 {
+		return m.clusters(dps, []int{Noise})
+	}
+
+	idx := newIndex(X)
+	core := make([]float64, n)
+	for i := range X {
+		core[i] = idx.kth(i, minSamples)
+	}
+	edges := spanningTree(idx, core)
+
+	// single linkage tree: leaves are the data points, node n+i is made by
+	// edges[i]
+	u := newUnion(n)
+	left := make([]int, n-1)
+	right := make([]int, n-1)
+	size := make([]int, 2*n-1)
+	for i := 0; i < n; i++ {
+		size[i] = 1
+	}
+	for i, e := range edges {
+		left[i], right[i] = u.id[u.find(e.a)], u.id[u.find(e.b)]
+		root := u.union(e.a, e.b)
+		u.id[root] = n + i
+		size[n+i] = size[left[i]] + size[right[i]]
+	}
+
+	// distances of 0 between duplicates get the largest finite lambda
+	maxLambda := 1.0
+	for _, e := range edges {
+		if e.weight > 0 {
+			maxLambda = math.Max(maxLambda, 1/e.weight)
+		}
+	}
+	lambda := func(node int) float64 {
+		if w := edges[node-n].weight; w > 0 {
+			return 1 / w
+		}
+		return maxLambda
+	}
+
+	// condense the tree: a split keeps the cluster going on its large side
+	// when the other side is too small, whose data points fall out of the
+	// cluster, and makes two new clusters when both sides are large enough
+	clusters := []condensed{{parent: -1}}
+	fallen := make([]int, n)
+	leave := func(node, c int, l float64) {
+		stack := []int{node}
+		for len(stack) > 0 {
+			v := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if v < n {
+				fallen[v] = c
+				clusters[c].stability += l - clusters[c].birth
+				continue
+			}
+			stack = append(stack, left[v-n], right[v-n])
+		}
+	}
+	type task struct{ node, cluster int }
+	tasks := []task{{2*n - 2, 0}}
+	for len(tasks) > 0 {
+		t := tasks[len(tasks)-1]
+		tasks = tasks[:len(tasks)-1]
+		node, c := t.node, t.cluster
+		for node >= n {
+			l := lambda(node)
+			a, b := left[node-n], right[node-n]
+			bigA, bigB := size[a] >= minSize, size[b] >= minSize
+			if bigA && bigB {
+				for _, child := range []int{a, b} {
+					clusters = append(clusters, condensed{parent: c, birth: l})
+					id := len(clusters) - 1
+					clusters[c].children = append(clusters[c].children, id)
+					clusters[c].stability += float64(size[child]) * (l - clusters[c].birth)
+					tasks = append(tasks, task{child, id})
+				}
+				break
+			}
+			if !bigA {
+				leave(a, c, l)
+			}
+			if !bigB {
+				leave(b, c, l)
+			}
+			switch {
+			case bigA:
+				node = a
+			case bigB:
+				node = b
+			default:
+				node = -1
+			}
+		}
+	}
+
+	// select the clusters more stable than their selected descendants,
+	// children having larger ids than their parents
+	selected := make([]bool, len(clusters))
+	best := make([]float64, len(clusters))
+	for c := len(clusters) - 1; c >= 0; c-- {
+		children := 0.0
+		for _, child := range clusters[c].children {
+			children = children + best[child]
+		}
+		if c == 0 && !m.AllowSingleCluster {
+			break
+		}
+		if len(clusters[c].children) == 0 || clusters[c].stability >= children {
+			selected[c] = true
+			best[c] = clusters[c].stability
+		} else {
+			best[c] = children
+		}
+	}
+	number := make([]int, len(clusters))
+	count := 0
+	for c := range clusters {
+		number[c] = Noise
+		for p := clusters[c].parent; p >= 0 && selected[c]; p = clusters[p].parent {
+			if selected[p] {
+				selected[c] = false
+			}
+		}
+		if selected[c] {
+			number[c] = count
+			count++
+		}
+	}
+
+	labels := make([]int, n)
+	for i := range labels {
+		labels[i] = Noise
+		for c := fallen[i]; c >= 0; c = clusters[c].parent {
+			if selected[c] {
+				labels[i] = number[c]
+				break
+			}
+		}
+	}
+	return m.clusters(dps, labels)
+}
+
+// spanningTree returns the edges of the minimum spanning tree of the mutual
+// reachability distances by weight, found with Borůvka's algorithm: every
+// round joins each component to its nearest other component, searched in
+// the k-d tree of idx while skipping the subtrees within the component.
+func spanningTree(idx *index, core []float64) []edge {
+	n := len(idx.X)
+	u := newUnion(n)
+	s := &reachSearch{idx: idx, core: core, component: make([]int, n), subtree: make(map[*kdtree.Node]int, n)}
+	best := make([]edge, n)
+	edges := make([]edge, 0, n-1)
+	for len(edges) < n-1 {
+		for i := range s.component {
+			s.component[i] = u.find(i)
+			best[i] = edge{a: -1, b: -1, weight: math.Inf(1)}
+		}
+		s.label(idx.tree.Root)
+		for i := range idx.X {
+			c := s.component[i]
+			// no edge of i weighs less than its core distance
+			if core[i] <= best[c].weight {
+				s.nearest(idx.tree.Root, i, &best[c])
+			}
+		}
+		for c, e := range best {
+			if e.a >= 0 && u.find(e.a) != u.find(e.b) {
+				u.union(e.a, e.b)
+				edges = append(edges, best[c])
+			}
+		}
+	}
+	sort.SliceStable(edges, func(i, j int) bool {
+		return edges[i].weight < edges[j].weight
+	})
+	return edges
+}
+
+// reachSearch finds the nearest data point of another component by mutual
+// reachability distance.
+type reachSearch struct {
+	idx  *index
+	core []float64
+	// component is the root of the component of every data point, subtree
+	// that of every k-d tree node when all its data points share it and
+	// mixed otherwise
+	component []int
+	subtree   map[*kdtree.Node]int
+}
+
+const mixed, empty = -1, -2
+
+func (s *reachSearch) label(node *kdtree.Node) int {
+	if node == nil {
+		return empty
+	}
+	c := s.component[node.Point.(point).i]
+	for _, child := range []*kdtree.Node{node.Left, node.Right} {
+		if l := s.label(child); l != empty && l != c {
+			c = mixed
+		}
+	}
+	s.subtree[node] = c
+	return c
+}
+
+// nearest replaces best by the edge from data point i to a data point of the
+// subtree of node when it is lighter. Ties go to the smaller data points so
+// that every component agrees on the order of the edges and Borůvka makes
+// no cycle.
+func (s *reachSearch) nearest(node *kdtree.Node, i int, best *edge) {
+	c := s.component[i]
+	if node == nil || s.subtree[node] == c {
+		return
+	}
+	x, p := s.idx.X[i], node.Point.(point)
+	if s.component[p.i] != c {
+		w := math.Max(math.Sqrt(p.Point.Distance(kdtree.Point(x))), math.Max(s.core[i], s.core[p.i]))
+		if e := (edge{a: i, b: p.i, weight: w}); lighter(e, *best) {
+			*best = e
+		}
+	}
+	d := x[node.Plane] - p.Point[node.Plane]
+	near, far := node.Left, node.Right
+	if d > 0 {
+		near, far = far, near
+	}
+	s.nearest(near, i, best)
+	if d*d <= best.weight*best.weight {
+		s.nearest(far, i, best)
+	}
+}
+
+// lighter orders edges by weight, then by their smaller and larger data
+// point.
+func lighter(e, f edge) bool {
+	if e.weight != f.weight {
+		return e.weight < f.weight
+	}
+	ea, eb := order(e)
+	fa, fb := order(f)
+	if ea != fa {
+		return ea < fa
+	}
+	return eb < fb
+}
+
+func order(e edge) (int, int) {
+	if e.a < e.b {
+		return e.a, e.b
+	}
+	return e.b, e.a
+}
+
+// union is a disjoint-set forest over the data points.
+type union struct {
+	parent []int
+	size   []int
+	// id is the single linkage tree node of the set of every root
+	id []int
+}
+
+func newUnion(n int) *union {
+	u := &union{parent: make([]int, n), size: make([]int, n), id: make([]int, n)}
+	for i := range u.parent {
+		u.parent[i] = i
+		u.size[i] = 1
+		u.id[i] = i
+	}
+	return u
+}
+
+func (u *union) find(i int) int {
+	for u.parent[i] != i {
+		u.parent[i] = u.parent[u.parent[i]]
+		i = u.parent[i]
+	}
+	return i
+}
+
+// union joins the sets of a and b and returns the new root.
+func (u *union) union(a, b int) int {
+	ra, rb := u.find(a), u.find(b)
+	if u.size[ra] < u.size[rb] {
+		ra, rb = rb, ra
+	}
+	u.parent[rb] = ra
+	u.size[ra] += u.size[rb]
+	return ra
+}
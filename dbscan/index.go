@@ -0,0 +1,81 @@
+package dbscan
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/spatial/kdtree"
+)
+
+// point is a data point of the k-d tree that remembers its position in the
+// data set.
+type point struct {
+	kdtree.Point
+	i int
+}
+
+func (p point) Compare(c kdtree.Comparable, d kdtree.Dim) float64 {
+	return p.Point.Compare(c.(point).Point, d)
+}
+
+func (p point) Distance(c kdtree.Comparable) float64 {
+	return p.Point.Distance(c.(point).Point)
+}
+
+type points []point
+
+func (p points) Index(i int) kdtree.Comparable         { return p[i] }
+func (p points) Len() int                              { return len(p) }
+func (p points) Pivot(d kdtree.Dim) int                { return plane{points: p, Dim: d}.Pivot() }
+func (p points) Slice(start, end int) kdtree.Interface { return p[start:end] }
+
+// plane sorts points along a dimension to find the pivot of a k-d tree node.
+type plane struct {
+	kdtree.Dim
+	points
+}
+
+func (p plane) Less(i, j int) bool {
+	return p.points[i].Point[p.Dim] < p.points[j].Point[p.Dim]
+}
+func (p plane) Pivot() int { return kdtree.Partition(p, kdtree.MedianOfRandoms(p, 100)) }
+func (p plane) Slice(start, end int) kdtree.SortSlicer {
+	p.points = p.points[start:end]
+	return p
+}
+func (p plane) Swap(i, j int) {
+	p.points[i], p.points[j] = p.points[j], p.points[i]
+}
+
+// index answers neighborhood queries over the data points with a k-d tree,
+// which avoids comparing every pair of data points in low dimensions.
+type index struct {
+	X    [][]float64
+	tree *kdtree.Tree
+}
+
+func newIndex(X [][]float64) *index {
+	ps := make(points, len(X))
+	for i, x := range X {
+		ps[i] = point{Point: x, i: i}
+	}
+	return &index{X: X, tree: kdtree.New(ps, false)}
+}
+
+// radius returns the data points within eps of data point i, i included.
+func (idx *index) radius(i int, eps float64) []int {
+	keeper := kdtree.NewDistKeeper(eps * eps)
+	idx.tree.NearestSet(keeper, point{Point: idx.X[i], i: i})
+	out := make([]int, len(keeper.Heap))
+	for k, c := range keeper.Heap {
+		out[k] = c.Comparable.(point).i
+	}
+	return out
+}
+
+// kth returns the distance from data point i to its k-th nearest data
+// point, counting i itself as the first.
+func (idx *index) kth(i, k int) float64 {
+	keeper := kdtree.NewNKeeper(k)
+	idx.tree.NearestSet(keeper, point{Point: idx.X[i], i: i})
+	return math.Sqrt(keeper.Heap[len(keeper.Heap)-1].Dist)
+}
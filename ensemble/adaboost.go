@@ -0,0 +1,167 @@
+package ensemble
+
+import (
+	"math"
+	"mygoml"
+	"sort"
+)
+
+// AdaBoost trains a sequence of weak classifiers, each on a sample that
+// favours the data points the previous ones got wrong, and lets them vote
+// with weights that grow with their accuracy. It uses the SAMME update,
+// which handles any number of classes. As a SupervisedModel cannot be
+// trained on weighted data, the weights are applied by resampling.
+type AdaBoost struct {
+	// Estimator is the weak classifier, e.g. a tree.Model of depth 1
+	Estimator Estimator
+	// Rounds is the maximum number of classifiers, 50 when 0
+	Rounds int
+	// LearningRate shrinks the vote of every classifier, 1 when 0
+	LearningRate float64
+	// Seed makes training reproducible, a time based seed is used when 0
+	Seed    int64
+	models  []mygoml.SupervisedModel
+	alphas  []float64
+	errors  []float64
+	classes []float64
+}
+
+// Train boosts up to Rounds classifiers. It stops early when a classifier
+// makes no error or does no better than chance, in which case it is
+// dropped. The data set needs at least two classes.
+func (m *AdaBoost) Train(dataset mygoml.SupervisedDataSet) error {
+	dps := dataset.DataPoints()
+	n := len(dps)
+	if n == 0 {
+		return mygoml.ErrDatasetEmpty
+	}
+	rounds := m.Rounds
+	if rounds <= 0 {
+		rounds = 50
+	}
+	rate := m.LearningRate
+	if rate <= 0 {
+		rate = 1
+	}
+
+	m.models, m.alphas, m.errors = nil, nil, nil
+	m.classes = labelSet(dps)
+	if len(m.classes) < 2 {
+		return mygoml.ErrIncompatibleDataAndModel("adaboost needs at least two classes")
+	}
+	k := float64(len(m.classes))
+	rnd := newRand(m.Seed)
+	weights := make([]float64, n)
+	for i := range weights {
+		weights[i] = 1 / float64(n)
+	}
+	cumulative := make([]float64, n)
+	wrong := make([]bool, n)
+	var models []mygoml.SupervisedModel
+	var alphas, errors []float64
+	for r := 0; r < rounds; r++ {
+		sum := 0.0
+		for i, w := range weights {
+			sum = sum + w
+			cumulative[i] = sum
+		}
+		sample := make(points, n)
+		for i := range sample {
+			j := sort.SearchFloat64s(cumulative, rnd.Float64()*sum)
+			if j == n {
+				j = n - 1
+			}
+			sample[i] = dps[j]
+		}
+		model := m.Estimator.New()
+		if err := m.Estimator.train(model, sample); err != nil {
+			return err
+		}
+
+		e := 0.0
+		for i, dp := range dps {
+			label, err := m.Estimator.label(model, dp.Features())
+			if err != nil {
+				return err
+			}
+			wrong[i] = label != dp.Target()[0]
+			if wrong[i] {
+				e = e + weights[i]
+			}
+		}
+		e = e / sum
+		if e >= 1-1/k {
+			break
+		}
+		models = append(models, model)
+		errors = append(errors, e)
+		if e <= 0 {
+			alphas = append(alphas, 1)
+			break
+		}
+		alpha := rate * (math.Log((1-e)/e) + math.Log(k-1))
+		alphas = append(alphas, alpha)
+		for i := range weights {
+			if wrong[i] {
+				weights[i] *= math.Exp(alpha)
+			}
+			weights[i] /= sum
+		}
+	}
+	if models == nil {
+		return mygoml.ErrIncompatibleDataAndModel("the estimator does no better than chance")
+	}
+	m.models, m.alphas, m.errors = models, alphas, errors
+	return nil
+}
+
+// Predict returns the class with the highest weighted vote, the smallest
+// on ties.
+func (m *AdaBoost) Predict(features []float64) ([]float64, error) {
+	proba, err := m.PredictProba(features)
+	if err != nil {
+		return nil, err
+	}
+	return best(m.classes, proba), nil
+}
+
+// PredictProba returns the share of the weighted votes of every class, in
+// the order of Classes.
+func (m *AdaBoost) PredictProba(features []float64) ([]float64, error) {
+	if m.models == nil {
+		return nil, errNotTrained
+	}
+	votes := make([]float64, len(m.classes))
+	for t, model := range m.models {
+		label, err := m.Estimator.label(model, features)
+		if err != nil {
+			return nil, err
+		}
+		c, err := classIndex(m.classes, label)
+		if err != nil {
+			return nil, err
+		}
+		votes[c] += m.alphas[t]
+	}
+	return normalize(votes), nil
+}
+
+// Classes returns the sorted class labels of the training data.
+func (m *AdaBoost) Classes() []float64 {
+	return m.classes
+}
+
+// Models returns the trained classifiers.
+func (m *AdaBoost) Models() []mygoml.SupervisedModel {
+	return append([]mygoml.SupervisedModel(nil), m.models...)
+}
+
+// EstimatorWeights returns the vote of every classifier.
+func (m *AdaBoost) EstimatorWeights() []float64 {
+	return m.alphas
+}
+
+// EstimatorErrors returns the weighted training error of every classifier.
+func (m *AdaBoost) EstimatorErrors() []float64 {
+	return m.errors
+}
@@ -0,0 +1,160 @@
+package ensemble
+
+import (
+	"math"
+	"mygoml"
+)
+
+// Bagging trains copies of a classifier on random samples of the data set
+// and predicts the class most of them vote for.
+type Bagging struct {
+	Estimator Estimator
+	// Estimators is the number of models, 10 when 0
+	Estimators int
+	// MaxSamples is the size of every sample as a fraction of the data
+	// set, 1 when 0
+	MaxSamples float64
+	// NoBootstrap draws the samples without replacement, which also
+	// disables the out-of-bag error
+	NoBootstrap bool
+	// Seed makes training reproducible, a time based seed is used when 0
+	Seed     int64
+	models   []mygoml.SupervisedModel
+	classes  []float64
+	oobError float64
+}
+
+// Train trains every model on its own sample of dataset.
+func (m *Bagging) Train(dataset mygoml.SupervisedDataSet) error {
+	dps := dataset.DataPoints()
+	n := len(dps)
+	if n == 0 {
+		return mygoml.ErrDatasetEmpty
+	}
+	estimators := m.Estimators
+	if estimators <= 0 {
+		estimators = 10
+	}
+	size := n
+	if m.MaxSamples > 0 {
+		size = int(math.Max(1, math.Min(float64(n), math.Round(m.MaxSamples*float64(n)))))
+	}
+
+	m.models = nil
+	m.classes = labelSet(dps)
+	rnd := newRand(m.Seed)
+	models := make([]mygoml.SupervisedModel, estimators)
+	inBag := make([][]bool, estimators)
+	for t := range models {
+		inBag[t] = make([]bool, n)
+		sample := make(points, size)
+		if m.NoBootstrap {
+			for i, k := range rnd.Perm(n)[:size] {
+				sample[i] = dps[k]
+				inBag[t][k] = true
+			}
+		} else {
+			for i := range sample {
+				k := rnd.Intn(n)
+				sample[i] = dps[k]
+				inBag[t][k] = true
+			}
+		}
+		models[t] = m.Estimator.New()
+		if err := m.Estimator.train(models[t], sample); err != nil {
+			return err
+		}
+	}
+	m.models = models
+
+	m.oobError = math.NaN()
+	if !m.NoBootstrap {
+		oob, err := m.outOfBag(dps, inBag)
+		if err != nil {
+			return err
+		}
+		m.oobError = oob
+	}
+	return nil
+}
+
+// outOfBag returns the misclassification rate of the votes of the models
+// that did not see a data point, over the data points left out by at least
+// one model.
+func (m *Bagging) outOfBag(dps []mygoml.SupervisedDataPoint, inBag [][]bool) (float64, error) {
+	wrong, count := 0, 0
+	for i, dp := range dps {
+		var models []mygoml.SupervisedModel
+		for t, bag := range inBag {
+			if !bag[i] {
+				models = append(models, m.models[t])
+			}
+		}
+		if len(models) == 0 {
+			continue
+		}
+		votes, err := m.votes(models, dp.Features())
+		if err != nil {
+			return 0, err
+		}
+		count++
+		if best(m.classes, votes)[0] != dp.Target()[0] {
+			wrong++
+		}
+	}
+	if count == 0 {
+		return math.NaN(), nil
+	}
+	return float64(wrong) / float64(count), nil
+}
+
+// votes returns the share of models voting for every class.
+func (m *Bagging) votes(models []mygoml.SupervisedModel, features []float64) ([]float64, error) {
+	votes := make([]float64, len(m.classes))
+	for _, model := range models {
+		label, err := m.Estimator.label(model, features)
+		if err != nil {
+			return nil, err
+		}
+		c, err := classIndex(m.classes, label)
+		if err != nil {
+			return nil, err
+		}
+		votes[c]++
+	}
+	return normalize(votes), nil
+}
+
+// Predict returns the class with the most votes, the smallest on ties.
+func (m *Bagging) Predict(features []float64) ([]float64, error) {
+	proba, err := m.PredictProba(features)
+	if err != nil {
+		return nil, err
+	}
+	return best(m.classes, proba), nil
+}
+
+// PredictProba returns the share of the votes of every class, in the order
+// of Classes.
+func (m *Bagging) PredictProba(features []float64) ([]float64, error) {
+	if m.models == nil {
+		return nil, errNotTrained
+	}
+	return m.votes(m.models, features)
+}
+
+// Classes returns the sorted class labels of the training data.
+func (m *Bagging) Classes() []float64 {
+	return m.classes
+}
+
+// OOBError returns the out-of-bag misclassification rate, NaN without
+// bootstrap.
+func (m *Bagging) OOBError() float64 {
+	return m.oobError
+}
+
+// Models returns the trained models.
+func (m *Bagging) Models() []mygoml.SupervisedModel {
+	return append([]mygoml.SupervisedModel(nil), m.models...)
+}
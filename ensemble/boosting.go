@@ -0,0 +1,575 @@
+package ensemble
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"mygoml"
+	"mygoml/tree"
+	"sort"
+	"time"
+)
+
+// Loss is the loss gradient boosting minimizes. SquaredLoss, AbsoluteLoss
+// and HuberLoss boost regression trees, LogLoss two classes and
+// SoftmaxLoss any number of classes.
+type Loss int
+
+const (
+	SquaredLoss Loss = iota
+	AbsoluteLoss
+	HuberLoss
+	LogLoss
+	SoftmaxLoss
+)
+
+func (l Loss) String() string {
+	switch l {
+	case SquaredLoss:
+		return "squared"
+	case AbsoluteLoss:
+		return "absolute"
+	case HuberLoss:
+		return "huber"
+	case LogLoss:
+		return "log"
+	case SoftmaxLoss:
+		return "softmax"
+	}
+	return fmt.Sprintf("Loss(%d)", int(l))
+}
+
+func (l Loss) classification() bool {
+	return l == LogLoss || l == SoftmaxLoss
+}
+
+// GradientBoosting adds up shallow regression trees, each fit to the
+// negative gradient of the loss at the current predictions and scaled by
+// LearningRate, with leaf values chosen to minimize the loss.
+type GradientBoosting struct {
+	Loss Loss
+	// Rounds is the number of boosting rounds, 100 when 0. SoftmaxLoss
+	// grows one tree per class every round
+	Rounds int
+	// LearningRate shrinks every tree, 0.1 when 0
+	LearningRate float64
+	// MaxDepth of every tree, 3 when 0
+	MaxDepth       int
+	MinSamplesLeaf int
+	// Subsample is the fraction of the data points, drawn without
+	// replacement, every tree is fit to, all of them when 0
+	Subsample float64
+	// HuberAlpha is the quantile of the absolute residuals beyond which
+	// HuberLoss turns linear, 0.9 when 0
+	HuberAlpha float64
+	// MaxBins enables histogram-based split finding: the training data
+	// points are binned once, see tree.Histogram, and every tree only
+	// searches the bin edges
+	MaxBins int
+	// ValidationFraction holds out that fraction of the data points to stop
+	// training once the validation loss has not improved by Tolerance for
+	// NoImprovementRounds rounds, keeping the rounds up to the best one.
+	// Early stopping is off unless both are set
+	ValidationFraction  float64
+	NoImprovementRounds int
+	Tolerance           float64
+	// Seed makes training reproducible, a time based seed is used when 0
+	Seed int64
+
+	// init holds the initial prediction of every output and trees the
+	// trees of every round, one per output
+	init        []float64
+	trees       [][]*tree.Model
+	classes     []float64
+	features    int
+	trainLoss   []float64
+	validLoss   []float64
+	importances []float64
+	// delta is the huber threshold of the last tree
+	delta float64
+}
+
+func (m *GradientBoosting) Train(dataset mygoml.SupervisedDataSet) error {
+	dps := dataset.DataPoints()
+	if len(dps) == 0 {
+		return mygoml.ErrDatasetEmpty
+	}
+	X := make([][]float64, len(dps))
+	y := make([]float64, len(dps))
+	for i, dp := range dps {
+		X[i] = dp.Features()
+		y[i] = dp.Target()[0]
+	}
+	return m.fit(X, y)
+}
+
+func (m *GradientBoosting) fit(X [][]float64, y []float64) error {
+	rounds := m.Rounds
+	if rounds <= 0 {
+		rounds = 100
+	}
+	rate := m.LearningRate
+	if rate <= 0 {
+		rate = 0.1
+	}
+	maxDepth := m.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = 3
+	}
+	seed := m.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rnd := rand.New(rand.NewSource(seed))
+
+	m.features = len(X[0])
+	m.classes, m.trees, m.trainLoss, m.validLoss = nil, nil, nil, nil
+
+	// targets holds one column per output: the regression value, 1 for the
+	// positive class with LogLoss or a one-hot encoding with SoftmaxLoss
+	outputs := 1
+	targets := make([][]float64, len(y))
+	if m.Loss.classification() {
+		seen := make(map[float64]bool)
+		for _, label := range y {
+			if !seen[label] {
+				seen[label] = true
+				m.classes = append(m.classes, label)
+			}
+		}
+		sort.Float64s(m.classes)
+		if m.Loss == LogLoss && len(m.classes) != 2 {
+			msg := fmt.Sprintf("log loss needs 2 classes but got %d, use SoftmaxLoss", len(m.classes))
+			return mygoml.ErrIncompatibleDataAndModel(msg)
+		}
+		if m.Loss == SoftmaxLoss {
+			outputs = len(m.classes)
+		}
+		for i, label := range y {
+			c := sort.SearchFloat64s(m.classes, label)
+			targets[i] = make([]float64, outputs)
+			if m.Loss == SoftmaxLoss {
+				targets[i][c] = 1
+			} else {
+				targets[i][0] = float64(c)
+			}
+		}
+	} else {
+		for i, v := range y {
+			targets[i] = []float64{v}
+		}
+	}
+
+	// hold out the validation set
+	order := rnd.Perm(len(X))
+	var train, valid []int
+	stopping := m.ValidationFraction > 0 && m.NoImprovementRounds > 0
+	if stopping {
+		held := int(math.Round(m.ValidationFraction * float64(len(X))))
+		if held < 1 || held >= len(X) {
+			return mygoml.ErrIncompatibleDataAndModel("validation fraction leaves no training or validation data")
+		}
+		valid, train = order[:held], order[held:]
+		sort.Ints(train)
+	} else {
+		train = order
+		sort.Ints(train)
+	}
+
+	m.init = m.initial(targets, train, outputs)
+	F := make([][]float64, len(X))
+	for i := range F {
+		F[i] = append([]float64(nil), m.init...)
+	}
+
+	sampleSize := len(train)
+	if m.Subsample > 0 && m.Subsample < 1 {
+		sampleSize = int(math.Max(1, math.Round(m.Subsample*float64(len(train)))))
+	}
+	Xs := make([][]float64, sampleSize)
+	residuals := make([]float64, sampleSize)
+
+	// the training data points are binned once, every tree gets the bins
+	// of its sample
+	var histogram *tree.Histogram
+	if m.MaxBins > 0 {
+		Xt := make([][]float64, len(train))
+		for k, i := range train {
+			Xt[k] = X[i]
+		}
+		var err error
+		if histogram, err = tree.NewHistogram(Xt, m.MaxBins); err != nil {
+			return err
+		}
+	}
+
+	best, bestRound := math.Inf(1), 0
+	for round := 0; round < rounds; round++ {
+		sample, sampleHistogram := train, histogram
+		if sampleSize < len(train) {
+			sample = make([]int, sampleSize)
+			positions := rnd.Perm(len(train))[:sampleSize]
+			for k, j := range positions {
+				sample[k] = train[j]
+			}
+			if histogram != nil {
+				sampleHistogram = histogram.Rows(positions)
+			}
+		}
+		for k, i := range sample {
+			Xs[k] = X[i]
+		}
+
+		// softmax trees of a round all start from the same probabilities
+		var probabilities [][]float64
+		if m.Loss == SoftmaxLoss {
+			probabilities = make([][]float64, len(X))
+			for _, i := range sample {
+				probabilities[i] = softmax(F[i])
+			}
+		}
+
+		trees := make([]*tree.Model, outputs)
+		for k := 0; k < outputs; k++ {
+			for s, i := range sample {
+				residuals[s] = m.negativeGradient(targets[i][k], F[i][k], probabilities, i, k)
+			}
+			delta := 0.0
+			if m.Loss == HuberLoss {
+				delta = m.huberDelta(targets, F, sample)
+				for s := range residuals {
+					residuals[s] = math.Max(-delta, math.Min(delta, residuals[s]))
+				}
+			}
+
+			t := &tree.Model{
+				Criterion:      tree.MSE,
+				MaxDepth:       maxDepth,
+				MinSamplesLeaf: m.MinSamplesLeaf,
+				Rand:           rnd,
+			}
+			var err error
+			if sampleHistogram != nil {
+				err = t.FitHistogram(Xs, residuals, sampleHistogram)
+			} else {
+				err = t.Fit(Xs, residuals)
+			}
+			if err != nil {
+				return err
+			}
+			m.updateLeaves(t, X, sample, targets, F, probabilities, k, delta)
+			m.delta = delta
+			trees[k] = t
+		}
+		m.trees = append(m.trees, trees)
+
+		for i := range X {
+			for k, t := range trees {
+				p, _ := t.Predict(X[i])
+				F[i][k] = F[i][k] + rate*p[0]
+			}
+		}
+		m.trainLoss = append(m.trainLoss, m.loss(targets, F, train))
+		if !stopping {
+			continue
+		}
+		loss := m.loss(targets, F, valid)
+		m.validLoss = append(m.validLoss, loss)
+		if loss < best-m.Tolerance {
+			best, bestRound = loss, round
+		} else if round-bestRound >= m.NoImprovementRounds {
+			m.trees = m.trees[:bestRound+1]
+			m.trainLoss = m.trainLoss[:bestRound+1]
+			m.validLoss = m.validLoss[:bestRound+1]
+			break
+		}
+	}
+
+	m.importances = make([]float64, m.features)
+	count := 0.0
+	for _, trees := range m.trees {
+		for _, t := range trees {
+			for f, v := range t.FeatureImportances() {
+				m.importances[f] += v
+			}
+			count++
+		}
+	}
+	for f := range m.importances {
+		m.importances[f] = m.importances[f] / count
+	}
+	return nil
+}
+
+// initial is the constant prediction minimizing the loss on the training
+// data points.
+func (m *GradientBoosting) initial(targets [][]float64, train []int, outputs int) []float64 {
+	init := make([]float64, outputs)
+	values := make([]float64, len(train))
+	for k := 0; k < outputs; k++ {
+		sum := 0.0
+		for j, i := range train {
+			values[j] = targets[i][k]
+			sum = sum + targets[i][k]
+		}
+		mean := sum / float64(len(train))
+		switch m.Loss {
+		case SquaredLoss:
+			init[k] = mean
+		case AbsoluteLoss, HuberLoss:
+			sort.Float64s(values)
+			init[k] = medianOf(values)
+		case LogLoss, SoftmaxLoss:
+			// log-odds for two classes and log-priors with softmax
+			p := math.Max(1e-12, math.Min(1-1e-12, mean))
+			if m.Loss == LogLoss {
+				init[k] = math.Log(p / (1 - p))
+			} else {
+				init[k] = math.Log(p)
+			}
+		}
+	}
+	return init
+}
+
+func (m *GradientBoosting) negativeGradient(y, f float64, probabilities [][]float64, i, k int) float64 {
+	switch m.Loss {
+	case AbsoluteLoss:
+		return sign(y - f)
+	case LogLoss:
+		return y - sigmoid(f)
+	case SoftmaxLoss:
+		return y - probabilities[i][k]
+	}
+	// squared and huber residuals, huber ones are clipped afterwards
+	return y - f
+}
+
+// huberDelta is the HuberAlpha quantile of the absolute residuals.
+func (m *GradientBoosting) huberDelta(targets, F [][]float64, sample []int) float64 {
+	alpha := m.HuberAlpha
+	if alpha <= 0 || alpha >= 1 {
+		alpha = 0.9
+	}
+	abs := make([]float64, len(sample))
+	for s, i := range sample {
+		abs[s] = math.Abs(targets[i][0] - F[i][0])
+	}
+	sort.Float64s(abs)
+	return abs[int(alpha*float64(len(abs)-1))]
+}
+
+// updateLeaves replaces the mean residual in every leaf of t by the value
+// minimizing the loss of the data points of the leaf, or a Newton step for
+// the classification losses.
+func (m *GradientBoosting) updateLeaves(t *tree.Model, X [][]float64, sample []int, targets, F, probabilities [][]float64, k int, delta float64) {
+	if m.Loss == SquaredLoss {
+		return
+	}
+	members := make([][]int, t.Leaves())
+	for _, i := range sample {
+		leaf, _ := t.LeafIndex(X[i])
+		members[leaf] = append(members[leaf], i)
+	}
+
+	for leaf, indices := range members {
+		if len(indices) == 0 {
+			continue
+		}
+		var value float64
+		switch m.Loss {
+		case AbsoluteLoss, HuberLoss:
+			d := make([]float64, len(indices))
+			for j, i := range indices {
+				d[j] = targets[i][0] - F[i][0]
+			}
+			sort.Float64s(d)
+			value = medianOf(d)
+			if m.Loss == HuberLoss {
+				// one step from the median towards the huber minimum
+				med, sum := value, 0.0
+				for _, v := range d {
+					sum = sum + sign(v-med)*math.Min(delta, math.Abs(v-med))
+				}
+				value = med + sum/float64(len(d))
+			}
+		case LogLoss:
+			num, den := 0.0, 0.0
+			for _, i := range indices {
+				p := sigmoid(F[i][0])
+				num = num + targets[i][0] - p
+				den = den + p*(1-p)
+			}
+			value = num / math.Max(den, 1e-12)
+		case SoftmaxLoss:
+			num, den := 0.0, 0.0
+			for _, i := range indices {
+				r := targets[i][k] - probabilities[i][k]
+				num = num + r
+				den = den + math.Abs(r)*(1-math.Abs(r))
+			}
+			K := float64(len(m.classes))
+			value = (K - 1) / K * num / math.Max(den, 1e-12)
+		}
+		t.SetLeafValue(leaf, value)
+	}
+}
+
+// loss is the mean loss of the data points indices at predictions F.
+func (m *GradientBoosting) loss(targets, F [][]float64, indices []int) float64 {
+	sum := 0.0
+	for _, i := range indices {
+		switch m.Loss {
+		case SquaredLoss:
+			d := targets[i][0] - F[i][0]
+			sum = sum + d*d/2
+		case AbsoluteLoss:
+			sum = sum + math.Abs(targets[i][0]-F[i][0])
+		case HuberLoss:
+			// the loss is measured with the delta of the last round
+			d := math.Abs(targets[i][0] - F[i][0])
+			if d <= m.delta {
+				sum = sum + d*d/2
+			} else {
+				sum = sum + m.delta*(d-m.delta/2)
+			}
+		case LogLoss:
+			// log(1+exp(f)) - y*f, with a softplus that does not
+			// overflow for large f
+			y, f := targets[i][0], F[i][0]
+			sum = sum + math.Max(f, 0) + math.Log1p(math.Exp(-math.Abs(f))) - y*f
+		case SoftmaxLoss:
+			p := softmax(F[i])
+			for k, y := range targets[i] {
+				if y > 0 {
+					sum = sum - math.Log(math.Max(p[k], 1e-300))
+				}
+			}
+		}
+	}
+	return sum / float64(len(indices))
+}
+
+func sign(x float64) float64 {
+	if x > 0 {
+		return 1
+	} else if x < 0 {
+		return -1
+	}
+	return 0
+}
+
+func sigmoid(x float64) float64 {
+	return 1 / (1 + math.Exp(-x))
+}
+
+func softmax(z []float64) []float64 {
+	max := z[0]
+	for _, v := range z {
+		max = math.Max(max, v)
+	}
+	out := make([]float64, len(z))
+	sum := 0.0
+	for k, v := range z {
+		out[k] = math.Exp(v - max)
+		sum = sum + out[k]
+	}
+	for k := range out {
+		out[k] = out[k] / sum
+	}
+	return out
+}
+
+// medianOf sorted values.
+func medianOf(sorted []float64) float64 {
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// decision sums the initial prediction and the shrunk trees.
+func (m *GradientBoosting) decision(features []float64) ([]float64, error) {
+	if m.trees == nil {
+		return nil, mygoml.ErrIncompatibleDataAndModel("model is not trained")
+	}
+	if len(features) != m.features {
+		msg := fmt.Sprintf("model expects %d features but got %d features", m.features, len(features))
+		return nil, mygoml.ErrIncompatibleDataAndModel(msg)
+	}
+	rate := m.LearningRate
+	if rate <= 0 {
+		rate = 0.1
+	}
+	F := append([]float64(nil), m.init...)
+	for _, trees := range m.trees {
+		for k, t := range trees {
+			p, _ := t.Predict(features)
+			F[k] = F[k] + rate*p[0]
+		}
+	}
+	return F, nil
+}
+
+// Predict returns the regression value or the most probable class label.
+func (m *GradientBoosting) Predict(features []float64) ([]float64, error) {
+	if !m.Loss.classification() {
+		return m.decision(features)
+	}
+	proba, err := m.PredictProba(features)
+	if err != nil {
+		return nil, err
+	}
+	best := 0
+	for c, p := range proba {
+		if p > proba[best] {
+			best = c
+		}
+	}
+	return []float64{m.classes[best]}, nil
+}
+
+// PredictProba returns the probability of each class, in the order of
+// Classes.
+func (m *GradientBoosting) PredictProba(features []float64) ([]float64, error) {
+	if !m.Loss.classification() {
+		return nil, mygoml.ErrIncompatibleDataAndModel("regression losses have no class probabilities")
+	}
+	F, err := m.decision(features)
+	if err != nil {
+		return nil, err
+	}
+	if m.Loss == LogLoss {
+		p := sigmoid(F[0])
+		return []float64{1 - p, p}, nil
+	}
+	return softmax(F), nil
+}
+
+// Classes returns the sorted class labels of a classification model.
+func (m *GradientBoosting) Classes() []float64 {
+	return append([]float64(nil), m.classes...)
+}
+
+// Stages returns the number of boosting rounds kept after early stopping.
+func (m *GradientBoosting) Stages() int {
+	return len(m.trees)
+}
+
+// TrainLoss returns the mean training loss after every kept round.
+func (m *GradientBoosting) TrainLoss() []float64 {
+	return append([]float64(nil), m.trainLoss...)
+}
+
+// ValidationLoss returns the mean validation loss after every kept round,
+// nil without early stopping.
+func (m *GradientBoosting) ValidationLoss() []float64 {
+	return append([]float64(nil), m.validLoss...)
+}
+
+// FeatureImportances returns the feature importances averaged over the
+// trees.
+func (m *GradientBoosting) FeatureImportances() []float64 {
+	return append([]float64(nil), m.importances...)
+}
@@ -0,0 +1,104 @@
+package ensemble
+
+import (
+	"math/rand"
+	"mygoml"
+	"testing"
+)
+
+func TestGradientBoostingRegression(t *testing.T) {
+	train := wave(rand.New(rand.NewSource(1)), 300)
+	test := wave(rand.New(rand.NewSource(2)), 100)
+	models := map[string]*GradientBoosting{
+		"squared":   {Seed: 1},
+		"huber":     {Loss: HuberLoss, Seed: 1},
+		"histogram": {MaxBins: 32, Subsample: 0.8, Seed: 1},
+	}
+	for name, m := range models {
+		t.Run(name, func(t *testing.T) {
+			if err := m.Train(train); err != nil {
+				t.Fatal(err)
+			}
+			if mse := meanSquaredError(t, m, test); mse > 0.05 {
+				t.Errorf("expected a mean squared error below 0.05, got %g", mse)
+			}
+			loss := m.TrainLoss()
+			if len(loss) != 100 || loss[len(loss)-1] >= loss[0] {
+				t.Errorf("expected 100 decreasing training losses, got %d from %g to %g", len(loss), loss[0], loss[len(loss)-1])
+			}
+		})
+	}
+}
+
+func TestGradientBoostingClassification(t *testing.T) {
+	train := blobs(rand.New(rand.NewSource(1)), 60, 1)
+	test := blobs(rand.New(rand.NewSource(2)), 60, 1)
+
+	t.Run("log loss", func(t *testing.T) {
+		var two mygoml.Points
+		for _, p := range train {
+			if p.Y < 2 {
+				two = append(two, p)
+			}
+		}
+		m := &GradientBoosting{Loss: LogLoss, LearningRate: 1, Rounds: 50, Seed: 1}
+		if err := m.Train(two); err != nil {
+			t.Fatal(err)
+		}
+		proba, err := m.PredictProba([]float64{4, 0})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if proba[1] < 0.9 {
+			t.Errorf("expected class 1 to be likely, got %v", proba)
+		}
+		if err := m.Train(train); err == nil {
+			t.Error("expected an error for three classes")
+		}
+	})
+
+	t.Run("large log loss decision values", func(t *testing.T) {
+		// exp(800) overflows, the loss of a confident right prediction is
+		// still 0 and that of a confident wrong one 800
+		m := &GradientBoosting{Loss: LogLoss}
+		targets := [][]float64{{1}, {0}, {0}}
+		F := [][]float64{{800}, {-800}, {800}}
+		mygoml.FloatEqual(t, "loss", 800.0/3, m.loss(targets, F, []int{0, 1, 2}))
+	})
+
+	t.Run("softmax", func(t *testing.T) {
+		m := &GradientBoosting{Loss: SoftmaxLoss, Rounds: 50, Seed: 1}
+		if err := m.Train(train); err != nil {
+			t.Fatal(err)
+		}
+		if a := mygoml.ModelAccuracy(t, m, test); a < 0.95 {
+			t.Errorf("expected an accuracy of at least 0.95, got %g", a)
+		}
+	})
+}
+
+func TestGradientBoostingEarlyStopping(t *testing.T) {
+	m := &GradientBoosting{
+		Rounds:              1000,
+		LearningRate:        0.5,
+		MaxDepth:            4,
+		ValidationFraction:  0.2,
+		NoImprovementRounds: 5,
+		Seed:                1,
+	}
+	if err := m.Train(wave(rand.New(rand.NewSource(1)), 200)); err != nil {
+		t.Fatal(err)
+	}
+	valid, train := m.ValidationLoss(), m.TrainLoss()
+	if m.Stages() >= 1000 {
+		t.Fatalf("expected to stop early, got %d stages", m.Stages())
+	}
+	if len(valid) != m.Stages() || len(train) != m.Stages() {
+		t.Fatalf("expected a loss per kept round, got %d stages, %d training and %d validation losses", m.Stages(), len(train), len(valid))
+	}
+	for i, loss := range valid {
+		if loss < valid[m.Stages()-1] {
+			t.Errorf("expected the kept rounds to end at the best validation loss, round %d has %g", i, loss)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package ensemble
+
+import (
+	"math"
+	"math/rand"
+	"mygoml"
+	"testing"
+)
+
+// blobs draws n points of each of three classes around (0, 0), (4, 0) and
+// (0, 4), with standard deviation sd.
+func blobs(rnd *rand.Rand, n int, sd float64) mygoml.Points {
+	return mygoml.Blobs(rnd, n, sd, []float64{0, 0}, []float64{4, 0}, []float64{0, 4})
+}
+
+// wave samples y = sin(x) + 0.1*noise on [0, 6].
+func wave(rnd *rand.Rand, n int) mygoml.Points {
+	var d mygoml.Points
+	for i := 0; i < n; i++ {
+		x := 6 * rnd.Float64()
+		d = append(d, mygoml.Point{X: []float64{x}, Y: math.Sin(x) + 0.1*rnd.NormFloat64()})
+	}
+	return d
+}
+
+func meanSquaredError(t *testing.T, m mygoml.SupervisedModel, d mygoml.Points) float64 {
+	t.Helper()
+	sum := 0.0
+	for _, p := range d {
+		out, err := m.Predict(p.X)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sum = sum + (out[0]-p.Y)*(out[0]-p.Y)
+	}
+	return sum / float64(len(d))
+}
@@ -0,0 +1,371 @@
+package ensemble
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"mygoml"
+	"mygoml/tree"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// forest is the bagging machinery shared by RandomForest and ExtraTrees.
+type forest struct {
+	trees          []*tree.Model
+	classes        []float64
+	classification bool
+	features       int
+	oobError       float64
+	importances    []float64
+}
+
+// forestConfig is what RandomForest and ExtraTrees tell fit about the
+// trees to grow.
+type forestConfig struct {
+	trees           int
+	criterion       tree.Criterion
+	maxDepth        int
+	minSamplesSplit int
+	minSamplesLeaf  int
+	maxFeatures     int
+	splitter        tree.Splitter
+	bootstrap       bool
+	workers         int
+	seed            int64
+}
+
+// defaultMaxFeatures is the square root of the number of features for
+// classification and all of them for regression.
+func defaultMaxFeatures(criterion tree.Criterion, features int) int {
+	if criterion == tree.Gini || criterion == tree.Entropy {
+		return int(math.Max(1, math.Round(math.Sqrt(float64(features)))))
+	}
+	return features
+}
+
+func (f *forest) train(dataset mygoml.SupervisedDataSet, cfg forestConfig) error {
+	dps := dataset.DataPoints()
+	if len(dps) == 0 {
+		return mygoml.ErrDatasetEmpty
+	}
+	X := make([][]float64, len(dps))
+	y := make([]float64, len(dps))
+	for i, dp := range dps {
+		X[i] = dp.Features()
+		y[i] = dp.Target()[0]
+	}
+	return f.fit(X, y, cfg)
+}
+
+func (f *forest) fit(X [][]float64, y []float64, cfg forestConfig) error {
+	n := len(X)
+	if cfg.trees <= 0 {
+		cfg.trees = 100
+	}
+	*f = forest{
+		classification: cfg.criterion == tree.Gini || cfg.criterion == tree.Entropy,
+		features:       len(X[0]),
+		trees:          make([]*tree.Model, cfg.trees),
+	}
+	if f.classification {
+		seen := make(map[float64]bool)
+		for _, label := range y {
+			if !seen[label] {
+				seen[label] = true
+				f.classes = append(f.classes, label)
+			}
+		}
+		sort.Float64s(f.classes)
+	}
+	if cfg.maxFeatures <= 0 {
+		cfg.maxFeatures = defaultMaxFeatures(cfg.criterion, f.features)
+	}
+	if cfg.workers <= 0 {
+		cfg.workers = runtime.NumCPU()
+	}
+	if cfg.seed == 0 {
+		cfg.seed = time.Now().UnixNano()
+	}
+
+	// every tree draws from its own source so that the forest does not
+	// depend on how the trees are spread over the workers
+	inBag := make([][]bool, len(f.trees))
+	errs := make([]error, len(f.trees))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < cfg.workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for t := range jobs {
+				rnd := rand.New(rand.NewSource(cfg.seed + int64(t)))
+				Xt, yt := X, y
+				if cfg.bootstrap {
+					inBag[t] = make([]bool, n)
+					Xt, yt = make([][]float64, n), make([]float64, n)
+					for i := range Xt {
+						k := rnd.Intn(n)
+						Xt[i], yt[i] = X[k], y[k]
+						inBag[t][k] = true
+					}
+				}
+				f.trees[t] = &tree.Model{
+					Criterion:       cfg.criterion,
+					MaxDepth:        cfg.maxDepth,
+					MinSamplesSplit: cfg.minSamplesSplit,
+					MinSamplesLeaf:  cfg.minSamplesLeaf,
+					MaxFeatures:     cfg.maxFeatures,
+					Splitter:        cfg.splitter,
+					Rand:            rnd,
+				}
+				errs[t] = f.trees[t].Fit(Xt, yt)
+			}
+		}()
+	}
+	for t := range f.trees {
+		jobs <- t
+	}
+	close(jobs)
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	f.importances = make([]float64, f.features)
+	for _, t := range f.trees {
+		for i, v := range t.FeatureImportances() {
+			f.importances[i] += v / float64(len(f.trees))
+		}
+	}
+	f.oobError = math.NaN()
+	if cfg.bootstrap {
+		f.oobError = f.outOfBag(X, y, inBag)
+	}
+	return nil
+}
+
+// outOfBag predicts every data point with the trees that did not see it
+// and returns the misclassification rate or the mean squared error over
+// the data points left out by at least one tree.
+func (f *forest) outOfBag(X [][]float64, y []float64, inBag [][]bool) float64 {
+	sum, count := 0.0, 0
+	for i := range X {
+		var trees []*tree.Model
+		for t, bag := range inBag {
+			if !bag[i] {
+				trees = append(trees, f.trees[t])
+			}
+		}
+		if len(trees) == 0 {
+			continue
+		}
+		prediction := f.aggregate(trees, X[i])
+		count++
+		if f.classification {
+			if prediction[0] != y[i] {
+				sum++
+			}
+		} else {
+			sum = sum + (prediction[0]-y[i])*(prediction[0]-y[i])
+		}
+	}
+	if count == 0 {
+		return math.NaN()
+	}
+	return sum / float64(count)
+}
+
+// probabilities averages the class probabilities of trees, in the order of
+// the classes of the forest.
+func (f *forest) probabilities(trees []*tree.Model, features []float64) []float64 {
+	out := make([]float64, len(f.classes))
+	for _, t := range trees {
+		proba, _ := t.PredictProba(features)
+		for k, class := range t.Classes() {
+			c := sort.SearchFloat64s(f.classes, class)
+			out[c] += proba[k] / float64(len(trees))
+		}
+	}
+	return out
+}
+
+// aggregate predicts the class with the highest averaged probability or
+// the mean of the regression values of trees.
+func (f *forest) aggregate(trees []*tree.Model, features []float64) []float64 {
+	if f.classification {
+		proba := f.probabilities(trees, features)
+		best := 0
+		for c, p := range proba {
+			if p > proba[best] {
+				best = c
+			}
+		}
+		return []float64{f.classes[best]}
+	}
+	sum := 0.0
+	for _, t := range trees {
+		p, _ := t.Predict(features)
+		sum = sum + p[0]
+	}
+	return []float64{sum / float64(len(trees))}
+}
+
+func (f *forest) check(features []float64) error {
+	if f.trees == nil {
+		return mygoml.ErrIncompatibleDataAndModel("model is not trained")
+	}
+	if len(features) != f.features {
+		msg := fmt.Sprintf("model expects %d features but got %d features", f.features, len(features))
+		return mygoml.ErrIncompatibleDataAndModel(msg)
+	}
+	return nil
+}
+
+func (f *forest) predict(features []float64) ([]float64, error) {
+	if err := f.check(features); err != nil {
+		return nil, err
+	}
+	return f.aggregate(f.trees, features), nil
+}
+
+func (f *forest) predictProba(features []float64) ([]float64, error) {
+	if err := f.check(features); err != nil {
+		return nil, err
+	}
+	if !f.classification {
+		return nil, mygoml.ErrIncompatibleDataAndModel("regression forests have no class probabilities")
+	}
+	return f.probabilities(f.trees, features), nil
+}
+
+// RandomForest averages decision trees grown on bootstrap samples of the
+// data, each split searching a random subset of the features.
+type RandomForest struct {
+	// Trees is the number of trees, 100 when 0
+	Trees     int
+	Criterion tree.Criterion
+	// MaxDepth, MinSamplesSplit and MinSamplesLeaf are passed to every tree
+	MaxDepth        int
+	MinSamplesSplit int
+	MinSamplesLeaf  int
+	// MaxFeatures is the number of features searched at every split, the
+	// square root of the number of features for classification and all of
+	// them for regression when 0
+	MaxFeatures int
+	// NoBootstrap grows every tree on the whole data set, which also
+	// disables the out-of-bag estimate
+	NoBootstrap bool
+	// Workers is the number of goroutines growing trees, one per CPU when 0
+	Workers int
+	// Seed makes training reproducible, a time based seed is used when 0
+	Seed int64
+	forest
+}
+
+func (m *RandomForest) Train(dataset mygoml.SupervisedDataSet) error {
+	return m.forest.train(dataset, forestConfig{
+		trees:           m.Trees,
+		criterion:       m.Criterion,
+		maxDepth:        m.MaxDepth,
+		minSamplesSplit: m.MinSamplesSplit,
+		minSamplesLeaf:  m.MinSamplesLeaf,
+		maxFeatures:     m.MaxFeatures,
+		splitter:        tree.Best,
+		bootstrap:       !m.NoBootstrap,
+		workers:         m.Workers,
+		seed:            m.Seed,
+	})
+}
+
+// Predict returns the class with the highest averaged probability, or the
+// averaged regression value.
+func (m *RandomForest) Predict(features []float64) ([]float64, error) {
+	return m.forest.predict(features)
+}
+
+// PredictProba returns the class probabilities averaged over the trees, in
+// the order of Classes.
+func (m *RandomForest) PredictProba(features []float64) ([]float64, error) {
+	return m.forest.predictProba(features)
+}
+
+// ExtraTrees averages extremely randomized trees, whose splits draw one
+// random threshold for each of a random subset of the features and keep
+// the best of them. Trees are grown on the whole data set unless
+// Bootstrap is set.
+type ExtraTrees struct {
+	// Trees is the number of trees, 100 when 0
+	Trees     int
+	Criterion tree.Criterion
+	// MaxDepth, MinSamplesSplit and MinSamplesLeaf are passed to every tree
+	MaxDepth        int
+	MinSamplesSplit int
+	MinSamplesLeaf  int
+	// MaxFeatures is the number of features searched at every split, the
+	// square root of the number of features for classification and all of
+	// them for regression when 0
+	MaxFeatures int
+	// Bootstrap grows every tree on a bootstrap sample, which enables the
+	// out-of-bag estimate
+	Bootstrap bool
+	// Workers is the number of goroutines growing trees, one per CPU when 0
+	Workers int
+	// Seed makes training reproducible, a time based seed is used when 0
+	Seed int64
+	forest
+}
+
+func (m *ExtraTrees) Train(dataset mygoml.SupervisedDataSet) error {
+	return m.forest.train(dataset, forestConfig{
+		trees:           m.Trees,
+		criterion:       m.Criterion,
+		maxDepth:        m.MaxDepth,
+		minSamplesSplit: m.MinSamplesSplit,
+		minSamplesLeaf:  m.MinSamplesLeaf,
+		maxFeatures:     m.MaxFeatures,
+		splitter:        tree.Random,
+		bootstrap:       m.Bootstrap,
+		workers:         m.Workers,
+		seed:            m.Seed,
+	})
+}
+
+// Predict returns the class with the highest averaged probability, or the
+// averaged regression value.
+func (m *ExtraTrees) Predict(features []float64) ([]float64, error) {
+	return m.forest.predict(features)
+}
+
+// PredictProba returns the class probabilities averaged over the trees, in
+// the order of Classes.
+func (m *ExtraTrees) PredictProba(features []float64) ([]float64, error) {
+	return m.forest.predictProba(features)
+}
+
+// Classes returns the sorted class labels of a classification forest.
+func (f *forest) Classes() []float64 {
+	return append([]float64(nil), f.classes...)
+}
+
+// OOBError returns the out-of-bag misclassification rate, or mean squared
+// error for regression, estimated on the data points each tree did not
+// see. It is NaN without bootstrap samples.
+func (f *forest) OOBError() float64 {
+	return f.oobError
+}
+
+// FeatureImportances returns the feature importances averaged over the
+// trees.
+func (f *forest) FeatureImportances() []float64 {
+	return append([]float64(nil), f.importances...)
+}
+
+// Estimators returns the trees of the forest.
+func (f *forest) Estimators() []*tree.Model {
+	return append([]*tree.Model(nil), f.trees...)
+}
@@ -0,0 +1,93 @@
+package ensemble
+
+import (
+	"math"
+	"math/rand"
+	"mygoml"
+	"mygoml/tree"
+	"testing"
+)
+
+func TestRandomForest(t *testing.T) {
+	train := blobs(rand.New(rand.NewSource(1)), 60, 1)
+	test := blobs(rand.New(rand.NewSource(2)), 60, 1)
+
+	t.Run("classification", func(t *testing.T) {
+		m := &RandomForest{Trees: 50, Seed: 1}
+		if err := m.Train(train); err != nil {
+			t.Fatal(err)
+		}
+		mygoml.DeepEqual(t, "classes", []float64{0, 1, 2}, m.Classes())
+		if a := mygoml.ModelAccuracy(t, m, test); a < 0.95 {
+			t.Errorf("expected an accuracy of at least 0.95, got %g", a)
+		}
+		// the out-of-bag error estimates the test error
+		oob := m.OOBError()
+		if math.IsNaN(oob) || oob > 0.1 {
+			t.Errorf("expected an out-of-bag error below 0.1, got %g", oob)
+		}
+		proba, err := m.PredictProba([]float64{4, 0})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if proba[1] < 0.9 {
+			t.Errorf("expected class 1 to be likely, got %v", proba)
+		}
+	})
+
+	t.Run("reproducible", func(t *testing.T) {
+		a := &RandomForest{Trees: 10, Seed: 7, Workers: 1}
+		b := &RandomForest{Trees: 10, Seed: 7, Workers: 4}
+		if err := a.Train(train); err != nil {
+			t.Fatal(err)
+		}
+		if err := b.Train(train); err != nil {
+			t.Fatal(err)
+		}
+		mygoml.FloatEqual(t, "oob error", a.OOBError(), b.OOBError())
+		for _, p := range test[:20] {
+			pa, _ := a.PredictProba(p.X)
+			pb, _ := b.PredictProba(p.X)
+			mygoml.DeepEqual(t, "probabilities", pa, pb)
+		}
+	})
+
+	t.Run("no bootstrap", func(t *testing.T) {
+		m := &RandomForest{Trees: 5, NoBootstrap: true}
+		if err := m.Train(train); err != nil {
+			t.Fatal(err)
+		}
+		if !math.IsNaN(m.OOBError()) {
+			t.Errorf("expected a NaN out-of-bag error, got %g", m.OOBError())
+		}
+	})
+
+	t.Run("regression", func(t *testing.T) {
+		m := &RandomForest{Trees: 50, Criterion: tree.MSE, Seed: 1}
+		if err := m.Train(wave(rand.New(rand.NewSource(3)), 300)); err != nil {
+			t.Fatal(err)
+		}
+		if mse := m.OOBError(); mse > 0.05 {
+			t.Errorf("expected an out-of-bag mean squared error below 0.05, got %g", mse)
+		}
+		if mse := meanSquaredError(t, m, wave(rand.New(rand.NewSource(4)), 100)); mse > 0.05 {
+			t.Errorf("expected a mean squared error below 0.05, got %g", mse)
+		}
+		if _, err := m.PredictProba([]float64{1}); err == nil {
+			t.Error("expected an error for the probabilities of a regression forest")
+		}
+	})
+}
+
+func TestExtraTrees(t *testing.T) {
+	m := &ExtraTrees{Trees: 50, Bootstrap: true, Seed: 1}
+	if err := m.Train(blobs(rand.New(rand.NewSource(1)), 60, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if a := mygoml.ModelAccuracy(t, m, blobs(rand.New(rand.NewSource(2)), 60, 1)); a < 0.95 {
+		t.Errorf("expected an accuracy of at least 0.95, got %g", a)
+	}
+	if oob := m.OOBError(); math.IsNaN(oob) || oob > 0.1 {
+		t.Errorf("expected an out-of-bag error below 0.1, got %g", oob)
+	}
+}
@@ -0,0 +1,172 @@
+package ensemble
+
+import (
+	"fmt"
+	"math/rand"
+	"mygoml"
+	"sort"
+	"time"
+)
+
+// Factory creates an untrained model, so that a meta-model can train as
+// many copies of it as it needs.
+type Factory func() mygoml.SupervisedModel
+
+// Decoder turns the output of a model into a class label.
+type Decoder func(output []float64) float64
+
+// Label reads the class label from the first output, which is what the
+// classifiers of knn, tree and naivebayes return.
+func Label(output []float64) float64 {
+	return output[0]
+}
+
+// Threshold returns a decoder that labels a first output above cut as above
+// and any other as below, e.g. Threshold(0.5, 0, 1) for the probability of
+// logregres.Model or Threshold(0, -1, 1) for the sign of pla.Model.
+func Threshold(cut, below, above float64) Decoder {
+	return func(output []float64) float64 {
+		if output[0] > cut {
+			return above
+		}
+		return below
+	}
+}
+
+// Encoder turns a class label into the target a model trains on.
+type Encoder func(label float64) []float64
+
+// Estimator is a base model of a meta-model. Encoder and Decoder let models
+// that expect other targets, such as pla.Model and its -1 and 1, take part
+// with the class labels of the data set.
+type Estimator struct {
+	New Factory
+	// Encoder turns the class labels into the targets of the model, which
+	// trains on the targets of the data set when nil
+	Encoder Encoder
+	// Decoder turns the predictions of the model into class labels, Label
+	// when nil
+	Decoder Decoder
+}
+
+// Probabilistic is a classifier that also predicts the probability of each
+// class, in the order of its Classes.
+type Probabilistic interface {
+	mygoml.SupervisedModel
+	PredictProba(features []float64) ([]float64, error)
+	Classes() []float64
+}
+
+// encoded is a data point with the target of an Encoder.
+type encoded struct {
+	mygoml.SupervisedDataPoint
+	target []float64
+}
+
+func (e encoded) Target() []float64 {
+	return e.target
+}
+
+// train trains a model created by e on dataset.
+func (e Estimator) train(model mygoml.SupervisedModel, dataset mygoml.SupervisedDataSet) error {
+	if e.Encoder == nil {
+		return model.Train(dataset)
+	}
+	dps := dataset.DataPoints()
+	targets := make(points, len(dps))
+	for i, dp := range dps {
+		targets[i] = encoded{dp, e.Encoder(dp.Target()[0])}
+	}
+	return model.Train(targets)
+}
+
+// label predicts the class label of features with a model created by e.
+func (e Estimator) label(model mygoml.SupervisedModel, features []float64) (float64, error) {
+	output, err := model.Predict(features)
+	if err != nil {
+		return 0, err
+	}
+	if e.Decoder == nil {
+		return Label(output), nil
+	}
+	return e.Decoder(output), nil
+}
+
+// points is a data set made of data points of another one.
+type points []mygoml.SupervisedDataPoint
+
+func (p points) DataPoints() []mygoml.SupervisedDataPoint {
+	return p
+}
+
+// labelSet returns the sorted distinct class labels of dps.
+func labelSet(dps []mygoml.SupervisedDataPoint) []float64 {
+	seen := make(map[float64]bool)
+	var classes []float64
+	for _, dp := range dps {
+		label := dp.Target()[0]
+		if !seen[label] {
+			seen[label] = true
+			classes = append(classes, label)
+		}
+	}
+	sort.Float64s(classes)
+	return classes
+}
+
+// classIndex returns the index of label in the sorted classes.
+func classIndex(classes []float64, label float64) (int, error) {
+	c := sort.SearchFloat64s(classes, label)
+	if c == len(classes) || classes[c] != label {
+		return 0, mygoml.ErrIncompatibleDataAndModel(fmt.Sprintf("estimator predicted the unknown class %g", label))
+	}
+	return c, nil
+}
+
+// spread adds the probabilities of a model, in the order of its classes, to
+// out, in the order of classes.
+func spread(out []float64, classes, modelClasses, proba []float64, weight float64) error {
+	for k, class := range modelClasses {
+		c, err := classIndex(classes, class)
+		if err != nil {
+			return err
+		}
+		out[c] += weight * proba[k]
+	}
+	return nil
+}
+
+// best returns the class with the highest score, the smallest on ties.
+func best(classes, scores []float64) []float64 {
+	b := 0
+	for c, s := range scores {
+		if s > scores[b] {
+			b = c
+		}
+	}
+	return []float64{classes[b]}
+}
+
+// normalize scales scores to sum to 1.
+func normalize(scores []float64) []float64 {
+	sum := 0.0
+	for _, s := range scores {
+		sum = sum + s
+	}
+	if sum > 0 {
+		for c := range scores {
+			scores[c] /= sum
+		}
+	}
+	return scores
+}
+
+// newRand returns a source seeded with seed, or with the time when 0.
+func newRand(seed int64) *rand.Rand {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return rand.New(rand.NewSource(seed))
+}
+
+var errNotTrained = mygoml.ErrIncompatibleDataAndModel("model is not trained")
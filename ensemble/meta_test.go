@@ -0,0 +1,129 @@
+package ensemble
+
+import (
+	"math/rand"
+	"mygoml"
+	"mygoml/naivebayes"
+	"mygoml/tree"
+	"testing"
+)
+
+// constant always predicts the same label and has no probabilities.
+type constant float64
+
+func (c constant) Train(mygoml.SupervisedDataSet) error { return nil }
+
+func (c constant) Predict([]float64) ([]float64, error) { return []float64{float64(c)}, nil }
+
+func stump() mygoml.SupervisedModel {
+	return &tree.Model{MaxDepth: 1}
+}
+
+func gaussian() mygoml.SupervisedModel {
+	return &naivebayes.Gaussian{}
+}
+
+func TestVoting(t *testing.T) {
+	train := blobs(rand.New(rand.NewSource(1)), 60, 1)
+	test := blobs(rand.New(rand.NewSource(2)), 60, 1)
+	deep := func() mygoml.SupervisedModel { return &tree.Model{MaxDepth: 3} }
+
+	for _, soft := range []bool{false, true} {
+		m := &Voting{Estimators: []Estimator{{New: deep}, {New: gaussian}, {New: stump}}, Soft: soft}
+		if err := m.Train(train); err != nil {
+			t.Fatal(err)
+		}
+		if a := mygoml.ModelAccuracy(t, m, test); a < 0.9 {
+			t.Errorf("soft %v: expected an accuracy of at least 0.9, got %g", soft, a)
+		}
+		proba, err := m.PredictProba([]float64{0, 4})
+		if err != nil {
+			t.Fatal(err)
+		}
+		mygoml.FloatEqual(t, "probability sum", 1, proba[0]+proba[1]+proba[2])
+	}
+
+	t.Run("weights", func(t *testing.T) {
+		two := func() mygoml.SupervisedModel { return constant(2) }
+		m := &Voting{Estimators: []Estimator{{New: gaussian}, {New: two}}, Weights: []float64{1, 2}}
+		if err := m.Train(train); err != nil {
+			t.Fatal(err)
+		}
+		out, err := m.Predict([]float64{4, 0})
+		if err != nil {
+			t.Fatal(err)
+		}
+		mygoml.DeepEqual(t, "prediction", []float64{2}, out)
+	})
+
+	t.Run("soft without probabilities", func(t *testing.T) {
+		two := func() mygoml.SupervisedModel { return constant(2) }
+		m := &Voting{Estimators: []Estimator{{New: gaussian}, {New: two}}, Soft: true}
+		if err := m.Train(train); err == nil {
+			t.Error("expected an error for an estimator without probabilities")
+		}
+	})
+}
+
+func TestStacking(t *testing.T) {
+	train := blobs(rand.New(rand.NewSource(1)), 60, 1)
+	test := blobs(rand.New(rand.NewSource(2)), 60, 1)
+
+	// a stump only separates two of the three classes, the final tree
+	// learns to combine it with the naive Bayes probabilities
+	for _, proba := range []bool{false, true} {
+		m := &Stacking{
+			Estimators: []Estimator{{New: stump}, {New: gaussian}},
+			Final:      Estimator{New: func() mygoml.SupervisedModel { return &tree.Model{MaxDepth: 3} }},
+			Proba:      proba,
+			Seed:       1,
+		}
+		if err := m.Train(train); err != nil {
+			t.Fatal(err)
+		}
+		mygoml.DeepEqual(t, "classes", []float64{0, 1, 2}, m.Classes())
+		if a := mygoml.ModelAccuracy(t, m, test); a < 0.9 {
+			t.Errorf("proba %v: expected an accuracy of at least 0.9, got %g", proba, a)
+		}
+	}
+}
+
+func TestAdaBoost(t *testing.T) {
+	train := blobs(rand.New(rand.NewSource(1)), 60, 1)
+	test := blobs(rand.New(rand.NewSource(2)), 60, 1)
+
+	single := stump()
+	if err := single.Train(train); err != nil {
+		t.Fatal(err)
+	}
+	m := &AdaBoost{Estimator: Estimator{New: stump}, Rounds: 20, Seed: 1}
+	if err := m.Train(train); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(m.Models()); n < 2 || n != len(m.EstimatorWeights()) || n != len(m.EstimatorErrors()) {
+		t.Errorf("expected a weight and an error for each of several models, got %d models, %d weights and %d errors",
+			n, len(m.EstimatorWeights()), len(m.EstimatorErrors()))
+	}
+	// a single stump cannot tell three classes apart
+	if a, b := mygoml.ModelAccuracy(t, single, test), mygoml.ModelAccuracy(t, m, test); b < 0.9 || b <= a {
+		t.Errorf("expected boosting to beat a single stump with at least 0.9, got %g and %g", b, a)
+	}
+	proba, err := m.PredictProba([]float64{4, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proba[1] < proba[0] || proba[1] < proba[2] {
+		t.Errorf("expected class 1 to be the most likely, got %v", proba)
+	}
+
+	var one mygoml.Points
+	for _, p := range train {
+		if p.Y == 1 {
+			one = append(one, p)
+		}
+	}
+	expected := mygoml.ErrIncompatibleDataAndModel("adaboost needs at least two classes")
+	if err := m.Train(one); err != expected {
+		t.Errorf("expected %v on a single class, got %v", expected, err)
+	}
+}
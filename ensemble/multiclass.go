@@ -0,0 +1,204 @@
+package ensemble
+
+import (
+	"math"
+	"mygoml"
+)
+
+// binaryTargets relabels the data points of dps whose class is in keep,
+// with 1 for the positive class and negative for the others.
+func binaryTargets(dps []mygoml.SupervisedDataPoint, keep func(label float64) bool, positive, negative float64) points {
+	var out points
+	for _, dp := range dps {
+		label := dp.Target()[0]
+		if !keep(label) {
+			continue
+		}
+		target := negative
+		if label == positive {
+			target = 1
+		}
+		out = append(out, encoded{dp, []float64{target}})
+	}
+	return out
+}
+
+// OneVsRest solves a multi-class problem with a binary model per class,
+// trained to tell the class from all the others, and predicts the class
+// whose model scores highest.
+type OneVsRest struct {
+	// New creates the binary model. It trains on the target 1 for its
+	// class and Negative for the others, and the first component of its
+	// prediction is a score that grows with its class, e.g. the
+	// probability of logregres.Model
+	New Factory
+	// Negative is the target of the other classes, e.g. -1 for pla.Model
+	Negative float64
+	models   []mygoml.SupervisedModel
+	classes  []float64
+}
+
+// Train trains a binary model per class of dataset.
+func (m *OneVsRest) Train(dataset mygoml.SupervisedDataSet) error {
+	dps := dataset.DataPoints()
+	if len(dps) == 0 {
+		return mygoml.ErrDatasetEmpty
+	}
+	classes := labelSet(dps)
+	if len(classes) < 2 {
+		return mygoml.ErrIncompatibleDataAndModel("one-vs-rest needs at least two classes")
+	}
+	if m.Negative >= 1 {
+		return mygoml.ErrIncompatibleDataAndModel("the negative target must be below 1")
+	}
+
+	m.models, m.classes = nil, classes
+	all := func(float64) bool { return true }
+	models := make([]mygoml.SupervisedModel, len(classes))
+	for c, class := range classes {
+		models[c] = m.New()
+		if err := models[c].Train(binaryTargets(dps, all, class, m.Negative)); err != nil {
+			return err
+		}
+	}
+	m.models = models
+	return nil
+}
+
+// Scores returns the score of the model of every class, in the order of
+// Classes.
+func (m *OneVsRest) Scores(features []float64) ([]float64, error) {
+	if m.models == nil {
+		return nil, errNotTrained
+	}
+	scores := make([]float64, len(m.models))
+	for c, model := range m.models {
+		output, err := model.Predict(features)
+		if err != nil {
+			return nil, err
+		}
+		scores[c] = output[0]
+	}
+	return scores, nil
+}
+
+// Predict returns the class with the highest score, the smallest on ties.
+func (m *OneVsRest) Predict(features []float64) ([]float64, error) {
+	scores, err := m.Scores(features)
+	if err != nil {
+		return nil, err
+	}
+	return best(m.classes, scores), nil
+}
+
+// Classes returns the sorted class labels of the training data.
+func (m *OneVsRest) Classes() []float64 {
+	return m.classes
+}
+
+// Models returns the binary model of every class, in the order of Classes.
+func (m *OneVsRest) Models() []mygoml.SupervisedModel {
+	return append([]mygoml.SupervisedModel(nil), m.models...)
+}
+
+// OneVsOne solves a multi-class problem with a binary model per pair of
+// classes, trained on the data points of these two classes only, and
+// predicts the class that wins the most pairs.
+type OneVsOne struct {
+	// New creates the binary model. Of the two classes of a pair, it
+	// trains on the target 1 for the larger and Negative for the smaller,
+	// and the first component of its prediction is closer to 1 than to
+	// Negative for the larger, e.g. the probability of logregres.Model
+	New Factory
+	// Negative is the target of the smaller class, e.g. -1 for pla.Model
+	Negative float64
+	models   []mygoml.SupervisedModel
+	// pairs holds the classes of every model, smaller first
+	pairs   [][2]int
+	classes []float64
+}
+
+// Train trains a binary model per pair of classes of dataset.
+func (m *OneVsOne) Train(dataset mygoml.SupervisedDataSet) error {
+	dps := dataset.DataPoints()
+	if len(dps) == 0 {
+		return mygoml.ErrDatasetEmpty
+	}
+	classes := labelSet(dps)
+	if len(classes) < 2 {
+		return mygoml.ErrIncompatibleDataAndModel("one-vs-one needs at least two classes")
+	}
+	if m.Negative >= 1 {
+		return mygoml.ErrIncompatibleDataAndModel("the negative target must be below 1")
+	}
+
+	m.models, m.pairs, m.classes = nil, nil, classes
+	var models []mygoml.SupervisedModel
+	var pairs [][2]int
+	for a := 0; a < len(classes); a++ {
+		for b := a + 1; b < len(classes); b++ {
+			pair := func(label float64) bool {
+				return label == classes[a] || label == classes[b]
+			}
+			model := m.New()
+			if err := model.Train(binaryTargets(dps, pair, classes[b], m.Negative)); err != nil {
+				return err
+			}
+			models = append(models, model)
+			pairs = append(pairs, [2]int{a, b})
+		}
+	}
+	m.models, m.pairs = models, pairs
+	return nil
+}
+
+// Scores returns the number of pairs every class wins, in the order of
+// Classes. Ties are broken by adding the summed confidence of the models,
+// scaled into (-1/3, 1/3) so that it never outweighs a vote.
+func (m *OneVsOne) Scores(features []float64) ([]float64, error) {
+	if m.models == nil {
+		return nil, errNotTrained
+	}
+	middle := (m.Negative + 1) / 2
+	votes := make([]float64, len(m.classes))
+	confidence := make([]float64, len(m.classes))
+	for i, model := range m.models {
+		output, err := model.Predict(features)
+		if err != nil {
+			return nil, err
+		}
+		// positive when the model leans towards the larger class
+		d := (output[0] - middle) / (1 - middle)
+		a, b := m.pairs[i][0], m.pairs[i][1]
+		if d > 0 {
+			votes[b]++
+		} else {
+			votes[a]++
+		}
+		confidence[b] += d
+		confidence[a] -= d
+	}
+	for c := range votes {
+		votes[c] += confidence[c] / (3 * (math.Abs(confidence[c]) + 1))
+	}
+	return votes, nil
+}
+
+// Predict returns the class that wins the most pairs.
+func (m *OneVsOne) Predict(features []float64) ([]float64, error) {
+	scores, err := m.Scores(features)
+	if err != nil {
+		return nil, err
+	}
+	return best(m.classes, scores), nil
+}
+
+// Classes returns the sorted class labels of the training data.
+func (m *OneVsOne) Classes() []float64 {
+	return m.classes
+}
+
+// Models returns the binary model of every pair of classes.
+func (m *OneVsOne) Models() []mygoml.SupervisedModel {
+	return append([]mygoml.SupervisedModel(nil), m.models...)
+}
@@ -0,0 +1,117 @@
+package ensemble
+
+import (
+	"math/rand"
+	"mygoml"
+	"mygoml/logregres"
+	"testing"
+)
+
+// recorder remembers the targets it is trained on and predicts their mean.
+type recorder struct {
+	targets []float64
+}
+
+func (r *recorder) Train(dataset mygoml.SupervisedDataSet) error {
+	r.targets = nil
+	for _, dp := range dataset.DataPoints() {
+		r.targets = append(r.targets, dp.Target()[0])
+	}
+	return nil
+}
+
+func (r *recorder) Predict([]float64) ([]float64, error) {
+	sum := 0.0
+	for _, t := range r.targets {
+		sum = sum + t
+	}
+	return []float64{sum / float64(len(r.targets))}, nil
+}
+
+// count returns how many of targets equal 1 and how many equal negative.
+func count(targets []float64, negative float64) (positives, negatives int) {
+	for _, t := range targets {
+		switch t {
+		case 1:
+			positives++
+		case negative:
+			negatives++
+		}
+	}
+	return positives, negatives
+}
+
+func logistic() mygoml.SupervisedModel {
+	return &logregres.Model{}
+}
+
+func TestOneVsRest(t *testing.T) {
+	train := blobs(rand.New(rand.NewSource(1)), 40, 0.7)
+	test := blobs(rand.New(rand.NewSource(2)), 40, 0.7)
+
+	t.Run("targets", func(t *testing.T) {
+		m := &OneVsRest{New: func() mygoml.SupervisedModel { return &recorder{} }, Negative: -1}
+		if err := m.Train(train); err != nil {
+			t.Fatal(err)
+		}
+		mygoml.DeepEqual(t, "classes", []float64{0, 1, 2}, m.Classes())
+		for c, model := range m.Models() {
+			positives, negatives := count(model.(*recorder).targets, -1)
+			if positives != 40 || negatives != 80 {
+				t.Errorf("class %d: expected 40 positive and 80 negative targets, got %d and %d", c, positives, negatives)
+			}
+		}
+	})
+
+	m := &OneVsRest{New: logistic}
+	if err := m.Train(train); err != nil {
+		t.Fatal(err)
+	}
+	if a := mygoml.ModelAccuracy(t, m, test); a < 0.9 {
+		t.Errorf("expected an accuracy of at least 0.9, got %g", a)
+	}
+	scores, err := m.Scores([]float64{0, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scores[2] < scores[0] || scores[2] < scores[1] {
+		t.Errorf("expected class 2 to score highest, got %v", scores)
+	}
+}
+
+func TestOneVsOne(t *testing.T) {
+	train := blobs(rand.New(rand.NewSource(1)), 40, 0.7)
+	test := blobs(rand.New(rand.NewSource(2)), 40, 0.7)
+
+	t.Run("targets", func(t *testing.T) {
+		m := &OneVsOne{New: func() mygoml.SupervisedModel { return &recorder{} }, Negative: -1}
+		if err := m.Train(train); err != nil {
+			t.Fatal(err)
+		}
+		mygoml.DeepEqual(t, "pairs", [][2]int{{0, 1}, {0, 2}, {1, 2}}, m.pairs)
+		for i, model := range m.Models() {
+			positives, negatives := count(model.(*recorder).targets, -1)
+			if positives != 40 || negatives != 40 {
+				t.Errorf("pair %v: expected 40 positive and 40 negative targets, got %d and %d", m.pairs[i], positives, negatives)
+			}
+		}
+	})
+
+	m := &OneVsOne{New: logistic}
+	if err := m.Train(train); err != nil {
+		t.Fatal(err)
+	}
+	if a := mygoml.ModelAccuracy(t, m, test); a < 0.9 {
+		t.Errorf("expected an accuracy of at least 0.9, got %g", a)
+	}
+	// every class of the three beats the two others around its center
+	for class, x := range [][]float64{{0, 0}, {4, 0}, {0, 4}} {
+		scores, err := m.Scores(x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if scores[class] < 2-1.0/3 {
+			t.Errorf("expected class %d to win both of its pairs at %v, got %v", class, x, scores)
+		}
+	}
+}
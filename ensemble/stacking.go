@@ -0,0 +1,168 @@
+package ensemble
+
+import (
+	"mygoml"
+)
+
+// Stacking trains a final model on the predictions of the estimators. So
+// that the final model learns how the estimators do on data they have not
+// seen, its training data are out-of-fold predictions: the data set is
+// split in folds and every fold is predicted by estimators trained on the
+// others.
+type Stacking struct {
+	Estimators []Estimator
+	// Final is the model trained on the predictions of the estimators
+	Final Estimator
+	// Folds is the number of folds, 5 when less than 2
+	Folds int
+	// Proba feeds the final model with the class probabilities of the
+	// Probabilistic estimators instead of their class labels
+	Proba bool
+	// Passthrough also feeds the final model with the features
+	Passthrough bool
+	// Seed makes training reproducible, a time based seed is used when 0
+	Seed    int64
+	models  []mygoml.SupervisedModel
+	final   mygoml.SupervisedModel
+	classes []float64
+}
+
+// stacked is a data point of the final model.
+type stacked struct {
+	features []float64
+	target   []float64
+}
+
+func (s stacked) Features() []float64 {
+	return s.features
+}
+
+func (s stacked) Target() []float64 {
+	return s.target
+}
+
+// Train trains the final model on out-of-fold predictions, then every
+// estimator on the whole of dataset.
+func (m *Stacking) Train(dataset mygoml.SupervisedDataSet) error {
+	dps := dataset.DataPoints()
+	n := len(dps)
+	if n == 0 {
+		return mygoml.ErrDatasetEmpty
+	}
+	if len(m.Estimators) == 0 {
+		return mygoml.ErrIncompatibleDataAndModel("stacking needs at least one estimator")
+	}
+	folds := m.Folds
+	if folds < 2 {
+		folds = 5
+	}
+	if folds > n {
+		folds = n
+	}
+
+	m.models, m.final = nil, nil
+	m.classes = labelSet(dps)
+	fold := make([]int, n)
+	for p, i := range newRand(m.Seed).Perm(n) {
+		fold[i] = p % folds
+	}
+	meta := make(points, n)
+	for k := 0; k < folds; k++ {
+		var train, test points
+		var held []int
+		for i, dp := range dps {
+			if fold[i] == k {
+				test = append(test, dp)
+				held = append(held, i)
+			} else {
+				train = append(train, dp)
+			}
+		}
+		models, err := m.trainAll(train)
+		if err != nil {
+			return err
+		}
+		for j, dp := range test {
+			features, err := m.metaFeatures(models, dp.Features())
+			if err != nil {
+				return err
+			}
+			meta[held[j]] = stacked{features: features, target: dp.Target()}
+		}
+	}
+
+	final := m.Final.New()
+	if err := m.Final.train(final, meta); err != nil {
+		return err
+	}
+	models, err := m.trainAll(dataset)
+	if err != nil {
+		return err
+	}
+	m.models, m.final = models, final
+	return nil
+}
+
+// trainAll trains a new model of every estimator on dataset.
+func (m *Stacking) trainAll(dataset mygoml.SupervisedDataSet) ([]mygoml.SupervisedModel, error) {
+	models := make([]mygoml.SupervisedModel, len(m.Estimators))
+	for i, e := range m.Estimators {
+		models[i] = e.New()
+		if err := e.train(models[i], dataset); err != nil {
+			return nil, err
+		}
+	}
+	return models, nil
+}
+
+// metaFeatures returns the features of the final model: the class label or
+// probabilities predicted by every model, then the features themselves
+// with Passthrough.
+func (m *Stacking) metaFeatures(models []mygoml.SupervisedModel, features []float64) ([]float64, error) {
+	var out []float64
+	for i, model := range models {
+		if p, ok := model.(Probabilistic); m.Proba && ok {
+			proba, err := p.PredictProba(features)
+			if err != nil {
+				return nil, err
+			}
+			mapped := make([]float64, len(m.classes))
+			if err := spread(mapped, m.classes, p.Classes(), proba, 1); err != nil {
+				return nil, err
+			}
+			out = append(out, mapped...)
+			continue
+		}
+		label, err := m.Estimators[i].label(model, features)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, label)
+	}
+	if m.Passthrough {
+		out = append(out, features...)
+	}
+	return out, nil
+}
+
+// Predict returns the class label the final model decodes from the
+// predictions of the estimators.
+func (m *Stacking) Predict(features []float64) ([]float64, error) {
+	if m.final == nil {
+		return nil, errNotTrained
+	}
+	meta, err := m.metaFeatures(m.models, features)
+	if err != nil {
+		return nil, err
+	}
+	label, err := m.Final.label(m.final, meta)
+	if err != nil {
+		return nil, err
+	}
+	return []float64{label}, nil
+}
+
+// Classes returns the sorted class labels of the training data.
+func (m *Stacking) Classes() []float64 {
+	return m.classes
+}
@@ -0,0 +1,115 @@
+package ensemble
+
+import (
+	"fmt"
+	"mygoml"
+)
+
+// Voting trains every estimator on the whole data set and predicts the
+// class with the most votes or, when Soft is set, the highest averaged
+// probability.
+type Voting struct {
+	Estimators []Estimator
+	// Soft averages the class probabilities of the estimators, which must
+	// then all be Probabilistic
+	Soft bool
+	// Weights scales the vote of every estimator, equal when nil
+	Weights []float64
+	models  []mygoml.SupervisedModel
+	classes []float64
+}
+
+// Train trains a new model of every estimator on dataset.
+func (m *Voting) Train(dataset mygoml.SupervisedDataSet) error {
+	dps := dataset.DataPoints()
+	if len(dps) == 0 {
+		return mygoml.ErrDatasetEmpty
+	}
+	if len(m.Estimators) == 0 {
+		return mygoml.ErrIncompatibleDataAndModel("voting needs at least one estimator")
+	}
+	if m.Weights != nil && len(m.Weights) != len(m.Estimators) {
+		msg := fmt.Sprintf("model expects %d weights but got %d", len(m.Estimators), len(m.Weights))
+		return mygoml.ErrIncompatibleDataAndModel(msg)
+	}
+
+	m.models = nil
+	m.classes = labelSet(dps)
+	models := make([]mygoml.SupervisedModel, len(m.Estimators))
+	for i, e := range m.Estimators {
+		models[i] = e.New()
+		if _, ok := models[i].(Probabilistic); m.Soft && !ok {
+			msg := fmt.Sprintf("soft voting needs class probabilities but estimator %d has none", i)
+			return mygoml.ErrIncompatibleDataAndModel(msg)
+		}
+		if err := e.train(models[i], dataset); err != nil {
+			return err
+		}
+	}
+	m.models = models
+	return nil
+}
+
+func (m *Voting) weight(i int) float64 {
+	if m.Weights == nil {
+		return 1
+	}
+	return m.Weights[i]
+}
+
+// scores returns the weighted votes or probabilities of every class.
+func (m *Voting) scores(features []float64) ([]float64, error) {
+	if m.models == nil {
+		return nil, errNotTrained
+	}
+	scores := make([]float64, len(m.classes))
+	for i, model := range m.models {
+		if m.Soft {
+			p := model.(Probabilistic)
+			proba, err := p.PredictProba(features)
+			if err != nil {
+				return nil, err
+			}
+			if err := spread(scores, m.classes, p.Classes(), proba, m.weight(i)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		label, err := m.Estimators[i].label(model, features)
+		if err != nil {
+			return nil, err
+		}
+		c, err := classIndex(m.classes, label)
+		if err != nil {
+			return nil, err
+		}
+		scores[c] += m.weight(i)
+	}
+	return normalize(scores), nil
+}
+
+// Predict returns the class with the most votes, the smallest on ties.
+func (m *Voting) Predict(features []float64) ([]float64, error) {
+	scores, err := m.scores(features)
+	if err != nil {
+		return nil, err
+	}
+	return best(m.classes, scores), nil
+}
+
+// PredictProba returns the averaged class probabilities with soft voting
+// and the share of the votes of every class otherwise, in the order of
+// Classes.
+func (m *Voting) PredictProba(features []float64) ([]float64, error) {
+	return m.scores(features)
+}
+
+// Classes returns the sorted class labels of the training data.
+func (m *Voting) Classes() []float64 {
+	return m.classes
+}
+
+// Models returns the trained model of every estimator.
+func (m *Voting) Models() []mygoml.SupervisedModel {
+	return append([]mygoml.SupervisedModel(nil), m.models...)
+}
@@ -0,0 +1,95 @@
+package gmm
+
+import (
+	"fmt"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// CovarianceType constrains the covariance matrices of the components.
+type CovarianceType int
+
+const (
+	// Full gives every component its own covariance matrix
+	Full CovarianceType = iota
+	// Diagonal gives every component its own diagonal covariance matrix,
+	// the features being independent within a component
+	Diagonal
+	// Tied shares one covariance matrix between the components
+	Tied
+	// Spherical gives every component a single variance for all features
+	Spherical
+)
+
+func (c CovarianceType) String() string {
+	switch c {
+	case Full:
+		return "full"
+	case Diagonal:
+		return "diagonal"
+	case Tied:
+		return "tied"
+	case Spherical:
+		return "spherical"
+	}
+	return fmt.Sprintf("CovarianceType(%d)", int(c))
+}
+
+// parameters returns the number of free parameters of the covariance
+// matrices of k components over d features.
+func (c CovarianceType) parameters(k, d int) int {
+	switch c {
+	case Diagonal:
+		return k * d
+	case Tied:
+		return d * (d + 1) / 2
+	case Spherical:
+		return k
+	}
+	return k * d * (d + 1) / 2
+}
+
+// scatter returns the weighted scatter matrix of X around mean, divided by
+// total.
+func scatter(X [][]float64, weights, mean []float64, total float64) *mat.SymDense {
+	d := len(mean)
+	s := mat.NewSymDense(d, nil)
+	diff := make([]float64, d)
+	for i, x := range X {
+		if weights[i] == 0 {
+			continue
+		}
+		for j := range diff {
+			diff[j] = x[j] - mean[j]
+		}
+		s.SymRankOne(s, weights[i]/total, mat.NewVecDense(d, diff))
+	}
+	return s
+}
+
+// constrain keeps the part of a full covariance matrix allowed by c and
+// adds reg to its diagonal.
+func (c CovarianceType) constrain(s *mat.SymDense, reg float64) *mat.SymDense {
+	d := s.SymmetricDim()
+	switch c {
+	case Diagonal:
+		out := mat.NewSymDense(d, nil)
+		for j := 0; j < d; j++ {
+			out.SetSym(j, j, s.At(j, j))
+		}
+		s = out
+	case Spherical:
+		variance := 0.0
+		for j := 0; j < d; j++ {
+			variance = variance + s.At(j, j)/float64(d)
+		}
+		s = mat.NewSymDense(d, nil)
+		for j := 0; j < d; j++ {
+			s.SetSym(j, j, variance)
+		}
+	}
+	for j := 0; j < d; j++ {
+		s.SetSym(j, j, s.At(j, j)+reg)
+	}
+	return s
+}
@@ -0,0 +1,431 @@
+package gmm
+
+import (
+	"fmt"
+	"math"
+	"mygoml"
+	"mygoml/kmeans"
+	"time"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat/distmv"
+)
+
+// epsilon is the machine epsilon of float64.
+const epsilon = 2.220446049250313e-16
+
+// maxRegularization bounds the attempts to make a covariance matrix
+// positive definite, each adding ten times more to its variances.
+const maxRegularization = 20
+
+var errSingular = mygoml.ErrIncompatibleDataAndModel("a covariance matrix stays singular, the features may be NaN or infinite")
+
+type Cluster struct {
+	mygoml.BasicCluster
+	mean []float64
+}
+
+func (c Cluster) Mean() []float64 {
+	return c.mean
+}
+
+// Model is a mixture of Gaussian distributions fit with the expectation
+// maximization algorithm, starting from the clusters of kmeans. Every data
+// point belongs to each component with some probability, and Clustering
+// assigns it to the most probable one.
+type Model struct {
+	// Components is the number of Gaussian distributions, 1 when 0
+	Components int
+	Covariance CovarianceType
+	// MaxIter is the maximum number of EM iterations, 100 when 0
+	MaxIter int
+	// Tolerance stops EM when the mean log-likelihood of the data points
+	// improves by less, 1e-3 when 0
+	Tolerance float64
+	// RegCovar is added to the variances so that the covariance matrices
+	// stay positive definite, 1e-6 when 0
+	RegCovar float64
+	// Inits is the number of fits from different kmeans clusters, the one
+	// with the highest log-likelihood being kept, 1 when 0
+	Inits int
+	// Seed makes the initializations differ reproducibly, a time based
+	// seed is used when 0
+	Seed        int64
+	weights     []float64
+	means       [][]float64
+	covariances []*mat.SymDense
+	normals     []*distmv.Normal
+	converged   bool
+	iterations  int
+}
+
+// Clustering fits the mixture to ds and returns a cluster per component
+// with the data points most probably drawn from it, or nil when Fit fails.
+func (m *Model) Clustering(ds mygoml.UnsupervisedDataSet) []mygoml.Cluster {
+	if err := m.Fit(ds); err != nil {
+		return nil
+	}
+	dps := ds.DataPoints()
+	X := make([][]float64, len(dps))
+	for i, dp := range dps {
+		X[i] = dp.Features()
+	}
+
+	clusters := make([]*Cluster, len(m.weights))
+	for k := range clusters {
+		clusters[k] = &Cluster{mean: m.means[k]}
+	}
+	resp := make([]float64, len(m.weights))
+	for i, x := range X {
+		m.responsibilities(resp, x)
+		clusters[floats.MaxIdx(resp)].Add(dps[i])
+	}
+	var out []mygoml.Cluster
+	for _, c := range clusters {
+		out = append(out, c)
+	}
+	return out
+}
+
+// features is a data point that owns a copy of the features of another.
+type features []float64
+
+func (f features) Features() []float64 {
+	return f
+}
+
+// dataPoints is a data set of copied data points, which kmeans may write
+// to.
+type dataPoints []mygoml.UnsupervisedDataPoint
+
+func (d dataPoints) DataPoints() []mygoml.UnsupervisedDataPoint {
+	return d
+}
+
+// Fit fits the mixture to the data points of ds. It fails, leaving the
+// model untrained, when a covariance matrix cannot be made positive
+// definite, e.g. because of NaN features.
+func (m *Model) Fit(ds mygoml.UnsupervisedDataSet) error {
+	dps := ds.DataPoints()
+	if len(dps) == 0 {
+		return mygoml.ErrDatasetEmpty
+	}
+	X := make([][]float64, len(dps))
+	for i, dp := range dps {
+		X[i] = dp.Features()
+	}
+	if err := m.fit(X); err != nil {
+		m.weights, m.means, m.covariances, m.normals = nil, nil, nil, nil
+		return err
+	}
+	return nil
+}
+
+func (m *Model) fit(X [][]float64) error {
+	inits := m.Inits
+	if inits <= 0 {
+		inits = 1
+	}
+	seed := m.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rnd := rand.New(rand.NewSource(uint64(seed)))
+
+	var best Model
+	bestLL := math.Inf(-1)
+	for r := 0; r < inits; r++ {
+		// every initialization seeds kmeans from rnd, so that it starts
+		// from other centers and the fit only depends on Seed
+		copied := make(dataPoints, len(X))
+		for i, x := range X {
+			copied[i] = append(features(nil), x...)
+		}
+		ll, err := m.em(copied, X, rnd.Int63n(math.MaxInt64)+1)
+		if err != nil {
+			return err
+		}
+		if r == 0 || ll > bestLL {
+			best, bestLL = *m, ll
+		}
+	}
+	*m = best
+	return nil
+}
+
+// em fits the mixture from the kmeans clusters of ds, a copy of the data
+// points of X, found with seed and returns the mean log-likelihood of X.
+func (m *Model) em(ds mygoml.UnsupervisedDataSet, X [][]float64, seed int64) (float64, error) {
+	n := len(X)
+	components := m.Components
+	if components <= 0 {
+		components = 1
+	}
+	if components > n {
+		components = n
+	}
+	maxIter := m.MaxIter
+	if maxIter <= 0 {
+		maxIter = 100
+	}
+	tolerance := m.Tolerance
+	if tolerance <= 0 {
+		tolerance = 1e-3
+	}
+
+	// start from the hard assignments of kmeans
+	resp := make([][]float64, n)
+	for i := range resp {
+		resp[i] = make([]float64, components)
+	}
+	if components == 1 {
+		for i := range resp {
+			resp[i][0] = 1
+		}
+	} else {
+		km := kmeans.Model{ClusterCount: components, Seed: seed}
+		var centers [][]float64
+		for _, c := range km.Clustering(ds) {
+			centers = append(centers, c.(*kmeans.Cluster).Center())
+		}
+		for i, x := range X {
+			best := 0
+			for k, center := range centers {
+				if floats.Distance(x, center, 2) < floats.Distance(x, centers[best], 2) {
+					best = k
+				}
+			}
+			resp[i][best] = 1
+		}
+	}
+	if err := m.maximize(X, resp); err != nil {
+		return 0, err
+	}
+
+	m.converged = false
+	ll, previous := 0.0, math.Inf(-1)
+	for m.iterations = 1; m.iterations <= maxIter; m.iterations++ {
+		ll = 0
+		for i, x := range X {
+			ll = ll + m.responsibilities(resp[i], x)
+		}
+		if err := m.maximize(X, resp); err != nil {
+			return 0, err
+		}
+		ll = ll / float64(n)
+		if math.Abs(ll-previous) < tolerance {
+			m.converged = true
+			break
+		}
+		previous = ll
+	}
+	if m.iterations > maxIter {
+		m.iterations = maxIter
+	}
+	return ll, nil
+}
+
+// maximize sets the weights, means and covariance matrices of the
+// components from the responsibilities of every component for every data
+// point.
+func (m *Model) maximize(X [][]float64, resp [][]float64) error {
+	n, d, components := len(X), len(X[0]), len(resp[0])
+	reg := m.RegCovar
+	if reg <= 0 {
+		reg = 1e-6
+	}
+	m.weights = make([]float64, components)
+	m.means = make([][]float64, components)
+	m.covariances = make([]*mat.SymDense, components)
+	m.normals = make([]*distmv.Normal, components)
+
+	column := make([]float64, n)
+	tied := mat.NewSymDense(d, nil)
+	for k := 0; k < components; k++ {
+		// a little mass keeps empty components defined
+		nk := 10 * epsilon
+		mean := make([]float64, d)
+		for i, x := range X {
+			column[i] = resp[i][k]
+			nk = nk + resp[i][k]
+			floats.AddScaled(mean, resp[i][k], x)
+		}
+		floats.Scale(1/nk, mean)
+		m.weights[k] = nk / float64(n)
+		m.means[k] = mean
+
+		if m.Covariance == Tied {
+			tied.AddSym(tied, scatter(X, column, mean, float64(n)))
+			continue
+		}
+		m.covariances[k] = m.Covariance.constrain(scatter(X, column, mean, nk), reg)
+	}
+	if m.Covariance == Tied {
+		shared := m.Covariance.constrain(tied, reg)
+		for k := range m.covariances {
+			m.covariances[k] = shared
+		}
+	}
+
+	for k := range m.normals {
+		// keep adding to the variances of a degenerate component until it
+		// can be factorized, which NaN or infinite features never allow
+		extra := reg
+		for tries := 0; ; tries++ {
+			normal, ok := distmv.NewNormal(m.means[k], m.covariances[k], nil)
+			if ok {
+				m.normals[k] = normal
+				break
+			}
+			if tries == maxRegularization {
+				return errSingular
+			}
+			m.covariances[k] = Full.constrain(m.covariances[k], extra)
+			extra *= 10
+		}
+	}
+	return nil
+}
+
+// responsibilities sets resp to the probability of every component given
+// x and returns the log-likelihood of x.
+func (m *Model) responsibilities(resp []float64, x []float64) float64 {
+	for k, normal := range m.normals {
+		resp[k] = math.Log(m.weights[k]) + normal.LogProb(x)
+	}
+	ll := floats.LogSumExp(resp)
+	for k := range resp {
+		resp[k] = math.Exp(resp[k] - ll)
+	}
+	return ll
+}
+
+func (m *Model) check(features []float64) error {
+	if m.normals == nil {
+		return mygoml.ErrIncompatibleDataAndModel("model is not trained")
+	}
+	if len(features) != len(m.means[0]) {
+		msg := fmt.Sprintf("model expects %d features but got %d features", len(m.means[0]), len(features))
+		return mygoml.ErrIncompatibleDataAndModel(msg)
+	}
+	return nil
+}
+
+// Probabilities returns the probability that every component generated the
+// data point of features.
+func (m *Model) Probabilities(features []float64) ([]float64, error) {
+	if err := m.check(features); err != nil {
+		return nil, err
+	}
+	resp := make([]float64, len(m.normals))
+	m.responsibilities(resp, features)
+	return resp, nil
+}
+
+// LogLikelihood returns the log-likelihood of the data points of ds under
+// the mixture.
+func (m *Model) LogLikelihood(ds mygoml.UnsupervisedDataSet) (float64, error) {
+	dps := ds.DataPoints()
+	if len(dps) == 0 {
+		return 0, mygoml.ErrDatasetEmpty
+	}
+	ll := 0.0
+	resp := make([]float64, len(m.normals))
+	for _, dp := range dps {
+		if err := m.check(dp.Features()); err != nil {
+			return 0, err
+		}
+		ll = ll + m.responsibilities(resp, dp.Features())
+	}
+	return ll, nil
+}
+
+// parameters returns the number of free parameters of the mixture.
+func (m *Model) parameters() int {
+	k, d := len(m.means), len(m.means[0])
+	return k*d + m.Covariance.parameters(k, d) + k - 1
+}
+
+// BIC returns the Bayesian information criterion of the mixture on ds,
+// lower being better, which helps choose the number of components.
+func (m *Model) BIC(ds mygoml.UnsupervisedDataSet) (float64, error) {
+	ll, err := m.LogLikelihood(ds)
+	if err != nil {
+		return 0, err
+	}
+	return -2*ll + float64(m.parameters())*math.Log(float64(len(ds.DataPoints()))), nil
+}
+
+// AIC returns the Akaike information criterion of the mixture on ds, lower
+// being better. It penalizes components less than BIC.
+func (m *Model) AIC(ds mygoml.UnsupervisedDataSet) (float64, error) {
+	ll, err := m.LogLikelihood(ds)
+	if err != nil {
+		return 0, err
+	}
+	return -2*ll + 2*float64(m.parameters()), nil
+}
+
+// Sample draws n data points from the mixture and returns them with the
+// component that generated each. A time based source is used when src is
+// nil.
+func (m *Model) Sample(n int, src rand.Source) ([][]float64, []int, error) {
+	if m.normals == nil {
+		return nil, nil, mygoml.ErrIncompatibleDataAndModel("model is not trained")
+	}
+	if src == nil {
+		src = rand.NewSource(uint64(time.Now().UnixNano()))
+	}
+	rnd := rand.New(src)
+	normals := make([]*distmv.Normal, len(m.means))
+	for k := range normals {
+		normals[k], _ = distmv.NewNormal(m.means[k], m.covariances[k], src)
+	}
+	cumulative := make([]float64, len(m.weights))
+	floats.CumSum(cumulative, m.weights)
+
+	X := make([][]float64, n)
+	components := make([]int, n)
+	for i := range X {
+		u := rnd.Float64() * cumulative[len(cumulative)-1]
+		k := 0
+		for k < len(cumulative)-1 && cumulative[k] < u {
+			k++
+		}
+		components[i] = k
+		X[i] = normals[k].Rand(nil)
+	}
+	return X, components, nil
+}
+
+// Weights returns the mixing weight of every component.
+func (m *Model) Weights() []float64 {
+	return m.weights
+}
+
+// Means returns the mean of every component.
+func (m *Model) Means() [][]float64 {
+	return m.means
+}
+
+// Covariances returns the covariance matrix of every component, the same
+// matrix for all of them with Tied.
+func (m *Model) Covariances() []mat.Symmetric {
+	out := make([]mat.Symmetric, len(m.covariances))
+	for k, c := range m.covariances {
+		out[k] = c
+	}
+	return out
+}
+
+// Converged reports whether the last fit stopped before MaxIter.
+func (m *Model) Converged() bool {
+	return m.converged
+}
+
+// Iterations returns the number of EM iterations of the last fit.
+func (m *Model) Iterations() int {
+	return m.iterations
+}
@@ -0,0 +1,242 @@
+package gmm
+
+import (
+	"math"
+	"math/rand"
+	"mygoml"
+	"testing"
+
+	exprand "golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distmv"
+)
+
+func TestClustering(t *testing.T) {
+	d := mygoml.Blobs(rand.New(rand.NewSource(1)), 50, 1, []float64{0, 0}, []float64{10, 10}).Unlabelled()
+	for _, covariance := range []CovarianceType{Full, Diagonal, Spherical, Tied} {
+		t.Run(covariance.String(), func(t *testing.T) {
+			m := &Model{Components: 2, Covariance: covariance, Seed: 1}
+			clusters := m.Clustering(d)
+			if !m.Converged() {
+				t.Error("expected EM to converge")
+			}
+			if len(clusters) != 2 {
+				t.Fatalf("expected 2 clusters, got %d", len(clusters))
+			}
+			for _, c := range clusters {
+				members := c.Members()
+				if len(members) != 50 {
+					t.Fatalf("expected 50 data points per cluster, got %d", len(members))
+				}
+				// every member lies on the side of its own blob
+				side := members[0].Features()[0] > 5
+				for _, p := range members {
+					if p.Features()[0] > 5 != side {
+						t.Fatalf("expected the blobs in separate clusters, got %v in the cluster of %v", p, members[0])
+					}
+				}
+				mean := c.(*Cluster).Mean()
+				center := 0.0
+				if side {
+					center = 10
+				}
+				if mean[0] < center-0.5 || mean[0] > center+0.5 || mean[1] < center-0.5 || mean[1] > center+0.5 {
+					t.Errorf("expected a mean near (%g, %g), got %v", center, center, mean)
+				}
+			}
+			mygoml.FloatEqual(t, "weights", 1, m.Weights()[0]+m.Weights()[1])
+		})
+	}
+}
+
+func TestSeed(t *testing.T) {
+	// overlapping blobs give every initialization a different optimum
+	d := mygoml.Blobs(rand.New(rand.NewSource(2)), 30, 1, []float64{0, 0}, []float64{2, 1}, []float64{1, 3}).Unlabelled()
+	fit := func() *Model {
+		m := &Model{Components: 3, Inits: 3, Seed: 7}
+		m.Clustering(d)
+		return m
+	}
+	a, b := fit(), fit()
+	mygoml.DeepEqual(t, "weights", a.Weights(), b.Weights())
+	mygoml.DeepEqual(t, "means", a.Means(), b.Means())
+	mygoml.DeepEqual(t, "iterations", a.Iterations(), b.Iterations())
+}
+
+// uneven draws 150 data points around (0, 0) and 50 around (8, 8).
+func uneven() mygoml.Samples {
+	rnd := rand.New(rand.NewSource(3))
+	d := mygoml.Blobs(rnd, 50, 1, []float64{0, 0}, []float64{8, 8}).Unlabelled()
+	return append(d, mygoml.Blobs(rnd, 100, 1, []float64{0, 0}).Unlabelled()...)
+}
+
+func TestProbabilities(t *testing.T) {
+	m := &Model{Components: 2, Seed: 1}
+	if _, err := m.Probabilities([]float64{0, 0}); err == nil {
+		t.Error("expected an error before training")
+	}
+	if err := m.Fit(uneven()); err != nil {
+		t.Fatal(err)
+	}
+	for _, x := range [][]float64{{0, 0}, {8, 8}} {
+		p, err := m.Probabilities(x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mygoml.FloatEqual(t, "sum", 1, p[0]+p[1])
+		// the blob centers belong to the component of the closest mean
+		k := 0
+		if floats.Distance(x, m.Means()[1], 2) < floats.Distance(x, m.Means()[0], 2) {
+			k = 1
+		}
+		if p[k] < 0.99 {
+			t.Errorf("expected component %d at %v, got %v", k, x, p)
+		}
+	}
+	if _, err := m.Probabilities([]float64{0}); err == nil {
+		t.Error("expected an error for the wrong number of features")
+	}
+}
+
+func TestLogLikelihood(t *testing.T) {
+	d := uneven()
+	m := &Model{Seed: 1}
+	if err := m.Fit(d); err != nil {
+		t.Fatal(err)
+	}
+	// a single full component is the normal distribution of the sample
+	// mean and covariance
+	X := make([][]float64, len(d))
+	for i, s := range d {
+		X[i] = s
+	}
+	mean := make([]float64, 2)
+	for _, x := range X {
+		floats.AddScaled(mean, 1/float64(len(X)), x)
+	}
+	if !floats.EqualApprox(mean, m.Means()[0], 1e-9) {
+		t.Errorf("expected the mean %v, got %v", mean, m.Means()[0])
+	}
+	cov := mat.NewSymDense(2, nil)
+	stat.CovarianceMatrix(cov, mat.NewDense(len(X), 2, flatten(X)), nil)
+	cov.ScaleSym(float64(len(X)-1)/float64(len(X)), cov)
+	cov.SetSym(0, 0, cov.At(0, 0)+1e-6)
+	cov.SetSym(1, 1, cov.At(1, 1)+1e-6)
+	normal, _ := distmv.NewNormal(mean, cov, nil)
+	expected := 0.0
+	for _, x := range X {
+		expected = expected + normal.LogProb(x)
+	}
+	ll, err := m.LogLikelihood(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(ll-expected) > 1e-6 {
+		t.Errorf("expected the log-likelihood %g, got %g", expected, ll)
+	}
+
+	p := float64(m.parameters())
+	bic, _ := m.BIC(d)
+	aic, _ := m.AIC(d)
+	mygoml.FloatEqual(t, "BIC", -2*ll+p*math.Log(float64(len(d))), bic)
+	mygoml.FloatEqual(t, "AIC", -2*ll+2*p, aic)
+	if _, err := m.LogLikelihood(mygoml.Samples{}); err != mygoml.ErrDatasetEmpty {
+		t.Errorf("expected %v, got %v", mygoml.ErrDatasetEmpty, err)
+	}
+}
+
+func flatten(X [][]float64) []float64 {
+	var out []float64
+	for _, x := range X {
+		out = append(out, x...)
+	}
+	return out
+}
+
+func TestParameters(t *testing.T) {
+	// 2 components over 3 features have 6 mean values and 1 free weight
+	for covariance, expected := range map[CovarianceType]int{
+		Full:      6 + 2*6 + 1,
+		Diagonal:  6 + 2*3 + 1,
+		Tied:      6 + 6 + 1,
+		Spherical: 6 + 2 + 1,
+	} {
+		m := &Model{Covariance: covariance, means: [][]float64{make([]float64, 3), make([]float64, 3)}}
+		if got := m.parameters(); got != expected {
+			t.Errorf("%v: expected %d parameters, got %d", covariance, expected, got)
+		}
+	}
+}
+
+func TestBIC(t *testing.T) {
+	d := uneven()
+	best, bestBIC := 0, math.Inf(1)
+	for k := 1; k <= 4; k++ {
+		m := &Model{Components: k, Inits: 2, Seed: 1}
+		if err := m.Fit(d); err != nil {
+			t.Fatal(err)
+		}
+		bic, err := m.BIC(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bic < bestBIC {
+			best, bestBIC = k, bic
+		}
+	}
+	if best != 2 {
+		t.Errorf("expected BIC to choose 2 components, got %d", best)
+	}
+}
+
+func TestSample(t *testing.T) {
+	m := &Model{Components: 2, Seed: 1}
+	if _, _, err := m.Sample(1, nil); err == nil {
+		t.Error("expected an error before training")
+	}
+	if err := m.Fit(uneven()); err != nil {
+		t.Fatal(err)
+	}
+	n := 20000
+	X, components, err := m.Sample(n, exprand.NewSource(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make([]float64, 2)
+	means := [][]float64{make([]float64, 2), make([]float64, 2)}
+	for i, x := range X {
+		counts[components[i]]++
+		floats.Add(means[components[i]], x)
+	}
+	for k, w := range m.Weights() {
+		if share := counts[k] / float64(n); math.Abs(share-w) > 0.01 {
+			t.Errorf("component %d: expected a share of %g, got %g", k, w, share)
+		}
+		floats.Scale(1/counts[k], means[k])
+		if !floats.EqualApprox(means[k], m.Means()[k], 0.05) {
+			t.Errorf("component %d: expected the mean %v, got %v", k, m.Means()[k], means[k])
+		}
+	}
+}
+
+func TestDegenerate(t *testing.T) {
+	d := append(uneven(), mygoml.Sample{math.NaN(), 0})
+	m := &Model{Components: 2, Seed: 1}
+	if err := m.Fit(d); err != errSingular {
+		t.Fatalf("expected %v, got %v", errSingular, err)
+	}
+	if clusters := m.Clustering(d); clusters != nil {
+		t.Errorf("expected no clusters, got %d", len(clusters))
+	}
+	if _, err := m.Probabilities([]float64{0, 0}); err == nil {
+		t.Error("expected an untrained model")
+	}
+
+	// identical data points only need more regularization
+	m = &Model{Components: 1}
+	if err := m.Fit(mygoml.Samples{{1, 1}, {1, 1}, {1, 1}}); err != nil {
+		t.Error(err)
+	}
+}
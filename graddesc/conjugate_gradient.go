@@ -0,0 +1,71 @@
+package graddesc
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// ConjugateGradientMinimizer is nonlinear conjugate gradient with the
+// Polak-Ribiere+ update, restarting from the steepest descent direction
+// every Restart steps (the input size by default) or when the direction
+// stops going downhill.
+type ConjugateGradientMinimizer struct {
+	Restart    int
+	MaxStep    int
+	Tolerance  float64
+	LineSearch LineSearch
+}
+
+func (m *ConjugateGradientMinimizer) Minimize(f Function, startPoint []float64) (Result, error) {
+	if err := f.validate(len(startPoint), true); err != nil {
+		return Result{}, err
+	}
+	maxStep, tolerance := minimizerDefaults(m.MaxStep, m.Tolerance)
+	ls := m.LineSearch.withDefaults(0.1)
+	restart := m.Restart
+	if restart <= 0 {
+		restart = len(startPoint)
+	}
+
+	x := make([]float64, len(startPoint))
+	copy(x, startPoint)
+	grad := f.Gradient(x)
+	direction := make([]float64, len(x))
+	floats.ScaleTo(direction, -1, grad)
+	result := Result{StopReason: MaxStepReached}
+	count := 0
+	for ; count < maxStep; count++ {
+		if converged(grad, tolerance) {
+			result.StopReason = Converged
+			break
+		}
+		step, next, nextGrad := ls.Search(f, x, grad, direction)
+		if step == 0 {
+			result.StopReason = LineSearchFailed
+			break
+		}
+
+		// beta = max(0, g1 * (g1 - g0) / (g0 * g0))
+		beta := 0.0
+		if (count+1)%restart != 0 {
+			diff := make([]float64, len(grad))
+			floats.SubTo(diff, nextGrad, grad)
+			beta = math.Max(0, floats.Dot(nextGrad, diff)/floats.Dot(grad, grad))
+		}
+		floats.Scale(beta, direction)
+		floats.Sub(direction, nextGrad)
+		if floats.Dot(direction, nextGrad) >= 0 {
+			floats.ScaleTo(direction, -1, nextGrad)
+		}
+		x, grad = next, nextGrad
+	}
+	if count == maxStep && converged(grad, tolerance) {
+		result.StopReason = Converged
+	}
+
+	result.X = x
+	result.Epochs = count
+	result.Loss = f.value(x)
+	return result, stopError(result.StopReason)
+}
@@ -0,0 +1,56 @@
+package graddesc
+
+import "math"
+
+type GradientCheck struct {
+	Analytic       []float64
+	Numeric        []float64
+	RelativeErrors []float64
+}
+
+func (c GradientCheck) MaxRelativeError() (float64, int) {
+	max, index := 0.0, -1
+	for i, e := range c.RelativeErrors {
+		if e > max || index < 0 {
+			max, index = e, i
+		}
+	}
+	return max, index
+}
+
+// CheckGradient compares f.Gradient at x with central finite differences
+// of f.Mapper, whose first output is taken as the value of f. Coordinates
+// are moved by step, 1e-5 when step is not positive. Errors are relative to
+// the larger of both derivatives, and absolute when both are below 1 so
+// that rounding noise around zero is not blown up.
+func CheckGradient(f Function, x []float64, step float64) (GradientCheck, error) {
+	if err := f.validate(len(x), true); err != nil {
+		return GradientCheck{}, err
+	}
+	if step <= 0 {
+		step = 1e-5
+	}
+	point := make([]float64, len(x))
+	copy(point, x)
+
+	check := GradientCheck{
+		Analytic:       f.Gradient(point),
+		Numeric:        make([]float64, len(x)),
+		RelativeErrors: make([]float64, len(x)),
+	}
+	for i := range point {
+		original := point[i]
+		point[i] = original + step
+		plus := f.value(point)
+		point[i] = original - step
+		minus := f.value(point)
+		point[i] = original
+
+		numeric := (plus - minus) / (2 * step)
+		analytic := check.Analytic[i]
+		check.Numeric[i] = numeric
+		scale := math.Max(1, math.Max(math.Abs(analytic), math.Abs(numeric)))
+		check.RelativeErrors[i] = math.Abs(analytic-numeric) / scale
+	}
+	return check, nil
+}
@@ -0,0 +1,49 @@
+package graddesc
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCheckGradient(t *testing.T) {
+	f := Function{
+		InputSize: 2,
+		Mapper: func(x []float64) []float64 {
+			return []float64{x[0]*x[0] + 10*math.Sin(x[1])}
+		},
+		Gradient: func(x []float64) []float64 {
+			return []float64{2 * x[0], 10 * math.Cos(x[1])}
+		},
+	}
+
+	t.Run("correct gradient", func(t *testing.T) {
+		check, err := CheckGradient(f, []float64{1.5, -0.3}, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e, i := check.MaxRelativeError(); e > 1e-7 {
+			t.Errorf("expected relative error below 1e-7, got %g at %d", e, i)
+		}
+	})
+
+	t.Run("wrong gradient", func(t *testing.T) {
+		wrong := f
+		wrong.Gradient = func(x []float64) []float64 {
+			return []float64{2 * x[0], 10 * math.Sin(x[1])}
+		}
+		check, err := CheckGradient(wrong, []float64{1.5, -0.3}, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e, i := check.MaxRelativeError(); e < 0.1 || i != 1 {
+			t.Errorf("expected a large relative error at 1, got %g at %d", e, i)
+		}
+	})
+
+	t.Run("input size mismatch", func(t *testing.T) {
+		_, err := CheckGradient(f, []float64{1.5}, 0)
+		if _, ok := err.(ErrInputSizeMismatch); !ok {
+			t.Errorf("expected ErrInputSizeMismatch, got %v", err)
+		}
+	})
+}
@@ -1,10 +1,13 @@
 package graddesc
 
 import (
+	"fmt"
+	"math"
 	"math/rand"
 	"time"
 
 	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
 )
 
 func init() {
@@ -15,14 +18,20 @@ type Function struct {
 	InputSize int
 	Mapper    func(x []float64) []float64
 	Gradient  func(x []float64) []float64
+	// Hessian is optional and only used by second-order minimizers
+	Hessian func(x []float64) *mat.SymDense
 }
 
-func (f Function) Run(x []float64) []float64 {
+func (f Function) Run(x []float64) ([]float64, error) {
 	if len(x) != f.InputSize {
-		panic("function input size mismatch")
+		msg := fmt.Sprintf("function expects %d inputs but got %d inputs", f.InputSize, len(x))
+		return nil, ErrInputSizeMismatch(msg)
+	}
+	if f.Mapper == nil {
+		return nil, ErrNoMapper
 	}
 	out := f.Mapper(x)
-	return out
+	return out, nil
 }
 
 func randomVector(size int) []float64 {
@@ -39,6 +48,20 @@ type Optimizer struct {
 	MaxStep       int
 	CheckInterval int
 	Updater       Updater
+	// ClipValue limits every gradient component to [-ClipValue, ClipValue]
+	ClipValue float64
+	// ClipNorm rescales gradients whose L2 norm is above ClipNorm
+	ClipNorm float64
+	// WeightDecay shrinks x by LearningRate*WeightDecay*x after every
+	// update, independently of the gradient
+	WeightDecay float64
+	// Async runs Hogwild style lock-free asynchronous SGD on Workers
+	// goroutines, which suits problems with sparse gradients. The functions
+	// of an epoch must then be safe to evaluate concurrently
+	Async bool
+	// Workers is the number of goroutines used when Async is set, one per
+	// CPU when it is 0
+	Workers int
 }
 
 type Updater interface {
@@ -46,39 +69,173 @@ type Updater interface {
 	Reset()
 }
 
-func (o *Optimizer) Optimize(startPoint []float64) []float64 {
-	// reset updater
-	o.Updater.Reset()
+// DivergenceError is reported when a gradient or a parameter stops being
+// a finite number.
+type DivergenceError struct {
+	Epoch    int
+	Index    int
+	Value    float64
+	Gradient bool
+}
+
+func (e DivergenceError) Error() string {
+	what := "parameter"
+	if e.Gradient {
+		what = "gradient of parameter"
+	}
+	return fmt.Sprintf("[diverged]: %s %d became %v at epoch %d", what, e.Index, e.Value, e.Epoch)
+}
+
+func firstInvalid(x []float64) int {
+	for i, v := range x {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (o *Optimizer) clip(grad []float64) {
+	if o.ClipValue > 0 {
+		for i, g := range grad {
+			grad[i] = math.Max(-o.ClipValue, math.Min(o.ClipValue, g))
+		}
+	}
+	if o.ClipNorm > 0 {
+		if norm := floats.Norm(grad, 2); norm > o.ClipNorm {
+			floats.Scale(o.ClipNorm/norm, grad)
+		}
+	}
+}
+
+// guard wraps the gradient of f with clipping and divergence detection,
+// keeping the first divergence found in diverged.
+func (o *Optimizer) guard(f Function, epoch int, diverged *error) Function {
+	gradient := f.Gradient
+	f.Gradient = func(x []float64) []float64 {
+		grad := gradient(x)
+		if i := firstInvalid(grad); i >= 0 && *diverged == nil {
+			*diverged = DivergenceError{Epoch: epoch, Index: i, Value: grad[i], Gradient: true}
+		}
+		o.clip(grad)
+		return grad
+	}
+	return f
+}
+
+// Optimize runs gradient descent from startPoint. When the optimization
+// diverges it stops early, the result holds the last finite point and the
+// error is a DivergenceError. Optimize keeps no state of its own between
+// runs, but the Updater and the EpochProvider do, so concurrent runs need
+// their own.
+func (o *Optimizer) Optimize(startPoint []float64) (Result, error) {
+	if o.EpochProvider == nil {
+		return Result{}, ErrNoEpochProvider
+	}
 
 	// add default values
-	if o.CheckInterval <= 0 {
-		o.CheckInterval = 1
+	checkInterval := o.CheckInterval
+	if checkInterval <= 0 {
+		checkInterval = 1
+	}
+	updater := o.Updater
+	if updater == nil {
+		updater = &BaseUpdater{}
 	}
 
 	// setup variables
 	count := 0
 	x := make([]float64, len(startPoint))
 	copy(x, startPoint)
-	output := o.EpochProvider.Funcs()[0]().Gradient(x)
-	zeros := make([]float64, len(output))
+	if o.Async {
+		return o.optimizeAsync(x)
+	}
+	updater.Reset()
+	last := make([]float64, len(x))
+	zeros := make([]float64, len(x))
+	result := Result{StopReason: MaxStepReached}
+	var creators []funcCreator
+	var funcs []Function
+	var err error
 	// do gradient descent loop
 	for count < o.MaxStep {
 		done := true
-		for _, loss := range o.EpochProvider.Funcs() {
-			grad := loss().Gradient(x)
-			if count%o.CheckInterval == 0 && floats.EqualApprox(grad, zeros, 0.0000001) {
+		creators = o.EpochProvider.Funcs()
+		funcs = make([]Function, len(creators))
+		if count == 0 && len(creators) == 0 {
+			return Result{}, ErrNoEpochProvider
+		}
+		for k, create := range creators {
+			// the first function of the run is validated as it comes
+			first := count == 0 && k == 0
+			funcs[k] = create()
+			if first {
+				if err := funcs[k].validate(len(x), false); err != nil {
+					return Result{}, err
+				}
+			}
+			f := o.guard(funcs[k], count, &err)
+			grad := f.Gradient(x)
+			if first && len(grad) != len(x) {
+				return Result{}, gradientSizeError(grad, x)
+			}
+			if err != nil {
+				break
+			}
+			if count%checkInterval == 0 && floats.EqualApprox(grad, zeros, 0.0000001) {
 				continue
 			}
-			o.Updater.Update(x, loss(), o.LearningRate)
+			copy(last, x)
+			updater.Update(x, f, o.LearningRate)
+			if o.WeightDecay > 0 {
+				floats.Scale(1-o.LearningRate*o.WeightDecay, x)
+			}
+			if i := firstInvalid(x); i >= 0 {
+				err = DivergenceError{Epoch: count, Index: i, Value: x[i]}
+			}
+			if err != nil {
+				copy(x, last)
+				break
+			}
 			o.EpochProvider.AfterUpdate(x)
 			done = false
 		}
+		if err != nil {
+			result.StopReason = Diverged
+			break
+		}
 		if done {
+			result.StopReason = Converged
 			break
 		}
 		o.EpochProvider.OnEpochEnd(x)
 		count = count + 1
 	}
-	o.Updater.Reset()
-	return x
+	updater.Reset()
+
+	result.X = x
+	result.Epochs = count
+	result.Loss = loss(x, creators, funcs)
+	return result, err
+}
+
+func gradientSizeError(grad, x []float64) error {
+	msg := fmt.Sprintf("gradient has %d components for %d inputs", len(grad), len(x))
+	return ErrInputSizeMismatch(msg)
+}
+
+// loss averages the value at x of the functions of the last epoch, creating
+// those the epoch stopped before. It is NaN when no epoch ran.
+func loss(x []float64, creators []funcCreator, funcs []Function) float64 {
+	if len(creators) == 0 {
+		return math.NaN()
+	}
+	sum := 0.0
+	for k, create := range creators {
+		if funcs[k].Gradient == nil {
+			funcs[k] = create()
+		}
+		sum = sum + funcs[k].value(x)
+	}
+	return sum / float64(len(creators))
 }
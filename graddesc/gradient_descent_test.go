@@ -0,0 +1,204 @@
+package graddesc
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// constantGradient is a linear function whose gradient is always grad.
+func constantGradient(grad ...float64) *BatchProvider {
+	p := BatchProvider(Function{
+		InputSize: len(grad),
+		Mapper: func(x []float64) []float64 {
+			return []float64{floats.Dot(grad, x)}
+		},
+		Gradient: func(x []float64) []float64 {
+			return append([]float64(nil), grad...)
+		},
+	})
+	return &p
+}
+
+func TestClipping(t *testing.T) {
+	norm := math.Sqrt(6*6 + 0.5*0.5 + 8*8)
+	tests := map[string]struct {
+		op       Optimizer
+		expected []float64
+	}{
+		"none":  {Optimizer{}, []float64{-6, 0.5, -8}},
+		"value": {Optimizer{ClipValue: 1}, []float64{-1, 0.5, -1}},
+		"norm":  {Optimizer{ClipNorm: 5}, []float64{-30 / norm, 2.5 / norm, -40 / norm}},
+		// value clipping comes first and gives a norm of 1.5 below 5
+		"both": {Optimizer{ClipValue: 1, ClipNorm: 5}, []float64{-1, 0.5, -1}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			op := test.op
+			op.EpochProvider = constantGradient(6, -0.5, 8)
+			op.LearningRate = 1
+			op.MaxStep = 1
+			result, err := op.Optimize([]float64{0, 0, 0})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !floats.EqualApprox(result.X, test.expected, 1e-12) {
+				t.Errorf("expected a step to %v, got %v", test.expected, result.X)
+			}
+		})
+	}
+}
+
+func TestWeightDecay(t *testing.T) {
+	op := Optimizer{EpochProvider: constantGradient(1), LearningRate: 0.5, MaxStep: 2, WeightDecay: 0.2}
+	result, err := op.Optimize([]float64{2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every step is x = (1 - 0.5*0.2) * (x - 0.5)
+	expected := 0.9 * (0.9*(2-0.5) - 0.5)
+	if math.Abs(result.X[0]-expected) > 1e-12 {
+		t.Errorf("expected %g, got %g", expected, result.X[0])
+	}
+}
+
+func TestDivergence(t *testing.T) {
+	t.Run("gradient", func(t *testing.T) {
+		// the gradient turns into NaN once x passes 2.5, which clipped
+		// steps of 0.5 reach at the sixth epoch
+		p := BatchProvider(Function{
+			InputSize: 1,
+			Gradient: func(x []float64) []float64 {
+				if x[0] > 2.5 {
+					return []float64{math.NaN()}
+				}
+				return []float64{-1}
+			},
+		})
+		op := Optimizer{EpochProvider: &p, LearningRate: 1, MaxStep: 10, ClipValue: 0.5}
+		result, err := op.Optimize([]float64{0})
+		expected := DivergenceError{Epoch: 6, Index: 0, Value: math.NaN(), Gradient: true}
+		if e, ok := err.(DivergenceError); !ok || e.Epoch != expected.Epoch || !e.Gradient || !math.IsNaN(e.Value) {
+			t.Fatalf("expected %v, got %v", expected, err)
+		}
+		if result.StopReason != Diverged || result.X[0] != 3 || result.Epochs != 6 {
+			t.Errorf("expected to stop at 3 after 6 epochs, got %v at %v after %d", result.StopReason, result.X, result.Epochs)
+		}
+	})
+
+	t.Run("parameter", func(t *testing.T) {
+		// steps far too long on x² overflow the parameter at the second
+		// update, and the result keeps the last finite point
+		p := BatchProvider(Function{
+			InputSize: 1,
+			Mapper:    func(x []float64) []float64 { return []float64{x[0] * x[0]} },
+			Gradient:  func(x []float64) []float64 { return []float64{2 * x[0]} },
+		})
+		op := Optimizer{EpochProvider: &p, LearningRate: 1e200, MaxStep: 10}
+		result, err := op.Optimize([]float64{1})
+		if e, ok := err.(DivergenceError); !ok || e.Epoch != 1 || e.Gradient || !math.IsInf(e.Value, 0) {
+			t.Fatalf("expected a parameter divergence at epoch 1, got %v", err)
+		}
+		if result.StopReason != Diverged || result.X[0] != 1-2e200 {
+			t.Errorf("expected to stop at %g, got %v at %v", 1-2e200, result.StopReason, result.X)
+		}
+	})
+}
+
+func TestOptimizerReuse(t *testing.T) {
+	// the gradient is NaN for negative x only, so the runs started on
+	// either side of 0 must not see each other's divergence
+	p := BatchProvider(Function{
+		InputSize: 1,
+		Gradient: func(x []float64) []float64 {
+			if x[0] < 0 {
+				return []float64{math.NaN()}
+			}
+			return []float64{x[0] - 4}
+		},
+	})
+	op := &Optimizer{EpochProvider: &p, LearningRate: 0.1, MaxStep: 200}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			start := float64(1 - 2*(i%2))
+			result, err := op.Optimize([]float64{start})
+			if start < 0 {
+				if _, ok := err.(DivergenceError); !ok {
+					t.Errorf("expected a divergence from %g, got %v", start, err)
+				}
+				return
+			}
+			if err != nil || math.Abs(result.X[0]-4) > 1e-3 {
+				t.Errorf("expected to reach 4 from %g, got %v and %v", start, result.X, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if op.Updater != nil || op.CheckInterval != 0 {
+		t.Error("expected Optimize to leave the optimizer unchanged")
+	}
+}
+
+// countingProvider is a mini-batch provider of size functions that counts
+// the calls to Funcs and the functions created, which Async creates
+// concurrently.
+type countingProvider struct {
+	MiniBatchProvider
+	epochs  int
+	created int32
+}
+
+func newCountingProvider(size int, mapper func(x []float64) []float64) *countingProvider {
+	p := &countingProvider{}
+	p.MiniBatchProvider = MiniBatchProvider{
+		BatchSize: 1,
+		TotalSize: size,
+		EpochGen: func(indices []int) Function {
+			atomic.AddInt32(&p.created, 1)
+			return Function{
+				InputSize: 1,
+				Mapper:    mapper,
+				Gradient:  func(x []float64) []float64 { return []float64{1} },
+			}
+		},
+	}
+	return p
+}
+
+func (p *countingProvider) Funcs() []funcCreator {
+	p.epochs++
+	return p.MiniBatchProvider.Funcs()
+}
+
+func TestOptimizeFuncs(t *testing.T) {
+	for _, async := range []bool{false, true} {
+		// every function of every epoch is created once, and the loss of
+		// the result reuses those of the last epoch
+		p := newCountingProvider(4, func(x []float64) []float64 { return []float64{x[0]} })
+		op := Optimizer{EpochProvider: p, LearningRate: 0.1, MaxStep: 3, Async: async, Workers: 2}
+		result, err := op.Optimize([]float64{0})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.epochs != 3 || p.created != 12 {
+			t.Errorf("async %v: expected 3 epochs of 4 functions, got %d calls to Funcs and %d functions", async, p.epochs, p.created)
+		}
+		if math.Abs(result.X[0]+1.2) > 1e-12 || math.Abs(result.Loss+1.2) > 1e-12 {
+			t.Errorf("async %v: expected -1.2 and its loss, got %v and %g", async, result.X, result.Loss)
+		}
+
+		// a Mapper without outputs gives no loss rather than a panic
+		p = newCountingProvider(4, func(x []float64) []float64 { return nil })
+		op.EpochProvider = p
+		result, err = op.Optimize([]float64{0})
+		if err != nil || !math.IsNaN(result.Loss) {
+			t.Errorf("async %v: expected a NaN loss, got %g and %v", async, result.Loss, err)
+		}
+	}
+}
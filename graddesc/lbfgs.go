@@ -0,0 +1,91 @@
+package graddesc
+
+import "gonum.org/v1/gonum/floats"
+
+const defaultLBFGSMemory = 10
+
+// LBFGSMinimizer approximates the inverse Hessian from the last Memory
+// steps and gradient changes.
+type LBFGSMinimizer struct {
+	Memory     int
+	MaxStep    int
+	Tolerance  float64
+	LineSearch LineSearch
+}
+
+func (m *LBFGSMinimizer) Minimize(f Function, startPoint []float64) (Result, error) {
+	if err := f.validate(len(startPoint), true); err != nil {
+		return Result{}, err
+	}
+	maxStep, tolerance := minimizerDefaults(m.MaxStep, m.Tolerance)
+	ls := m.LineSearch.withDefaults(0.9)
+	memory := m.Memory
+	if memory <= 0 {
+		memory = defaultLBFGSMemory
+	}
+
+	x := make([]float64, len(startPoint))
+	copy(x, startPoint)
+	grad := f.Gradient(x)
+	var ss, ys [][]float64
+	result := Result{StopReason: MaxStepReached}
+	count := 0
+	for ; count < maxStep; count++ {
+		if converged(grad, tolerance) {
+			result.StopReason = Converged
+			break
+		}
+		direction := twoLoop(grad, ss, ys)
+		step, next, nextGrad := ls.Search(f, x, grad, direction)
+		if step == 0 && len(ss) > 0 {
+			// the curvature pairs went stale, start again from the gradient
+			ss, ys = nil, nil
+			continue
+		}
+		if step == 0 {
+			result.StopReason = LineSearchFailed
+			break
+		}
+
+		s := make([]float64, len(x))
+		y := make([]float64, len(x))
+		floats.SubTo(s, next, x)
+		floats.SubTo(y, nextGrad, grad)
+		if floats.Dot(s, y) > 0 {
+			ss = append(ss, s)
+			ys = append(ys, y)
+			if len(ss) > memory {
+				ss, ys = ss[1:], ys[1:]
+			}
+		}
+		x, grad = next, nextGrad
+	}
+	if count == maxStep && converged(grad, tolerance) {
+		result.StopReason = Converged
+	}
+
+	result.X = x
+	result.Epochs = count
+	result.Loss = f.value(x)
+	return result, stopError(result.StopReason)
+}
+
+// twoLoop returns -H*grad for the L-BFGS inverse Hessian estimate H.
+func twoLoop(grad []float64, ss, ys [][]float64) []float64 {
+	q := make([]float64, len(grad))
+	copy(q, grad)
+	alphas := make([]float64, len(ss))
+	for i := len(ss) - 1; i >= 0; i-- {
+		alphas[i] = floats.Dot(ss[i], q) / floats.Dot(ys[i], ss[i])
+		floats.AddScaled(q, -alphas[i], ys[i])
+	}
+	if k := len(ss) - 1; k >= 0 {
+		floats.Scale(floats.Dot(ss[k], ys[k])/floats.Dot(ys[k], ys[k]), q)
+	}
+	for i := range ss {
+		beta := floats.Dot(ys[i], q) / floats.Dot(ys[i], ss[i])
+		floats.AddScaled(q, alphas[i]-beta, ss[i])
+	}
+	floats.Scale(-1, q)
+	return q
+}
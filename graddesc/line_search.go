@@ -0,0 +1,80 @@
+package graddesc
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// LineSearch looks for a step along a descent direction that satisfies the
+// Armijo sufficient decrease condition and, when C2 is positive, the strong
+// Wolfe curvature condition, which keeps the step near a minimum along the
+// direction as conjugate gradient needs. Zero values get sensible defaults,
+// and a negative C2 only checks the Armijo condition.
+type LineSearch struct {
+	InitialStep float64
+	C1          float64
+	C2          float64
+	MaxIter     int
+}
+
+func (ls LineSearch) withDefaults(c2 float64) LineSearch {
+	if ls.InitialStep <= 0 {
+		ls.InitialStep = 1
+	}
+	if ls.C1 <= 0 {
+		ls.C1 = 1e-4
+	}
+	if ls.C2 == 0 {
+		ls.C2 = c2
+	}
+	if ls.MaxIter <= 0 {
+		ls.MaxIter = 50
+	}
+	return ls
+}
+
+// Search returns the step, the new point and its gradient. A step of 0
+// means no acceptable point was found.
+func (ls LineSearch) Search(f Function, x, grad, direction []float64) (float64, []float64, []float64) {
+	ls = ls.withDefaults(0)
+	value := f.value(x)
+	slope := floats.Dot(grad, direction)
+	if slope >= 0 {
+		return 0, x, grad
+	}
+
+	next := make([]float64, len(x))
+	lo, hi := 0.0, -1.0
+	step := ls.InitialStep
+	for i := 0; i < ls.MaxIter; i++ {
+		floats.AddScaledTo(next, x, step, direction)
+		nextValue := f.value(next)
+		if nextValue > value+ls.C1*step*slope || math.IsNaN(nextValue) {
+			// too long
+			hi = step
+			step = (lo + hi) / 2
+			continue
+		}
+		nextGrad := f.Gradient(next)
+		curvature := floats.Dot(nextGrad, direction)
+		if ls.C2 > 0 && curvature < ls.C2*slope {
+			// too short
+			lo = step
+			if hi < 0 {
+				step = 2 * step
+			} else {
+				step = (lo + hi) / 2
+			}
+			continue
+		}
+		if ls.C2 > 0 && curvature > -ls.C2*slope {
+			// past the minimum along direction
+			hi = step
+			step = (lo + hi) / 2
+			continue
+		}
+		return step, next, nextGrad
+	}
+	return 0, x, grad
+}
@@ -0,0 +1,46 @@
+package graddesc
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+const (
+	defaultMinimizerMaxStep   = 100
+	defaultMinimizerTolerance = 1e-6
+)
+
+// Minimizer minimizes a single, deterministic Function, typically the
+// full-batch loss of a small convex problem. When it stops before the
+// gradient vanishes, the result holds the last point and the error is
+// ErrLineSearchFailed or ErrMaxStepReached.
+type Minimizer interface {
+	Minimize(f Function, startPoint []float64) (Result, error)
+}
+
+func minimizerDefaults(maxStep int, tolerance float64) (int, float64) {
+	if maxStep <= 0 {
+		maxStep = defaultMinimizerMaxStep
+	}
+	if tolerance <= 0 {
+		tolerance = defaultMinimizerTolerance
+	}
+	return maxStep, tolerance
+}
+
+// converged reports whether the largest gradient component is below tolerance.
+func converged(grad []float64, tolerance float64) bool {
+	return floats.Norm(grad, math.Inf(1)) <= tolerance
+}
+
+// stopError is the error a minimizer returns for the reason it stopped.
+func stopError(reason StopReason) error {
+	switch reason {
+	case LineSearchFailed:
+		return ErrLineSearchFailed
+	case MaxStepReached:
+		return ErrMaxStepReached
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package graddesc
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// rosenbrock is (1-x)² + 100(y-x²)², whose curved valley leads to its
+// minimum at (1, 1).
+var rosenbrock = Function{
+	InputSize: 2,
+	Mapper: func(x []float64) []float64 {
+		a, b := 1-x[0], x[1]-x[0]*x[0]
+		return []float64{a*a + 100*b*b}
+	},
+	Gradient: func(x []float64) []float64 {
+		b := x[1] - x[0]*x[0]
+		return []float64{-2*(1-x[0]) - 400*x[0]*b, 200 * b}
+	},
+	Hessian: func(x []float64) *mat.SymDense {
+		return mat.NewSymDense(2, []float64{
+			2 - 400*x[1] + 1200*x[0]*x[0], -400 * x[0],
+			-400 * x[0], 200,
+		})
+	},
+}
+
+// quadratic is x^t*A*x/2 - b^t*x for an ill-conditioned positive definite
+// A, whose minimum solves A*x = b.
+func quadratic() (Function, []float64) {
+	A := mat.NewSymDense(3, []float64{
+		10, 2, 0,
+		2, 5, 1,
+		0, 1, 0.5,
+	})
+	b := mat.NewVecDense(3, []float64{1, -2, 3})
+	var solution mat.VecDense
+	if err := solution.SolveVec(A, b); err != nil {
+		panic(err)
+	}
+	f := Function{
+		InputSize: 3,
+		Mapper: func(x []float64) []float64 {
+			v := mat.NewVecDense(3, x)
+			return []float64{mat.Inner(v, A, v)/2 - mat.Dot(b, v)}
+		},
+		Gradient: func(x []float64) []float64 {
+			var grad mat.VecDense
+			grad.MulVec(A, mat.NewVecDense(3, x))
+			grad.SubVec(&grad, b)
+			return grad.RawVector().Data
+		},
+		Hessian: func(x []float64) *mat.SymDense {
+			return mat.NewSymDense(3, append([]float64(nil), A.RawSymmetric().Data...))
+		},
+	}
+	return f, solution.RawVector().Data
+}
+
+func minimizers(ls LineSearch) map[string]Minimizer {
+	return map[string]Minimizer{
+		"newton": &NewtonMinimizer{MaxStep: 100, LineSearch: ls},
+		"lbfgs":  &LBFGSMinimizer{MaxStep: 1000, LineSearch: ls},
+		// without the curvature condition conjugate gradient is little
+		// better than gradient descent in the valley of rosenbrock
+		"conjugate gradient": &ConjugateGradientMinimizer{MaxStep: 20000, LineSearch: ls},
+	}
+}
+
+func TestMinimizers(t *testing.T) {
+	quad, solution := quadratic()
+	noHessian := rosenbrock
+	noHessian.Hessian = nil
+	problems := []struct {
+		name     string
+		f        Function
+		start    []float64
+		solution []float64
+	}{
+		{"rosenbrock", rosenbrock, []float64{-1.2, 1}, []float64{1, 1}},
+		{"rosenbrock numeric hessian", noHessian, []float64{-1.2, 1}, []float64{1, 1}},
+		{"quadratic", quad, []float64{0, 0, 0}, solution},
+	}
+	searches := map[string]LineSearch{
+		"wolfe":  {},
+		"armijo": {C2: -1},
+	}
+	for searchName, ls := range searches {
+		for name, minimizer := range minimizers(ls) {
+			for _, problem := range problems {
+				t.Run(searchName+"/"+name+"/"+problem.name, func(t *testing.T) {
+					result, err := minimizer.Minimize(problem.f, problem.start)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if result.StopReason != Converged {
+						t.Errorf("expected convergence, got %v", result.StopReason)
+					}
+					if !floats.EqualApprox(result.X, problem.solution, 1e-4) {
+						t.Errorf("expected %v, got %v after %d steps", problem.solution, result.X, result.Epochs)
+					}
+					if want := problem.f.Mapper(result.X)[0]; result.Loss != want {
+						t.Errorf("expected the loss %g at the result, got %g", want, result.Loss)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestMinimizerFailures(t *testing.T) {
+	// a gradient pointing the wrong way makes every search direction go
+	// uphill, so no step decreases the function
+	uphill := rosenbrock
+	uphill.Gradient = func(x []float64) []float64 {
+		return floats.ScaleTo(make([]float64, 2), -1, rosenbrock.Gradient(x))
+	}
+	for name, minimizer := range minimizers(LineSearch{}) {
+		t.Run(name, func(t *testing.T) {
+			result, err := minimizer.Minimize(uphill, []float64{-1.2, 1})
+			if err != ErrLineSearchFailed || result.StopReason != LineSearchFailed {
+				t.Errorf("expected a failed line search, got %v and %v", err, result.StopReason)
+			}
+			if !floats.Equal(result.X, []float64{-1.2, 1}) {
+				t.Errorf("expected the start point back, got %v", result.X)
+			}
+		})
+	}
+
+	short := map[string]Minimizer{
+		"newton":             &NewtonMinimizer{MaxStep: 2},
+		"lbfgs":              &LBFGSMinimizer{MaxStep: 2},
+		"conjugate gradient": &ConjugateGradientMinimizer{MaxStep: 2},
+	}
+	for name, minimizer := range short {
+		t.Run(name+" max step", func(t *testing.T) {
+			result, err := minimizer.Minimize(rosenbrock, []float64{-1.2, 1})
+			if err != ErrMaxStepReached || result.StopReason != MaxStepReached || result.Epochs != 2 {
+				t.Errorf("expected to stop after 2 steps, got %v and %v after %d", err, result.StopReason, result.Epochs)
+			}
+			if start := rosenbrock.Mapper([]float64{-1.2, 1})[0]; !(result.Loss < start) || math.IsNaN(result.Loss) {
+				t.Errorf("expected the last point to improve on %g, got %g", start, result.Loss)
+			}
+		})
+	}
+}
+
+func TestLineSearch(t *testing.T) {
+	f, _ := quadratic()
+	x := []float64{2, 2, 2}
+	grad := f.Gradient(x)
+	direction := floats.ScaleTo(make([]float64, 3), -1, grad)
+	value, slope := f.Mapper(x)[0], floats.Dot(grad, direction)
+
+	for name, ls := range map[string]LineSearch{
+		"armijo": {C1: 1e-4},
+		"wolfe":  {C1: 1e-4, C2: 0.9},
+	} {
+		t.Run(name, func(t *testing.T) {
+			step, next, nextGrad := ls.Search(f, x, grad, direction)
+			if step <= 0 {
+				t.Fatal("expected a step")
+			}
+			if f.Mapper(next)[0] > value+ls.C1*step*slope {
+				t.Errorf("expected a sufficient decrease at step %g", step)
+			}
+			if ls.C2 > 0 && math.Abs(floats.Dot(nextGrad, direction)) > -ls.C2*slope {
+				t.Errorf("expected the strong curvature condition at step %g", step)
+			}
+		})
+	}
+
+	// a tiny initial step satisfies the Armijo condition at once, while the
+	// curvature condition makes the Wolfe search grow it
+	tiny := LineSearch{InitialStep: 1e-6, C2: 0.9}
+	if step, _, _ := tiny.Search(f, x, grad, direction); step <= 1e-6 {
+		t.Errorf("expected the Wolfe search to grow a tiny step, got %g", step)
+	}
+	if step, _, _ := (LineSearch{InitialStep: 1e-6}).Search(f, x, grad, direction); step != 1e-6 {
+		t.Errorf("expected the Armijo search to accept a tiny step, got %g", step)
+	}
+	if step, _, _ := (LineSearch{}).Search(f, x, grad, grad); step != 0 {
+		t.Errorf("expected no step uphill, got %g", step)
+	}
+}
@@ -10,7 +10,7 @@ type MomentumUpdater struct {
 
 func (u *MomentumUpdater) Update(x []float64, f Function, learningRate float64) {
 	grad := f.Gradient(x)
-	if u.currentVelocity == nil {
+	if len(u.currentVelocity) != len(x) {
 		u.currentVelocity = make([]float64, len(x))
 	}
 	floats.Scale(learningRate, grad)
@@ -9,7 +9,7 @@ type NAGUpdater struct {
 }
 
 func (u *NAGUpdater) Update(x []float64, f Function, learningRate float64) {
-	if u.currentVelocity == nil {
+	if len(u.currentVelocity) != len(x) {
 		u.currentVelocity = make([]float64, len(x))
 	}
 	floats.Scale(u.Gamma, u.currentVelocity)
@@ -0,0 +1,115 @@
+package graddesc
+
+import (
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// NewtonMinimizer uses Function.Hessian, or finite differences of the
+// gradient when it is nil. An indefinite Hessian is damped towards
+// gradient descent until it becomes positive definite.
+type NewtonMinimizer struct {
+	MaxStep    int
+	Tolerance  float64
+	LineSearch LineSearch
+}
+
+func (m *NewtonMinimizer) Minimize(f Function, startPoint []float64) (Result, error) {
+	if err := f.validate(len(startPoint), true); err != nil {
+		return Result{}, err
+	}
+	maxStep, tolerance := minimizerDefaults(m.MaxStep, m.Tolerance)
+	ls := m.LineSearch.withDefaults(0.9)
+
+	x := make([]float64, len(startPoint))
+	copy(x, startPoint)
+	grad := f.Gradient(x)
+	result := Result{StopReason: MaxStepReached}
+	count := 0
+	for ; count < maxStep; count++ {
+		if converged(grad, tolerance) {
+			result.StopReason = Converged
+			break
+		}
+		var hessian *mat.SymDense
+		if f.Hessian != nil {
+			hessian = f.Hessian(x)
+		} else {
+			hessian = numericHessian(f, x)
+		}
+
+		direction := newtonDirection(hessian, grad)
+		step, next, nextGrad := ls.Search(f, x, grad, direction)
+		if step == 0 {
+			result.StopReason = LineSearchFailed
+			break
+		}
+		x, grad = next, nextGrad
+	}
+	if count == maxStep && converged(grad, tolerance) {
+		result.StopReason = Converged
+	}
+
+	result.X = x
+	result.Epochs = count
+	result.Loss = f.value(x)
+	return result, stopError(result.StopReason)
+}
+
+// newtonDirection solves (H + damping*I) * d = -grad.
+func newtonDirection(hessian *mat.SymDense, grad []float64) []float64 {
+	n := len(grad)
+	rhs := mat.NewVecDense(n, make([]float64, n))
+	rhs.ScaleVec(-1, mat.NewVecDense(n, grad))
+
+	damping := 0.0
+	damped := mat.NewSymDense(n, nil)
+	for tries := 0; tries < 60; tries++ {
+		damped.CopySym(hessian)
+		for i := 0; i < n; i++ {
+			damped.SetSym(i, i, damped.At(i, i)+damping)
+		}
+		var chol mat.Cholesky
+		if chol.Factorize(damped) {
+			var direction mat.VecDense
+			if err := chol.SolveVecTo(&direction, rhs); err == nil {
+				return direction.RawVector().Data
+			}
+		}
+		if damping == 0 {
+			damping = 1e-8
+		}
+		damping = damping * 10
+	}
+
+	direction := make([]float64, n)
+	floats.ScaleTo(direction, -1, grad)
+	return direction
+}
+
+func numericHessian(f Function, x []float64) *mat.SymDense {
+	const step = 1e-5
+	n := len(x)
+	point := make([]float64, n)
+	copy(point, x)
+	columns := make([][]float64, n)
+	for i := 0; i < n; i++ {
+		original := point[i]
+		point[i] = original + step
+		plus := f.Gradient(point)
+		point[i] = original - step
+		minus := f.Gradient(point)
+		point[i] = original
+		columns[i] = make([]float64, n)
+		floats.SubTo(columns[i], plus, minus)
+		floats.Scale(1/(2*step), columns[i])
+	}
+
+	hessian := mat.NewSymDense(n, nil)
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			hessian.SetSym(i, j, (columns[i][j]+columns[j][i])/2)
+		}
+	}
+	return hessian
+}
@@ -0,0 +1,191 @@
+package graddesc
+
+import (
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// Sharded splits indices into at most workers shards and returns a
+// function whose Mapper and Gradient evaluate gen on every shard in its own
+// goroutine. The shard results are averaged weighted by shard size, so gen
+// must return functions that average over their samples. A workers value
+// below 1 uses one worker per CPU.
+func Sharded(indices []int, workers int, gen func(indices []int) Function) Function {
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(indices) {
+		workers = len(indices)
+	}
+	if workers <= 1 {
+		return gen(indices)
+	}
+
+	shards := make([]Function, workers)
+	weights := make([]float64, workers)
+	for k := range shards {
+		start := k * len(indices) / workers
+		end := (k + 1) * len(indices) / workers
+		shards[k] = gen(indices[start:end:end])
+		weights[k] = float64(end-start) / float64(len(indices))
+	}
+
+	combine := func(eval func(f Function) func(x []float64) []float64) func(x []float64) []float64 {
+		if eval(shards[0]) == nil {
+			return nil
+		}
+		return func(x []float64) []float64 {
+			outputs := make([][]float64, len(shards))
+			var wg sync.WaitGroup
+			for k := range shards {
+				wg.Add(1)
+				go func(k int) {
+					defer wg.Done()
+					outputs[k] = eval(shards[k])(x)
+				}(k)
+			}
+			wg.Wait()
+			out := make([]float64, len(outputs[0]))
+			for k, output := range outputs {
+				floats.AddScaled(out, weights[k], output)
+			}
+			return out
+		}
+	}
+
+	f := shards[0]
+	f.Mapper = combine(func(f Function) func(x []float64) []float64 { return f.Mapper })
+	f.Gradient = combine(func(f Function) func(x []float64) []float64 { return f.Gradient })
+	f.Hessian = nil
+	if shards[0].Hessian != nil {
+		f.Hessian = gen(indices).Hessian
+	}
+	return f
+}
+
+// optimizeAsync is the Hogwild variant of Optimize: the functions of an
+// epoch are shared among o.Workers goroutines which read and update x
+// without locking. Every worker takes a plain gradient step on the
+// coordinates with a non zero gradient, so sparse gradients rarely touch the
+// same coordinates. WeightDecay only shrinks the coordinates being updated,
+// and neither the Updater nor AfterUpdate are used.
+func (o *Optimizer) optimizeAsync(x []float64) (Result, error) {
+	workers := o.Workers
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+
+	bits := make([]uint64, len(x))
+	for i, v := range x {
+		bits[i] = math.Float64bits(v)
+	}
+	last := make([]float64, len(x))
+	load := func(dst []float64) {
+		for i := range bits {
+			dst[i] = math.Float64frombits(atomic.LoadUint64(&bits[i]))
+		}
+	}
+
+	var mu sync.Mutex
+	var err error
+	result := Result{StopReason: MaxStepReached}
+	var creators []funcCreator
+	var funcs []Function
+	count := 0
+	for count < o.MaxStep {
+		creators = o.EpochProvider.Funcs()
+		funcs = make([]Function, len(creators))
+		if count == 0 {
+			// the first function of the run is validated before the
+			// workers start
+			if len(creators) == 0 {
+				return Result{}, ErrNoEpochProvider
+			}
+			funcs[0] = creators[0]()
+			if err := funcs[0].validate(len(x), false); err != nil {
+				return Result{}, err
+			}
+			if grad := funcs[0].Gradient(x); len(grad) != len(x) {
+				return Result{}, gradientSizeError(grad, x)
+			}
+		}
+
+		copy(last, x)
+		var moved int32
+		jobs := make(chan int)
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				local := make([]float64, len(x))
+				for k := range jobs {
+					// every function is created by the only worker that
+					// receives its index
+					if funcs[k].Gradient == nil {
+						funcs[k] = creators[k]()
+					}
+					load(local)
+					grad := funcs[k].Gradient(local)
+					if i := firstInvalid(grad); i >= 0 {
+						mu.Lock()
+						if err == nil {
+							err = DivergenceError{Epoch: count, Index: i, Value: grad[i], Gradient: true}
+						}
+						mu.Unlock()
+						continue
+					}
+					o.clip(grad)
+					for i, g := range grad {
+						if g == 0 {
+							continue
+						}
+						atomicAdd(&bits[i], -o.LearningRate*(g+o.WeightDecay*local[i]))
+						atomic.StoreInt32(&moved, 1)
+					}
+				}
+			}()
+		}
+		for k := range creators {
+			jobs <- k
+		}
+		close(jobs)
+		wg.Wait()
+
+		load(x)
+		if i := firstInvalid(x); i >= 0 && err == nil {
+			err = DivergenceError{Epoch: count, Index: i, Value: x[i]}
+		}
+		if err != nil {
+			copy(x, last)
+			result.StopReason = Diverged
+			break
+		}
+		if moved == 0 {
+			result.StopReason = Converged
+			break
+		}
+		o.EpochProvider.OnEpochEnd(x)
+		count = count + 1
+	}
+
+	result.X = x
+	result.Epochs = count
+	result.Loss = loss(x, creators, funcs)
+	return result, err
+}
+
+// atomicAdd adds delta to the float64 stored as bits in p.
+func atomicAdd(p *uint64, delta float64) {
+	for {
+		old := atomic.LoadUint64(p)
+		next := math.Float64bits(math.Float64frombits(old) + delta)
+		if atomic.CompareAndSwapUint64(p, old, next) {
+			return
+		}
+	}
+}
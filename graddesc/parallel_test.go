@@ -0,0 +1,143 @@
+package graddesc
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// sparseProblem is a least squares problem where every sample only depends
+// on two of the coordinates of the solution.
+type sparseProblem struct {
+	features [][2]int
+	values   [][2]float64
+	targets  []float64
+}
+
+func newSparseProblem(samples, size int) (sparseProblem, []float64) {
+	rnd := rand.New(rand.NewSource(1))
+	solution := make([]float64, size)
+	for i := range solution {
+		solution[i] = rnd.NormFloat64()
+	}
+	var p sparseProblem
+	for s := 0; s < samples; s++ {
+		f := [2]int{s % size, rnd.Intn(size)}
+		v := [2]float64{1 + rnd.Float64(), rnd.NormFloat64()}
+		p.features = append(p.features, f)
+		p.values = append(p.values, v)
+		p.targets = append(p.targets, v[0]*solution[f[0]]+v[1]*solution[f[1]])
+	}
+	return p, solution
+}
+
+func (p sparseProblem) gen(size int) func(indices []int) Function {
+	return func(indices []int) Function {
+		residual := func(w []float64, s int) float64 {
+			f, v := p.features[s], p.values[s]
+			return v[0]*w[f[0]] + v[1]*w[f[1]] - p.targets[s]
+		}
+		return Function{
+			InputSize: size,
+			Mapper: func(w []float64) []float64 {
+				sum := 0.0
+				for _, s := range indices {
+					r := residual(w, s)
+					sum += r * r / 2
+				}
+				return []float64{sum / float64(len(indices))}
+			},
+			Gradient: func(w []float64) []float64 {
+				grad := make([]float64, size)
+				for _, s := range indices {
+					r := residual(w, s) / float64(len(indices))
+					f, v := p.features[s], p.values[s]
+					grad[f[0]] += r * v[0]
+					grad[f[1]] += r * v[1]
+				}
+				return grad
+			},
+		}
+	}
+}
+
+func TestSharded(t *testing.T) {
+	const size = 20
+	p, _ := newSparseProblem(101, size)
+	indices := rand.Perm(101)
+	full := p.gen(size)(indices)
+	w := make([]float64, size)
+	for i := range w {
+		w[i] = float64(i) / 10
+	}
+	for _, workers := range []int{0, 1, 3, 8, 200} {
+		sharded := Sharded(indices, workers, p.gen(size))
+		if !floats.EqualApprox(sharded.Gradient(w), full.Gradient(w), 1e-12) {
+			t.Errorf("%d workers: sharded gradient differs from the full gradient", workers)
+		}
+		if !floats.EqualApprox(sharded.Mapper(w), full.Mapper(w), 1e-12) {
+			t.Errorf("%d workers: sharded loss differs from the full loss", workers)
+		}
+	}
+}
+
+func TestMiniBatchProviderWorkers(t *testing.T) {
+	const size = 10
+	p, solution := newSparseProblem(200, size)
+	op := Optimizer{
+		EpochProvider: &MiniBatchProvider{
+			BatchSize: 40,
+			TotalSize: 200,
+			Workers:   4,
+			EpochGen:  p.gen(size),
+		},
+		LearningRate: 0.5,
+		MaxStep:      2000,
+	}
+	result, err := op.Optimize(make([]float64, size))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := floats.Distance(result.X, solution, math.Inf(1)); d > 1e-3 {
+		t.Errorf("expected the solution within 1e-3, got distance %g", d)
+	}
+}
+
+func TestOptimizeAsync(t *testing.T) {
+	const size = 50
+	p, solution := newSparseProblem(1000, size)
+	gen := p.gen(size)
+	op := Optimizer{
+		EpochProvider: &StochasticProvider{
+			TotalSize: 1000,
+			EpochGen:  func(i int) Function { return gen([]int{i}) },
+		},
+		LearningRate: 0.05,
+		MaxStep:      200,
+		Async:        true,
+		Workers:      4,
+	}
+	result, err := op.Optimize(make([]float64, size))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := floats.Distance(result.X, solution, math.Inf(1)); d > 1e-2 {
+		t.Errorf("expected the solution within 1e-2, got distance %g", d)
+	}
+	if result.Loss > 1e-4 {
+		t.Errorf("expected a loss below 1e-4, got %g", result.Loss)
+	}
+
+	t.Run("divergence", func(t *testing.T) {
+		op.LearningRate = 100
+		result, err := op.Optimize(make([]float64, size))
+		if _, ok := err.(DivergenceError); !ok {
+			t.Fatalf("expected a DivergenceError, got %v", err)
+		}
+		if result.StopReason != Diverged || firstInvalid(result.X) >= 0 {
+			t.Errorf("expected a finite point after divergence, got %v", result.X)
+		}
+	})
+}
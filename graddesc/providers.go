@@ -1,6 +1,9 @@
 package graddesc
 
-import "math/rand"
+import (
+	"math/rand"
+	"sort"
+)
 
 type funcCreator = func() Function
 
@@ -19,27 +22,84 @@ func (p *BatchProvider) Funcs() []funcCreator {
 func (p *BatchProvider) OnEpochEnd([]float64)  {}
 func (p *BatchProvider) AfterUpdate([]float64) {}
 
+// MiniBatchProvider splits the TotalSize samples into batches of BatchSize
+// indices. The samples are shuffled again on every epoch unless NoShuffle is
+// set. When DropLast is set a final batch smaller than BatchSize is skipped
+// unless it is the only one, otherwise it is kept. When Labels holds a class
+// for every sample, each batch receives the classes in about the same
+// proportions as the whole data set. With Workers above 1 the gradient of
+// every batch is computed by Sharded on that many goroutines.
 type MiniBatchProvider struct {
 	BatchSize       int
 	TotalSize       int
+	NoShuffle       bool
+	DropLast        bool
+	Labels          []int
+	Workers         int
 	EpochGen        func(indices []int) Function
 	EpochEndFunc    func([]float64)
 	AfterUpdateFunc func([]float64)
 }
 
 func (p *MiniBatchProvider) Funcs() []funcCreator {
+	batchSize := p.BatchSize
+	if batchSize <= 0 {
+		batchSize = p.TotalSize
+	}
+	order := p.order()
 	var fs []funcCreator
-	var indices []int
-	for i := 0; i < p.TotalSize; i++ {
-		if (i > 0 && i%p.BatchSize == 0) || (i == p.TotalSize-1) {
-			fs = append(fs, func() Function { return p.EpochGen(indices) })
-			indices = nil
+	for start := 0; start < len(order); start = start + batchSize {
+		end := start + batchSize
+		if end > len(order) {
+			if p.DropLast && start > 0 {
+				break
+			}
+			end = len(order)
+		}
+		indices := order[start:end:end]
+		if p.Workers > 1 {
+			fs = append(fs, func() Function { return Sharded(indices, p.Workers, p.EpochGen) })
+			continue
 		}
-		indices = append(indices, i)
+		fs = append(fs, func() Function { return p.EpochGen(indices) })
 	}
 	return fs
 }
 
+// order returns the sample indices of an epoch in the order they are batched.
+func (p *MiniBatchProvider) order() []int {
+	var order []int
+	if p.NoShuffle {
+		order = make([]int, p.TotalSize)
+		for i := range order {
+			order[i] = i
+		}
+	} else {
+		order = rand.Perm(p.TotalSize)
+	}
+	if len(p.Labels) != p.TotalSize {
+		return order
+	}
+
+	// spread every class evenly over the epoch: the k-th of n samples of a
+	// class is placed at the relative position (k+0.5)/n
+	seen := make(map[int]int)
+	counts := make(map[int]int)
+	for _, label := range p.Labels {
+		counts[label]++
+	}
+	positions := make([]float64, len(order))
+	for _, i := range order {
+		label := p.Labels[i]
+		positions[i] = (float64(seen[label]) + 0.5) / float64(counts[label])
+		seen[label]++
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return positions[order[a]] < positions[order[b]]
+	})
+	return order
+}
+
 func (p *MiniBatchProvider) OnEpochEnd(x []float64) {
 	if p.EpochEndFunc != nil {
 		p.EpochEndFunc(x)
@@ -62,7 +122,7 @@ type StochasticProvider struct {
 func (p *StochasticProvider) Funcs() []funcCreator {
 	shuffles := rand.Perm(p.TotalSize)
 	var fs []funcCreator
-	for i, _ := range shuffles {
+	for _, i := range shuffles {
 		k := i
 		fs = append(fs, func() Function { return p.EpochGen(k) })
 	}
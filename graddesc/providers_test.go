@@ -0,0 +1,210 @@
+package graddesc
+
+import (
+	"math"
+	"testing"
+)
+
+// epochBatches collects the indices of every batch of one epoch of p.
+func epochBatches(p EpochProvider, record *[]int) [][]int {
+	var batches [][]int
+	for _, create := range p.Funcs() {
+		*record = nil
+		create()
+		batches = append(batches, *record)
+	}
+	return batches
+}
+
+func newRecordingProvider(total, batchSize int, record *[]int) *MiniBatchProvider {
+	return &MiniBatchProvider{
+		BatchSize: batchSize,
+		TotalSize: total,
+		EpochGen: func(indices []int) Function {
+			*record = append([]int(nil), indices...)
+			return Function{}
+		},
+	}
+}
+
+func TestMiniBatchProviderVisitsEachSampleOnce(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		total     int
+		batchSize int
+		noShuffle bool
+	}{
+		{"even", 100, 10, false},
+		{"remainder", 103, 10, false},
+		{"ordered", 103, 10, true},
+		{"single batch", 7, 10, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var record []int
+			p := newRecordingProvider(tc.total, tc.batchSize, &record)
+			p.NoShuffle = tc.noShuffle
+			for epoch := 0; epoch < 3; epoch++ {
+				batches := epochBatches(p, &record)
+				want := (tc.total + tc.batchSize - 1) / tc.batchSize
+				if len(batches) != want {
+					t.Fatalf("expected %d batches, got %d", want, len(batches))
+				}
+				visits := make([]int, tc.total)
+				for _, batch := range batches {
+					if len(batch) > tc.batchSize {
+						t.Errorf("batch of %d exceeds batch size %d", len(batch), tc.batchSize)
+					}
+					for _, i := range batch {
+						visits[i]++
+					}
+				}
+				for i, v := range visits {
+					if v != 1 {
+						t.Fatalf("sample %d visited %d times in epoch %d", i, v, epoch)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestMiniBatchProviderShuffles(t *testing.T) {
+	var record []int
+	p := newRecordingProvider(100, 10, &record)
+	first := epochBatches(p, &record)
+	second := epochBatches(p, &record)
+	same := true
+	for b := range first {
+		for k := range first[b] {
+			if first[b][k] != second[b][k] {
+				same = false
+			}
+		}
+	}
+	if same {
+		t.Error("expected different batches in consecutive epochs")
+	}
+
+	p.NoShuffle = true
+	for b, batch := range epochBatches(p, &record) {
+		for k, i := range batch {
+			if i != b*10+k {
+				t.Fatalf("expected sample %d at position %d of batch %d, got %d", b*10+k, k, b, i)
+			}
+		}
+	}
+}
+
+func TestMiniBatchProviderDropLast(t *testing.T) {
+	var record []int
+	p := newRecordingProvider(103, 10, &record)
+	p.DropLast = true
+	batches := epochBatches(p, &record)
+	if len(batches) != 10 {
+		t.Fatalf("expected 10 batches, got %d", len(batches))
+	}
+	seen := make(map[int]bool)
+	for _, batch := range batches {
+		if len(batch) != 10 {
+			t.Errorf("expected batches of 10, got %d", len(batch))
+		}
+		for _, i := range batch {
+			if seen[i] {
+				t.Fatalf("sample %d visited twice", i)
+			}
+			seen[i] = true
+		}
+	}
+}
+
+func TestMiniBatchProviderStratified(t *testing.T) {
+	// 80 samples of class 0 followed by 20 of class 1
+	labels := make([]int, 100)
+	for i := 80; i < len(labels); i++ {
+		labels[i] = 1
+	}
+	var record []int
+	p := newRecordingProvider(len(labels), 10, &record)
+	p.Labels = labels
+	for _, noShuffle := range []bool{false, true} {
+		p.NoShuffle = noShuffle
+		visits := make([]int, len(labels))
+		for _, batch := range epochBatches(p, &record) {
+			ones := 0
+			for _, i := range batch {
+				visits[i]++
+				ones += labels[i]
+			}
+			if ones != 2 {
+				t.Errorf("expected 2 samples of class 1 per batch, got %d", ones)
+			}
+		}
+		for i, v := range visits {
+			if v != 1 {
+				t.Fatalf("sample %d visited %d times", i, v)
+			}
+		}
+	}
+}
+
+func TestStochasticProviderVisitsEachSampleOnce(t *testing.T) {
+	var order []int
+	p := &StochasticProvider{
+		TotalSize: 50,
+		EpochGen: func(i int) Function {
+			order = append(order, i)
+			return Function{}
+		},
+	}
+	for _, create := range p.Funcs() {
+		create()
+	}
+	visits := make([]int, 50)
+	sorted := true
+	for k, i := range order {
+		visits[i]++
+		if k > 0 && i < order[k-1] {
+			sorted = false
+		}
+	}
+	for i, v := range visits {
+		if v != 1 {
+			t.Fatalf("sample %d visited %d times", i, v)
+		}
+	}
+	if sorted {
+		t.Error("expected the samples to be shuffled")
+	}
+}
+
+func TestMiniBatchProviderTrains(t *testing.T) {
+	// least squares fit of y = 3x through mini-batches
+	xs := make([]float64, 64)
+	for i := range xs {
+		xs[i] = float64(i)/32 - 1
+	}
+	p := &MiniBatchProvider{
+		BatchSize: 16,
+		TotalSize: len(xs),
+		EpochGen: func(indices []int) Function {
+			return Function{
+				InputSize: 1,
+				Gradient: func(w []float64) []float64 {
+					g := 0.0
+					for _, i := range indices {
+						g += (w[0]*xs[i] - 3*xs[i]) * xs[i]
+					}
+					return []float64{g / float64(len(indices))}
+				},
+			}
+		},
+	}
+	op := Optimizer{EpochProvider: p, LearningRate: 0.5, MaxStep: 200}
+	result, err := op.Optimize([]float64{0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(result.X[0]-3) > 1e-6 {
+		t.Errorf("expected weight 3, got %v", result.X[0])
+	}
+}
@@ -0,0 +1,81 @@
+package graddesc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var ErrNoEpochProvider = errors.New("[no epoch provider]: optimizer has no epoch provider or it provides no function")
+var ErrNoGradient = errors.New("[no gradient]: function has no gradient")
+var ErrNoMapper = errors.New("[no mapper]: function has no mapper")
+var ErrLineSearchFailed = errors.New("[line search failed]: no step along the search direction decreased the function enough")
+var ErrMaxStepReached = errors.New("[max step reached]: the gradient did not vanish within the maximum number of steps")
+
+type ErrInputSizeMismatch string
+
+func (e ErrInputSizeMismatch) Error() string {
+	return "[input size mismatch]: " + string(e)
+}
+
+type StopReason int
+
+const (
+	MaxStepReached StopReason = iota
+	// Converged means the gradient vanished
+	Converged
+	// LineSearchFailed means no step along the search direction decreased
+	// the function enough
+	LineSearchFailed
+	Diverged
+)
+
+func (r StopReason) String() string {
+	switch r {
+	case MaxStepReached:
+		return "max step reached"
+	case Converged:
+		return "converged"
+	case LineSearchFailed:
+		return "line search failed"
+	case Diverged:
+		return "diverged"
+	}
+	return fmt.Sprintf("StopReason(%d)", int(r))
+}
+
+type Result struct {
+	X          []float64
+	Epochs     int
+	StopReason StopReason
+	// Loss is the first output of Mapper at X, averaged over the functions
+	// of the last epoch, or NaN when there is no Mapper, it returns nothing
+	// or no epoch ran
+	Loss float64
+}
+
+// validate checks that f can be evaluated at a point of the given size.
+func (f Function) validate(size int, needMapper bool) error {
+	if f.Gradient == nil {
+		return ErrNoGradient
+	}
+	if needMapper && f.Mapper == nil {
+		return ErrNoMapper
+	}
+	if size != f.InputSize {
+		msg := fmt.Sprintf("function expects %d inputs but got %d inputs", f.InputSize, size)
+		return ErrInputSizeMismatch(msg)
+	}
+	return nil
+}
+
+// value is the first output of the Mapper, NaN when there is none.
+func (f Function) value(x []float64) float64 {
+	if f.Mapper == nil {
+		return math.NaN()
+	}
+	if out := f.Mapper(x); len(out) > 0 {
+		return out[0]
+	}
+	return math.NaN()
+}
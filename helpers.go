@@ -2,6 +2,7 @@ package mygoml
 
 import (
 	"math"
+	"math/rand"
 	"reflect"
 	"testing"
 )
@@ -30,3 +31,92 @@ func FloatEqual(t *testing.T, name string, expected, got float64) {
 		t.Errorf("[%s] expected: %f, got %f", name, expected, got)
 	}
 }
+
+// Point is a supervised data point with a single target, for tests.
+type Point struct {
+	X []float64
+	Y float64
+}
+
+func (p Point) Features() []float64 { return p.X }
+func (p Point) Target() []float64   { return []float64{p.Y} }
+
+// Points is a supervised data set, for tests.
+type Points []Point
+
+func (d Points) DataPoints() []SupervisedDataPoint {
+	out := make([]SupervisedDataPoint, len(d))
+	for i, p := range d {
+		out[i] = p
+	}
+	return out
+}
+
+// Unlabelled returns the features of the points as an unsupervised data set.
+func (d Points) Unlabelled() Samples {
+	out := make(Samples, len(d))
+	for i, p := range d {
+		out[i] = p.X
+	}
+	return out
+}
+
+// Sample is an unsupervised data point, for tests.
+type Sample []float64
+
+func (s Sample) Features() []float64 { return s }
+
+// Samples is an unsupervised data set, for tests.
+type Samples []Sample
+
+func (d Samples) DataPoints() []UnsupervisedDataPoint {
+	out := make([]UnsupervisedDataPoint, len(d))
+	for i, s := range d {
+		out[i] = s
+	}
+	return out
+}
+
+// Blobs draws n points around every center, one around each center in
+// turn, with standard deviation sd and the index of their center as target.
+func Blobs(rnd *rand.Rand, n int, sd float64, centers ...[]float64) Points {
+	var d Points
+	for i := 0; i < n; i++ {
+		for class, c := range centers {
+			x := make([]float64, len(c))
+			for j := range c {
+				x[j] = c[j] + sd*rnd.NormFloat64()
+			}
+			d = append(d, Point{X: x, Y: float64(class)})
+		}
+	}
+	return d
+}
+
+// Grids returns a 3x3 grid of spacing 0.1 around (o, o) for every offset o.
+func Grids(offsets ...float64) Samples {
+	var d Samples
+	for _, offset := range offsets {
+		for i := 0; i < 9; i++ {
+			d = append(d, Sample{offset + float64(i%3)/10, offset + float64(i/3)/10})
+		}
+	}
+	return d
+}
+
+// ModelAccuracy returns the fraction of the points whose first predicted
+// component equals their target.
+func ModelAccuracy(t *testing.T, m SupervisedModel, d Points) float64 {
+	t.Helper()
+	correct := 0
+	for _, p := range d {
+		out, err := m.Predict(p.X)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out[0] == p.Y {
+			correct++
+		}
+	}
+	return float64(correct) / float64(len(d))
+}
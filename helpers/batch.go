@@ -0,0 +1,64 @@
+package helpers
+
+import (
+	"mygoml/graddesc"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Data sets with at least LargeDatasetSize points are trained with
+// mini-batches of DefaultBatchSize when no batch size is given.
+var LargeDatasetSize = 1000
+var DefaultBatchSize = 64
+
+// Rows gathers the given rows of m into a new matrix.
+func Rows(m mat.Matrix, indices []int) *mat.Dense {
+	_, c := m.Dims()
+	out := mat.NewDense(len(indices), c, nil)
+	if d, ok := m.(*mat.Dense); ok {
+		for i, r := range indices {
+			copy(out.RawRowView(i), d.RawRowView(r))
+		}
+		return out
+	}
+	for i, r := range indices {
+		for j := 0; j < c; j++ {
+			out.Set(i, j, m.At(r, j))
+		}
+	}
+	return out
+}
+
+// NewEpochProvider picks the epoch provider matching batchSize: 1 is
+// stochastic, totalSize or more is full batch and anything in between is
+// mini-batch. A batchSize of 0 chooses one from the size of the data set.
+func NewEpochProvider(totalSize, batchSize int, gen func(indices []int) graddesc.Function) graddesc.EpochProvider {
+	if batchSize <= 0 {
+		batchSize = 1
+		if totalSize >= LargeDatasetSize {
+			batchSize = DefaultBatchSize
+		}
+	}
+
+	switch {
+	case batchSize == 1:
+		return &graddesc.StochasticProvider{
+			TotalSize: totalSize,
+			EpochGen: func(i int) graddesc.Function {
+				return gen([]int{i})
+			},
+		}
+	case batchSize >= totalSize:
+		indices := make([]int, totalSize)
+		for i := range indices {
+			indices[i] = i
+		}
+		batchProvider := graddesc.BatchProvider(gen(indices))
+		return &batchProvider
+	}
+	return &graddesc.MiniBatchProvider{
+		BatchSize: batchSize,
+		TotalSize: totalSize,
+		EpochGen:  gen,
+	}
+}
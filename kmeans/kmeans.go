@@ -11,36 +11,26 @@ import (
 )
 
 type Cluster struct {
-	members []mygoml.UnsupervisedDataPoint
-	center  []float64
+	mygoml.BasicCluster
+	center []float64
 }
 
 func (kc Cluster) Center() []float64 {
 	return kc.center
 }
 
-func (kc *Cluster) Add(p mygoml.UnsupervisedDataPoint) {
-	kc.members = append(kc.members, p)
-}
-
-func (kc *Cluster) Reset() {
-	kc.members = nil
-}
-
-func (kc *Cluster) Members() []mygoml.UnsupervisedDataPoint {
-	var out []mygoml.UnsupervisedDataPoint
-	for _, v := range kc.members {
-		out = append(out, v)
-	}
-	return out
-}
-
 type Model struct {
 	ClusterCount int
+	// Seed picks the initial centers reproducibly, a time based seed is
+	// used when 0
+	Seed int64
 }
 
-func createRandomClusters(dps []mygoml.UnsupervisedDataPoint, clusterCount int) []*Cluster {
-	s := rand.NewSource(uint64(time.Now().Unix()))
+func createRandomClusters(dps []mygoml.UnsupervisedDataPoint, clusterCount int, seed int64) []*Cluster {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	s := rand.NewSource(uint64(seed))
 	gen := rand.New(s)
 	var clusters []*Cluster
 	chosen := gen.Perm(len(dps))[:clusterCount]
@@ -155,7 +145,7 @@ func resetClusters(clusters []*Cluster) {
 func (km *Model) Clustering(ds mygoml.UnsupervisedDataSet) []mygoml.Cluster {
 	dps := ds.DataPoints()
 	// init centers & create clusters
-	clusters := createRandomClusters(dps, km.ClusterCount)
+	clusters := createRandomClusters(dps, km.ClusterCount, km.Seed)
 
 	for {
 		// get old centers
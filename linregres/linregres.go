@@ -3,46 +3,126 @@ package linregres
 import (
 	"fmt"
 	"mygoml"
+	"mygoml/graddesc"
+	"mygoml/regularization"
 
 	"gonum.org/v1/gonum/mat"
 )
 
 type Model struct {
-	weights mat.Dense
+	Regularization regularization.Penalty
+	NoIntercept    bool
+	Solver         Solver
+	LearningRate   float64
+	MaxIter        int
+	Tolerance      float64
+	weights        mat.Dense
 }
 
-func (m *Model) Train(s mygoml.SupervisedDataSet) error {
-	dps := s.DataPoints()
-	if len(dps) == 0 {
-		return mygoml.ErrDatasetEmpty
+// Coefficients returns the learned feature weights, one column per target,
+// or nil when the model has not been trained.
+func (m *Model) Coefficients() mat.Matrix {
+	if m.weights.IsEmpty() {
+		return nil
+	}
+	r, c := m.weights.Dims()
+	return mat.DenseCopyOf(m.weights.Slice(0, r-1, 0, c))
+}
+
+// Intercept returns the learned bias of every target, or nil when the model
+// has not been trained. It is zero when NoIntercept is set.
+func (m *Model) Intercept() []float64 {
+	if m.weights.IsEmpty() {
+		return nil
 	}
+	r, _ := m.weights.Dims()
+	return mat.Row(nil, r-1, &m.weights)
+}
+
+func buildMatrices(dps []mygoml.SupervisedDataPoint) (*mat.Dense, *mat.Dense) {
 	featuresCount := len(dps[0].Features())
 	targetCount := len(dps[0].Target())
 
-	// build feature matrix (MxN) & target vector (Mx1 matrix)
+	// build feature matrix (MxN) & target matrix (MxT)
 	var featureMatrixData []float64
 	var targetMatrixData []float64
 	for _, dp := range dps {
 		featureMatrixData = append(featureMatrixData, dp.Features()...)
-		featureMatrixData = append(featureMatrixData, 1)
 		targetMatrixData = append(targetMatrixData, dp.Target()...)
 	}
-	featureMatrix := mat.NewDense(len(dps), featuresCount+1, featureMatrixData)
+	featureMatrix := mat.NewDense(len(dps), featuresCount, featureMatrixData)
 	targetMatrix := mat.NewDense(len(dps), targetCount, targetMatrixData)
-	var lhs mat.Dense
-	var rhs mat.Dense
-	lhs.Mul(featureMatrix.T(), featureMatrix)
-	rhs.Mul(featureMatrix.T(), targetMatrix)
-	err := m.weights.Solve(&lhs, &rhs)
-	if err != nil {
+	return featureMatrix, targetMatrix
+}
+
+// center subtracts the column means from m in place and returns them.
+func center(m *mat.Dense) []float64 {
+	r, c := m.Dims()
+	means := make([]float64, c)
+	for j := 0; j < c; j++ {
+		for i := 0; i < r; i++ {
+			means[j] = means[j] + m.At(i, j)
+		}
+		means[j] = means[j] / float64(r)
+		for i := 0; i < r; i++ {
+			m.Set(i, j, m.At(i, j)-means[j])
+		}
+	}
+	return means
+}
+
+func (m *Model) Train(s mygoml.SupervisedDataSet) error {
+	dps := s.DataPoints()
+	if len(dps) == 0 {
+		return mygoml.ErrDatasetEmpty
+	}
+	X, Y := buildMatrices(dps)
+	_, featuresCount := X.Dims()
+	_, targetCount := Y.Dims()
+
+	// fitting on centered data gives the same coefficients as adding
+	// a column of ones, and keeps the intercept out of the penalty
+	xMeans := make([]float64, featuresCount)
+	yMeans := make([]float64, targetCount)
+	if !m.NoIntercept {
+		xMeans = center(X)
+		yMeans = center(Y)
+	}
+
+	var coef mat.Dense
+	var err error
+	switch m.Regularization.Type {
+	case regularization.None, regularization.L2:
+		err = m.solveLeastSquares(&coef, X, Y, m.Regularization.L2Strength())
+	case regularization.L1, regularization.ElasticNet:
+		err = coordinateDescent(&coef, X, Y, m.Regularization, m.MaxIter, m.Tolerance)
+	default:
+		return mygoml.ErrUnknown
+	}
+	// a coordinate descent that did not converge still gives usable
+	// coefficients, like an ill-conditioned solve
+	if err != nil && err != mygoml.ErrNotConverged {
 		switch err.(type) {
 		case mat.Condition:
-			return mygoml.ErrMaybeInaccurate
+			err = mygoml.ErrMaybeInaccurate
+		case graddesc.DivergenceError:
+			return err
 		default:
 			return mygoml.ErrUnknown
 		}
 	}
-	return nil
+
+	// weights hold the coefficients with the intercept as the last row
+	m.weights = *mat.NewDense(featuresCount+1, targetCount, nil)
+	m.weights.Slice(0, featuresCount, 0, targetCount).(*mat.Dense).Copy(&coef)
+	for j := 0; j < targetCount; j++ {
+		intercept := yMeans[j]
+		for i := 0; i < featuresCount; i++ {
+			intercept = intercept - xMeans[i]*coef.At(i, j)
+		}
+		m.weights.Set(featuresCount, j, intercept)
+	}
+	return err
 }
 
 func (m *Model) Predict(features []float64) ([]float64, error) {
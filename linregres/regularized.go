@@ -0,0 +1,87 @@
+package linregres
+
+import (
+	"math"
+	"mygoml"
+	"mygoml/regularization"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+const (
+	defaultMaxIter   = 1000
+	defaultTolerance = 0.0001
+)
+
+// All regularized variants minimize
+//
+//	1/(2n) * |Y - XW|^2 + penalty(W)
+//
+// so alpha has the same meaning for Ridge, Lasso and Elastic Net.
+
+func softThreshold(x, t float64) float64 {
+	if x > t {
+		return x - t
+	} else if x < -t {
+		return x + t
+	}
+	return 0
+}
+
+// coordinateDescent fits Lasso / Elastic Net one target column at a time,
+// cycling over the features until no coefficient moves more than tol. It
+// returns mygoml.ErrNotConverged, with the last coefficients in coef, when
+// a column still moves after maxIter cycles.
+func coordinateDescent(coef *mat.Dense, X, Y *mat.Dense, p regularization.Penalty, maxIter int, tol float64) error {
+	if maxIter <= 0 {
+		maxIter = defaultMaxIter
+	}
+	if tol <= 0 {
+		tol = defaultTolerance
+	}
+	n, featuresCount := X.Dims()
+	_, targetCount := Y.Dims()
+	l1, l2 := p.L1Strength(), p.L2Strength()
+
+	columns := make([][]float64, featuresCount)
+	norms := make([]float64, featuresCount)
+	for k := range columns {
+		columns[k] = mat.Col(nil, k, X)
+		norms[k] = mat.Dot(mat.NewVecDense(n, columns[k]), mat.NewVecDense(n, columns[k])) / float64(n)
+	}
+
+	*coef = *mat.NewDense(featuresCount, targetCount, nil)
+	var err error
+	for j := 0; j < targetCount; j++ {
+		w := make([]float64, featuresCount)
+		residual := mat.Col(nil, j, Y)
+		converged := false
+		for iter := 0; iter < maxIter && !converged; iter++ {
+			maxDelta := 0.0
+			for k, col := range columns {
+				if norms[k] == 0 {
+					continue
+				}
+				rho := 0.0
+				for i, v := range col {
+					rho = rho + v*(residual[i]+v*w[k])
+				}
+				rho = rho / float64(n)
+				updated := softThreshold(rho, l1) / (norms[k] + l2)
+				if delta := updated - w[k]; delta != 0 {
+					for i, v := range col {
+						residual[i] = residual[i] - v*delta
+					}
+					maxDelta = math.Max(maxDelta, math.Abs(delta))
+					w[k] = updated
+				}
+			}
+			converged = maxDelta < tol
+		}
+		if !converged {
+			err = mygoml.ErrNotConverged
+		}
+		coef.SetCol(j, w)
+	}
+	return err
+}
@@ -0,0 +1,85 @@
+package linregres
+
+import (
+	"mygoml"
+	"mygoml/regularization"
+	"testing"
+)
+
+// orthogonal is y = 2 + 3*x1 + 0.5*x2 on centered orthogonal features of
+// unit variance, on which the Lasso coefficients are the least squares
+// ones shrunk by alpha and the Ridge ones are divided by 1 + alpha.
+func orthogonal() mygoml.Points {
+	x1 := []float64{1, -1, 1, -1}
+	x2 := []float64{1, 1, -1, -1}
+	var d mygoml.Points
+	for i := range x1 {
+		d = append(d, mygoml.Point{X: []float64{x1[i], x2[i]}, Y: 2 + 3*x1[i] + 0.5*x2[i]})
+	}
+	return d
+}
+
+func checkFit(t *testing.T, m *Model, coefficients []float64, intercept float64) {
+	t.Helper()
+	if r, _ := m.Coefficients().Dims(); r != len(coefficients) {
+		t.Fatalf("expected %d coefficients, got %d", len(coefficients), r)
+	}
+	for i, c := range coefficients {
+		mygoml.FloatEqual(t, "coefficient", c, m.Coefficients().At(i, 0))
+	}
+	mygoml.FloatEqual(t, "intercept", intercept, m.Intercept()[0])
+}
+
+func TestRegularized(t *testing.T) {
+	tests := map[string]struct {
+		penalty      regularization.Penalty
+		coefficients []float64
+	}{
+		"ridge":       {regularization.Penalty{Type: regularization.L2, Alpha: 1}, []float64{1.5, 0.25}},
+		"lasso":       {regularization.Penalty{Type: regularization.L1, Alpha: 1}, []float64{2, 0}},
+		"lasso small": {regularization.Penalty{Type: regularization.L1, Alpha: 0.2}, []float64{2.8, 0.3}},
+		"lasso large": {regularization.Penalty{Type: regularization.L1, Alpha: 5}, []float64{0, 0}},
+		// the L1 half shrinks by 0.5 and the L2 half divides by 1.5
+		"elastic net": {regularization.Penalty{Type: regularization.ElasticNet, Alpha: 1, L1Ratio: 0.5}, []float64{5.0 / 3, 0}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := &Model{Regularization: test.penalty}
+			if err := m.Train(orthogonal()); err != nil {
+				t.Fatal(err)
+			}
+			checkFit(t, m, test.coefficients, 2)
+		})
+	}
+
+	// coefficients shrunk to zero are exactly zero, not just small
+	m := &Model{Regularization: regularization.Penalty{Type: regularization.L1, Alpha: 0.6}}
+	if err := m.Train(orthogonal()); err != nil {
+		t.Fatal(err)
+	}
+	if c := m.Coefficients().At(1, 0); c != 0 {
+		t.Errorf("expected the second coefficient to be exactly 0, got %g", c)
+	}
+}
+
+func TestCoordinateDescentNotConverged(t *testing.T) {
+	// correlated features make coordinate descent zigzag for many cycles
+	d := mygoml.Points{
+		{X: []float64{1, 1.1}, Y: 1},
+		{X: []float64{2, 1.9}, Y: 2},
+		{X: []float64{3, 3.05}, Y: 3.1},
+		{X: []float64{4, 3.9}, Y: 3.9},
+	}
+	m := &Model{Regularization: regularization.Penalty{Type: regularization.L1, Alpha: 0.001}, MaxIter: 2, Tolerance: 1e-12}
+	if err := m.Train(d); err != mygoml.ErrNotConverged {
+		t.Fatalf("expected %v, got %v", mygoml.ErrNotConverged, err)
+	}
+	if m.Coefficients() == nil {
+		t.Error("expected the coefficients of the last cycle")
+	}
+
+	m.MaxIter = 100000
+	if err := m.Train(d); err != nil {
+		t.Errorf("expected convergence with more cycles, got %v", err)
+	}
+}
@@ -0,0 +1,197 @@
+package linregres
+
+import (
+	"math"
+	"mygoml"
+	"mygoml/graddesc"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+type Solver int
+
+const (
+	// AutoSolver uses QR and switches to SVD when X is rank deficient.
+	AutoSolver Solver = iota
+	QRSolver
+	SVDSolver
+	CholeskySolver
+	GradientSolver
+)
+
+const (
+	defaultLearningRate = 0.1
+	// singular values below rcond * largest singular value are treated as zero
+	rcond = 1e-12
+)
+
+// solveLeastSquares minimizes 1/(2n) * |Y - XW|^2 + alpha/2 * |W|^2 with
+// the chosen solver. It never forms X^t*X except for CholeskySolver.
+func (m *Model) solveLeastSquares(coef *mat.Dense, X, Y *mat.Dense, alpha float64) error {
+	switch m.Solver {
+	case AutoSolver, QRSolver:
+		A, B := augment(X, Y, alpha)
+		if err := solveQR(coef, A, B); err != nil {
+			return solveSVD(coef, A, B)
+		}
+		return nil
+	case SVDSolver:
+		A, B := augment(X, Y, alpha)
+		return solveSVD(coef, A, B)
+	case CholeskySolver:
+		if err := solveCholesky(coef, X, Y, alpha); err != nil {
+			A, B := augment(X, Y, alpha)
+			return solveSVD(coef, A, B)
+		}
+		return nil
+	case GradientSolver:
+		return m.solveGradient(coef, X, Y, alpha)
+	}
+	return mygoml.ErrUnknown
+}
+
+// augment turns the ridge problem into an ordinary least squares one by
+// stacking sqrt(n*alpha)*I under X and zeros under Y.
+func augment(X, Y *mat.Dense, alpha float64) (*mat.Dense, *mat.Dense) {
+	if alpha == 0 {
+		return X, Y
+	}
+	n, c := X.Dims()
+	_, t := Y.Dims()
+	A := mat.NewDense(n+c, c, nil)
+	A.Slice(0, n, 0, c).(*mat.Dense).Copy(X)
+	for i := 0; i < c; i++ {
+		A.Set(n+i, i, math.Sqrt(float64(n)*alpha))
+	}
+	B := mat.NewDense(n+c, t, nil)
+	B.Slice(0, n, 0, t).(*mat.Dense).Copy(Y)
+	return A, B
+}
+
+func solveQR(coef *mat.Dense, X, Y *mat.Dense) error {
+	if r, c := X.Dims(); r < c {
+		return mat.Condition(math.Inf(1))
+	}
+	var qr mat.QR
+	qr.Factorize(X)
+	// SolveTo only fails on a condition number near 1/epsilon, but rounding
+	// leaves the R of a rank deficient X with a much smaller one
+	if cond := qr.Cond(); cond > 1/rcond {
+		return mat.Condition(cond)
+	}
+	return qr.SolveTo(coef, false, Y)
+}
+
+func solveSVD(coef *mat.Dense, X, Y *mat.Dense) error {
+	var svd mat.SVD
+	if ok := svd.Factorize(X, mat.SVDThin); !ok {
+		return mygoml.ErrUnknown
+	}
+	rank := svd.Rank(rcond)
+	if rank == 0 {
+		_, c := X.Dims()
+		_, t := Y.Dims()
+		*coef = *mat.NewDense(c, t, nil)
+		return nil
+	}
+	svd.SolveTo(coef, Y, rank)
+	return nil
+}
+
+func solveCholesky(coef *mat.Dense, X, Y *mat.Dense, alpha float64) error {
+	n, c := X.Dims()
+	lhs := mat.NewSymDense(c, nil)
+	lhs.SymOuterK(1, X.T())
+	for i := 0; i < c; i++ {
+		lhs.SetSym(i, i, lhs.At(i, i)+float64(n)*alpha)
+	}
+	var rhs mat.Dense
+	rhs.Mul(X.T(), Y)
+
+	var chol mat.Cholesky
+	if ok := chol.Factorize(lhs); !ok {
+		return mat.Condition(math.Inf(1))
+	}
+	return chol.SolveTo(coef, &rhs)
+}
+
+// solveGradient runs batch gradient descent on standardized features, which
+// only needs matrix-vector products and so scales to large data sets.
+func (m *Model) solveGradient(coef *mat.Dense, X, Y *mat.Dense, alpha float64) error {
+	n, c := X.Dims()
+	_, t := Y.Dims()
+
+	scales := make([]float64, c)
+	Xs := mat.DenseCopyOf(X)
+	for j := 0; j < c; j++ {
+		col := mat.Col(nil, j, X)
+		norm := math.Sqrt(mat.Dot(mat.NewVecDense(n, col), mat.NewVecDense(n, col)) / float64(n))
+		if norm == 0 {
+			norm = 1
+		}
+		scales[j] = norm
+		for i := 0; i < n; i++ {
+			Xs.Set(i, j, col[i]/norm)
+		}
+	}
+
+	lossFunc := graddesc.Function{
+		InputSize: c * t,
+		Mapper: func(w []float64) []float64 {
+			var residual mat.Dense
+			residual.Mul(Xs, mat.NewDense(c, t, w))
+			residual.Sub(&residual, Y)
+			norm := mat.Norm(&residual, 2)
+			loss := norm * norm / float64(2*n)
+			for i, v := range w {
+				// penalize the weights of the original, unscaled features
+				v = v / scales[i/t]
+				loss = loss + alpha/2*v*v
+			}
+			return []float64{loss}
+		},
+		Gradient: func(w []float64) []float64 {
+			W := mat.NewDense(c, t, w)
+			var residual mat.Dense
+			residual.Mul(Xs, W)
+			residual.Sub(&residual, Y)
+			var grad mat.Dense
+			grad.Mul(Xs.T(), &residual)
+			grad.Scale(1/float64(n), &grad)
+			for j := 0; j < c; j++ {
+				for k := 0; k < t; k++ {
+					grad.Set(j, k, grad.At(j, k)+alpha*W.At(j, k)/(scales[j]*scales[j]))
+				}
+			}
+			return grad.RawMatrix().Data
+		},
+	}
+
+	learningRate := m.LearningRate
+	if learningRate <= 0 {
+		learningRate = defaultLearningRate
+	}
+	maxStep := m.MaxIter
+	if maxStep <= 0 {
+		maxStep = defaultMaxIter
+	}
+	batchProvider := graddesc.BatchProvider(lossFunc)
+	op := graddesc.Optimizer{
+		EpochProvider: &batchProvider,
+		LearningRate:  learningRate,
+		MaxStep:       maxStep,
+		Updater:       &graddesc.BaseUpdater{},
+	}
+	result, err := op.Optimize(make([]float64, c*t))
+	if result.X == nil {
+		return err
+	}
+
+	*coef = *mat.NewDense(c, t, result.X)
+	for j := 0; j < c; j++ {
+		for k := 0; k < t; k++ {
+			coef.Set(j, k, coef.At(j, k)/scales[j])
+		}
+	}
+	return err
+}
@@ -0,0 +1,82 @@
+package linregres
+
+import (
+	"math"
+	"math/rand"
+	"mygoml"
+	"testing"
+)
+
+// linear returns noisy data points of y = 1 + 2*x1 - x2 + 0.5*x3. When
+// collinear is set, x3 is x1 + x2 and the least squares solution is not
+// unique.
+func linear(rnd *rand.Rand, n int, collinear bool) mygoml.Points {
+	var d mygoml.Points
+	for i := 0; i < n; i++ {
+		x1, x2, x3 := rnd.NormFloat64(), rnd.NormFloat64(), rnd.NormFloat64()
+		if collinear {
+			x3 = x1 + x2
+		}
+		y := 1 + 2*x1 - x2 + 0.5*x3 + 0.01*rnd.NormFloat64()
+		d = append(d, mygoml.Point{X: []float64{x1, x2, x3}, Y: y})
+	}
+	return d
+}
+
+func TestSolvers(t *testing.T) {
+	solvers := map[string]Solver{
+		"auto":     AutoSolver,
+		"qr":       QRSolver,
+		"svd":      SVDSolver,
+		"cholesky": CholeskySolver,
+		"gradient": GradientSolver,
+	}
+	for _, collinear := range []bool{false, true} {
+		d := linear(rand.New(rand.NewSource(1)), 50, collinear)
+		reference := &Model{Solver: SVDSolver}
+		if err := reference.Train(d); err != nil {
+			t.Fatal(err)
+		}
+		for name, solver := range solvers {
+			t.Run(name, func(t *testing.T) {
+				m := &Model{Solver: solver, MaxIter: 5000}
+				if err := m.Train(d); err != nil {
+					t.Fatal(err)
+				}
+				// every solver finds a least squares solution, and all but
+				// gradient descent on the standardized features find the
+				// minimum norm one when it is not unique
+				if !collinear || solver != GradientSolver {
+					for i := 0; i < 3; i++ {
+						if e := math.Abs(m.Coefficients().At(i, 0) - reference.Coefficients().At(i, 0)); e > 1e-4 {
+							t.Errorf("collinear %v: coefficient %d differs from SVD by %g", collinear, i, e)
+						}
+					}
+				}
+				for _, p := range d[:10] {
+					got, _ := m.Predict(p.X)
+					want, _ := reference.Predict(p.X)
+					if e := math.Abs(got[0] - want[0]); e > 1e-3 {
+						t.Errorf("collinear %v: prediction differs from SVD by %g", collinear, e)
+					}
+				}
+			})
+		}
+
+		w := make([]float64, 3)
+		for i := range w {
+			w[i] = reference.Coefficients().At(i, 0)
+		}
+		expected := []float64{2, -1, 0.5}
+		// with x3 = x1 + x2 only the sums of their coefficients are fixed
+		if collinear {
+			w = []float64{w[0] + w[2], w[1] + w[2]}
+			expected = []float64{2.5, -0.5}
+		}
+		for i, c := range expected {
+			if math.Abs(w[i]-c) > 0.01 {
+				t.Errorf("collinear %v: expected coefficient %d near %g, got %g", collinear, i, c, w[i])
+			}
+		}
+	}
+}
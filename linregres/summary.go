@@ -0,0 +1,235 @@
+package linregres
+
+import (
+	"fmt"
+	"math"
+	"mygoml"
+	"strings"
+
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// Summary holds the diagnostics of a fitted Model on a data set. Matrices
+// have one column per target and one row per feature, the intercept being
+// the last row. Standard errors assume an unregularized fit.
+type Summary struct {
+	Coefficients     mat.Matrix
+	StdErrors        mat.Matrix
+	TStatistics      mat.Matrix
+	PValues          mat.Matrix
+	Residuals        mat.Matrix
+	RSquared         []float64
+	AdjustedRSquared []float64
+	ResidualStdError []float64
+	DegreesOfFreedom int
+
+	intercept bool
+	// pseudo-inverse of D^t*D where D is the design matrix
+	covariance *mat.Dense
+}
+
+func (m *Model) Summary(s mygoml.SupervisedDataSet) (*Summary, error) {
+	dps := s.DataPoints()
+	if len(dps) == 0 {
+		return nil, mygoml.ErrDatasetEmpty
+	}
+	if r, _ := m.weights.Dims(); len(dps[0].Features()) != r-1 {
+		msg := fmt.Sprintf("model expects %d features but got %d features", r-1, len(dps[0].Features()))
+		return nil, mygoml.ErrIncompatibleDataAndModel(msg)
+	}
+	X, Y := buildMatrices(dps)
+	n, featuresCount := X.Dims()
+	_, targetCount := Y.Dims()
+
+	sm := &Summary{intercept: !m.NoIntercept}
+	D := sm.designMatrix(X)
+	_, designCount := D.Dims()
+
+	// residuals
+	var residuals mat.Dense
+	residuals.Mul(D, sm.designWeights(&m.weights))
+	residuals.Sub(Y, &residuals)
+	sm.Residuals = &residuals
+
+	// (D^t*D)^+ through the SVD of D, which also gives the rank
+	var svd mat.SVD
+	if ok := svd.Factorize(D, mat.SVDThin); !ok {
+		return nil, mygoml.ErrUnknown
+	}
+	rank := svd.Rank(rcond)
+	var V mat.Dense
+	svd.VTo(&V)
+	values := svd.Values(nil)
+	scaled := mat.NewDense(designCount, rank, nil)
+	for j := 0; j < rank; j++ {
+		for i := 0; i < designCount; i++ {
+			scaled.Set(i, j, V.At(i, j)/values[j])
+		}
+	}
+	sm.covariance = mat.NewDense(designCount, designCount, nil)
+	sm.covariance.Mul(scaled, scaled.T())
+
+	sm.DegreesOfFreedom = n - rank
+	if sm.DegreesOfFreedom <= 0 {
+		return nil, mygoml.ErrIncompatibleDataAndModel("not enough data points to estimate the variance")
+	}
+
+	coefficients := mat.DenseCopyOf(&m.weights)
+	stdErrors := mat.NewDense(featuresCount+1, targetCount, nil)
+	tStatistics := mat.NewDense(featuresCount+1, targetCount, nil)
+	pValues := mat.NewDense(featuresCount+1, targetCount, nil)
+	sm.RSquared = make([]float64, targetCount)
+	sm.AdjustedRSquared = make([]float64, targetCount)
+	sm.ResidualStdError = make([]float64, targetCount)
+	dist := distuv.StudentsT{Mu: 0, Sigma: 1, Nu: float64(sm.DegreesOfFreedom)}
+	for j := 0; j < targetCount; j++ {
+		y := mat.Col(nil, j, Y)
+		r := mat.Col(nil, j, &residuals)
+
+		// goodness of fit, uncentered when there is no intercept
+		var mean float64
+		if sm.intercept {
+			for _, v := range y {
+				mean = mean + v
+			}
+			mean = mean / float64(n)
+		}
+		var rss, tss float64
+		for i := range y {
+			rss = rss + r[i]*r[i]
+			tss = tss + (y[i]-mean)*(y[i]-mean)
+		}
+		offset := 0
+		if sm.intercept {
+			offset = 1
+		}
+		sigma2 := rss / float64(sm.DegreesOfFreedom)
+		sm.ResidualStdError[j] = math.Sqrt(sigma2)
+		sm.RSquared[j] = 1 - rss/tss
+		sm.AdjustedRSquared[j] = 1 - (1-sm.RSquared[j])*float64(n-offset)/float64(sm.DegreesOfFreedom)
+
+		// coefficient tests
+		for i := 0; i <= featuresCount; i++ {
+			if i == featuresCount && !sm.intercept {
+				stdErrors.Set(i, j, math.NaN())
+				tStatistics.Set(i, j, math.NaN())
+				pValues.Set(i, j, math.NaN())
+				continue
+			}
+			se := math.Sqrt(sigma2 * sm.covariance.At(i, i))
+			t := coefficients.At(i, j) / se
+			stdErrors.Set(i, j, se)
+			tStatistics.Set(i, j, t)
+			pValues.Set(i, j, 2*dist.Survival(math.Abs(t)))
+		}
+	}
+	sm.Coefficients = coefficients
+	sm.StdErrors = stdErrors
+	sm.TStatistics = tStatistics
+	sm.PValues = pValues
+	return sm, nil
+}
+
+// designMatrix appends the column of ones used by the intercept.
+func (sm *Summary) designMatrix(X *mat.Dense) *mat.Dense {
+	if !sm.intercept {
+		return X
+	}
+	n, c := X.Dims()
+	D := mat.NewDense(n, c+1, nil)
+	D.Slice(0, n, 0, c).(*mat.Dense).Copy(X)
+	for i := 0; i < n; i++ {
+		D.Set(i, c, 1)
+	}
+	return D
+}
+
+func (sm *Summary) designWeights(w *mat.Dense) mat.Matrix {
+	if sm.intercept {
+		return w
+	}
+	r, c := w.Dims()
+	return w.Slice(0, r-1, 0, c)
+}
+
+// CoefficientIntervals returns the lower and upper bounds of the confidence
+// intervals of the coefficients at the given level, e.g. 0.95.
+func (sm *Summary) CoefficientIntervals(level float64) (mat.Matrix, mat.Matrix) {
+	q := sm.quantile(level)
+	r, c := sm.Coefficients.Dims()
+	lower := mat.NewDense(r, c, nil)
+	upper := mat.NewDense(r, c, nil)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			v, se := sm.Coefficients.At(i, j), sm.StdErrors.At(i, j)
+			lower.Set(i, j, v-q*se)
+			upper.Set(i, j, v+q*se)
+		}
+	}
+	return lower, upper
+}
+
+// ConfidenceInterval bounds the mean response at features.
+func (sm *Summary) ConfidenceInterval(features []float64, level float64) ([]float64, []float64, error) {
+	return sm.interval(features, level, 0)
+}
+
+// PredictionInterval bounds a single new observation at features.
+func (sm *Summary) PredictionInterval(features []float64, level float64) ([]float64, []float64, error) {
+	return sm.interval(features, level, 1)
+}
+
+func (sm *Summary) interval(features []float64, level, extra float64) ([]float64, []float64, error) {
+	r, c := sm.Coefficients.Dims()
+	if len(features) != r-1 {
+		msg := fmt.Sprintf("model expects %d features but got %d features", r-1, len(features))
+		return nil, nil, mygoml.ErrIncompatibleDataAndModel(msg)
+	}
+	values := make([]float64, len(features), len(features)+1)
+	copy(values, features)
+	if sm.intercept {
+		values = append(values, 1)
+	}
+	d := mat.NewVecDense(len(values), values)
+	leverage := mat.Inner(d, sm.covariance, d)
+
+	q := sm.quantile(level)
+	lower := make([]float64, c)
+	upper := make([]float64, c)
+	for j := 0; j < c; j++ {
+		fit := sm.Coefficients.At(r-1, j)
+		for i, v := range features {
+			fit = fit + v*sm.Coefficients.At(i, j)
+		}
+		se := sm.ResidualStdError[j] * math.Sqrt(extra+leverage)
+		lower[j] = fit - q*se
+		upper[j] = fit + q*se
+	}
+	return lower, upper, nil
+}
+
+func (sm *Summary) quantile(level float64) float64 {
+	dist := distuv.StudentsT{Mu: 0, Sigma: 1, Nu: float64(sm.DegreesOfFreedom)}
+	return dist.Quantile((1 + level) / 2)
+}
+
+func (sm *Summary) String() string {
+	var b strings.Builder
+	r, c := sm.Coefficients.Dims()
+	for j := 0; j < c; j++ {
+		fmt.Fprintf(&b, "target %d\n", j)
+		fmt.Fprintf(&b, "%-12s %12s %12s %12s %12s\n", "", "estimate", "std error", "t value", "p value")
+		for i := 0; i < r; i++ {
+			name := fmt.Sprintf("x%d", i)
+			if i == r-1 {
+				name = "intercept"
+			}
+			fmt.Fprintf(&b, "%-12s %12.6g %12.6g %12.6g %12.6g\n", name,
+				sm.Coefficients.At(i, j), sm.StdErrors.At(i, j), sm.TStatistics.At(i, j), sm.PValues.At(i, j))
+		}
+		fmt.Fprintf(&b, "residual standard error: %.6g on %d degrees of freedom\n", sm.ResidualStdError[j], sm.DegreesOfFreedom)
+		fmt.Fprintf(&b, "R-squared: %.6g, adjusted R-squared: %.6g\n", sm.RSquared[j], sm.AdjustedRSquared[j])
+	}
+	return b.String()
+}
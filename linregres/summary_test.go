@@ -0,0 +1,115 @@
+package linregres
+
+import (
+	"math"
+	"mygoml"
+	"testing"
+)
+
+// cars is the stopping distance of cars against their speed, the cars data
+// set of R, whose lm(dist ~ speed) summary gives the expected values.
+func cars(duplicate bool) mygoml.Points {
+	speed := []float64{4, 4, 7, 7, 8, 9, 10, 10, 10, 11, 11, 12, 12, 12, 12, 13, 13, 13, 13, 14, 14, 14, 14, 15, 15,
+		15, 16, 16, 17, 17, 17, 18, 18, 18, 18, 19, 19, 19, 20, 20, 20, 20, 20, 22, 23, 24, 24, 24, 24, 25}
+	dist := []float64{2, 10, 4, 22, 16, 10, 18, 26, 34, 17, 28, 14, 20, 24, 28, 26, 34, 34, 46, 26, 36, 60, 80, 20, 26,
+		54, 32, 40, 32, 40, 50, 42, 56, 76, 84, 36, 46, 68, 32, 48, 52, 56, 64, 66, 54, 70, 92, 93, 120, 85}
+	var d mygoml.Points
+	for i := range speed {
+		features := []float64{speed[i]}
+		if duplicate {
+			features = append(features, speed[i])
+		}
+		d = append(d, mygoml.Point{X: features, Y: dist[i]})
+	}
+	return d
+}
+
+// near checks that got is within tol of expected, relative to expected.
+func near(t *testing.T, name string, expected, got, tol float64) {
+	t.Helper()
+	if math.Abs(got-expected) > tol*math.Abs(expected) {
+		t.Errorf("[%s] expected: %g, got %g", name, expected, got)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	m := &Model{}
+	d := cars(false)
+	if err := m.Train(d); err != nil {
+		t.Fatal(err)
+	}
+	sm, err := m.Summary(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name                           string
+		estimate, stdErr, tValue, pVal float64
+		lower, upper                   float64
+	}{
+		{"speed", 3.932409, 0.415513, 9.464, 1.49e-12, 3.096964, 4.767853},
+		{"intercept", -17.579095, 6.758440, -2.601, 0.0123, -31.167850, -3.990340},
+	}
+	lower, upper := sm.CoefficientIntervals(0.95)
+	for i, test := range tests {
+		near(t, test.name+" estimate", test.estimate, sm.Coefficients.At(i, 0), 1e-6)
+		near(t, test.name+" std error", test.stdErr, sm.StdErrors.At(i, 0), 1e-5)
+		near(t, test.name+" t value", test.tValue, sm.TStatistics.At(i, 0), 1e-3)
+		near(t, test.name+" p value", test.pVal, sm.PValues.At(i, 0), 1e-2)
+		near(t, test.name+" lower bound", test.lower, lower.At(i, 0), 1e-6)
+		near(t, test.name+" upper bound", test.upper, upper.At(i, 0), 1e-6)
+	}
+	near(t, "residual standard error", 15.38, sm.ResidualStdError[0], 1e-3)
+	near(t, "R-squared", 0.6511, sm.RSquared[0], 1e-4)
+	near(t, "adjusted R-squared", 0.6438, sm.AdjustedRSquared[0], 1e-4)
+	if sm.DegreesOfFreedom != 48 {
+		t.Errorf("expected 48 degrees of freedom, got %d", sm.DegreesOfFreedom)
+	}
+
+	// at the mean speed the fit is the mean distance and its standard
+	// error is sigma/sqrt(n), sigma*sqrt(1 + 1/n) for a new observation
+	q := sm.quantile(0.95)
+	sigma := sm.ResidualStdError[0]
+	for name, interval := range map[string]func([]float64, float64) ([]float64, []float64, error){
+		"confidence": sm.ConfidenceInterval,
+		"prediction": sm.PredictionInterval,
+	} {
+		low, high, err := interval([]float64{15.4}, 0.95)
+		if err != nil {
+			t.Fatal(err)
+		}
+		se := sigma / math.Sqrt(50)
+		if name == "prediction" {
+			se = sigma * math.Sqrt(1+1.0/50)
+		}
+		near(t, name+" lower bound", 42.98-q*se, low[0], 1e-9)
+		near(t, name+" upper bound", 42.98+q*se, high[0], 1e-9)
+	}
+	if _, _, err := sm.ConfidenceInterval([]float64{1, 2}, 0.95); err == nil {
+		t.Error("expected an error for the wrong number of features")
+	}
+}
+
+func TestSummaryRankDeficient(t *testing.T) {
+	// with speed twice in the design, the minimum norm solution and the
+	// pseudo-inverse split its coefficient and standard error in halves,
+	// and the rank, not the number of columns, sets the degrees of freedom
+	m := &Model{}
+	d := cars(true)
+	if err := m.Train(d); err != nil {
+		t.Fatal(err)
+	}
+	sm, err := m.Summary(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		near(t, "speed estimate", 3.932409/2, sm.Coefficients.At(i, 0), 1e-6)
+		near(t, "speed std error", 0.415513/2, sm.StdErrors.At(i, 0), 1e-5)
+		near(t, "speed t value", 9.464, sm.TStatistics.At(i, 0), 1e-3)
+	}
+	near(t, "intercept std error", 6.758440, sm.StdErrors.At(2, 0), 1e-5)
+	if sm.DegreesOfFreedom != 48 {
+		t.Errorf("expected 48 degrees of freedom, got %d", sm.DegreesOfFreedom)
+	}
+}
@@ -7,14 +7,25 @@ import (
 	"mygoml"
 	"mygoml/graddesc"
 	"mygoml/helpers"
-
-	"gonum.org/v1/gonum/floats"
+	"mygoml/regularization"
 
 	"gonum.org/v1/gonum/mat"
 )
 
 type Model struct {
-	weights mat.Matrix
+	Regularization regularization.Penalty
+	// ClassWeights scales the loss of negative (index 0) and positive
+	// (index 1) targets
+	ClassWeights []float64
+	// SampleWeights scales the loss of each data point, in dataset order
+	SampleWeights []float64
+	// BatchSize is the number of data points per update, 0 picks one
+	// from the size of the data set
+	BatchSize int
+	// Minimizer, when set, replaces gradient descent and minimizes the
+	// full-batch loss, e.g. with graddesc.LBFGSMinimizer
+	Minimizer graddesc.Minimizer
+	weights   mat.Matrix
 }
 
 func (m *Model) Weights() mat.Matrix {
@@ -39,68 +50,155 @@ func sigmod(x float64) float64 {
 	return 1 / (1 + math.Exp(-x))
 }
 
-func toFloatSlice(m mat.Matrix) []float64 {
-	var data []float64
-	mt := m.T()
-	r, c := mt.Dims()
-	for i := 0; i < r; i++ {
-		for j := 0; j < c; j++ {
-			data = append(data, mt.At(i, j))
+// classWeight interpolates between the class weights so that soft labels
+// are supported.
+func (m *Model) classWeight(y float64) float64 {
+	if m.ClassWeights == nil {
+		return 1
+	}
+	return (1-y)*m.ClassWeights[0] + y*m.ClassWeights[1]
+}
+
+// loss is the weighted cross-entropy over a batch plus the penalty,
+// Xb and Yb holding one data point per row and sw their sample weights.
+func (m *Model) loss(Xb, Yb *mat.Dense, sw []float64, W *mat.Dense) float64 {
+	wr, wc := W.Dims()
+	batchSize, _ := Xb.Dims()
+
+	var Z mat.Dense
+	Z.Mul(Xb, W)
+	sum := 0.0
+	for i := 0; i < batchSize; i++ {
+		for j := 0; j < wc; j++ {
+			z, y := Z.At(i, j), Yb.At(i, j)
+			// -y*log(sigmod(z)) - (1-y)*log(1-sigmod(z)) written as
+			// softplus(z) - y*z, which stays finite for large |z|
+			ce := math.Max(z, 0) + math.Log1p(math.Exp(-math.Abs(z))) - y*z
+			sum = sum + ce*sw[i]*m.classWeight(y)
 		}
 	}
+	return sum/float64(batchSize) + m.Regularization.Value(W.RawMatrix().Data[:(wr-1)*wc])
+}
+
+// gradient computes dL/dW over a batch, Xb and Yb holding one data point
+// per row and sw their sample weights.
+func (m *Model) gradient(Xb, Yb *mat.Dense, sw []float64, W *mat.Dense) []float64 {
+	wr, wc := W.Dims()
+	batchSize, _ := Xb.Dims()
+
+	// Z = Xb * W
+	var E mat.Dense
+	E.Mul(Xb, W)
+
+	// E = sigmod(Z) - Yb
+	E.Apply(func(i, j int, z float64) float64 {
+		y := Yb.At(i, j)
+		return (sigmod(z) - y) * sw[i] * m.classWeight(y)
+	}, &E)
+
+	// dL/dW = Xb^t * E / batchSize
+	grad := mat.NewDense(wr, wc, nil)
+	grad.Mul(Xb.T(), &E)
+	grad.Scale(1/float64(batchSize), grad)
+
+	// the last row of W holds the bias and is not penalized
+	data := grad.RawMatrix().Data
+	m.Regularization.AddGradient(data[:(wr-1)*wc], W.RawMatrix().Data[:(wr-1)*wc])
 	return data
 }
 
+// hessian computes d2L/dW2 over a batch, ordered like the flattened W. The
+// outputs are independent so it is block diagonal. Only the L2 part of the
+// penalty has curvature.
+func (m *Model) hessian(Xb, Yb *mat.Dense, sw []float64, W *mat.Dense) *mat.SymDense {
+	wr, wc := W.Dims()
+	batchSize, _ := Xb.Dims()
+
+	var Z mat.Dense
+	Z.Mul(Xb, W)
+	hessian := mat.NewSymDense(wr*wc, nil)
+	for i := 0; i < batchSize; i++ {
+		xi := Xb.RawRowView(i)
+		for k := 0; k < wc; k++ {
+			a := sigmod(Z.At(i, k))
+			c := sw[i] * m.classWeight(Yb.At(i, k)) * a * (1 - a) / float64(batchSize)
+			for j := 0; j < wr; j++ {
+				for l := j; l < wr; l++ {
+					p, q := j*wc+k, l*wc+k
+					hessian.SetSym(p, q, hessian.At(p, q)+c*xi[j]*xi[l])
+				}
+			}
+		}
+	}
+	l2 := m.Regularization.L2Strength()
+	for p := 0; p < (wr-1)*wc; p++ {
+		hessian.SetSym(p, p, hessian.At(p, p)+l2)
+	}
+	return hessian
+}
+
 func (m *Model) Train(dataset mygoml.SupervisedDataSet) error {
+	if len(dataset.DataPoints()) == 0 {
+		return mygoml.ErrDatasetEmpty
+	}
 	X, Y := helpers.ConvertSupervisedDataset(dataset, true)
 	wr, xcount := X.Dims()
 	wc, _ := Y.Dims()
+	if m.ClassWeights != nil && len(m.ClassWeights) != 2 {
+		msg := fmt.Sprintf("model expects 2 class weights but got %d", len(m.ClassWeights))
+		return mygoml.ErrIncompatibleDataAndModel(msg)
+	}
+	if m.SampleWeights != nil && len(m.SampleWeights) != xcount {
+		msg := fmt.Sprintf("model expects %d sample weights but got %d", xcount, len(m.SampleWeights))
+		return mygoml.ErrIncompatibleDataAndModel(msg)
+	}
 
 	var wData []float64
 	for i := 0; i < wr*wc; i++ {
 		wData = append(wData, rand.Float64()+0.01)
 	}
-	wMatrix := mat.NewDense(wr, wc, wData)
-	// s = Wt * X
-	// z = sigmod(s)
-
-	gradient := func(xi, yi []float64, w mat.Matrix) []float64 {
-		xivec := mat.NewVecDense(len(xi), copyFloats(xi))
-		var zivec mat.VecDense
-		zivec.MulVec(w.T(), xivec)
-		zival := mat.Col(nil, 0, &zivec)
-		wMatrix := mat.NewDense(wr, wc, nil)
-		for i, zv := range zival {
-			zi := 1 / (1 + math.Exp(-zv))
-			zi = zi - yi[i]
-			temp := make([]float64, len(xi))
-			floats.ScaleTo(temp, zi, xi)
-			wMatrix.SetCol(i, temp)
+
+	// keep one data point per row so that a batch is a set of rows
+	Xrows := mat.DenseCopyOf(X.T())
+	Yrows := mat.DenseCopyOf(Y.T())
+	gen := func(indices []int) graddesc.Function {
+		Xb := helpers.Rows(Xrows, indices)
+		Yb := helpers.Rows(Yrows, indices)
+		sw := make([]float64, len(indices))
+		for k, i := range indices {
+			sw[k] = 1
+			if m.SampleWeights != nil {
+				sw[k] = m.SampleWeights[i]
+			}
+		}
+
+		return graddesc.Function{
+			InputSize: wr * wc,
+			Mapper: func(w []float64) []float64 {
+				return []float64{m.loss(Xb, Yb, sw, mat.NewDense(wr, wc, w))}
+			},
+			Gradient: func(w []float64) []float64 {
+				return m.gradient(Xb, Yb, sw, mat.NewDense(wr, wc, w))
+			},
+			Hessian: func(w []float64) *mat.SymDense {
+				return m.hessian(Xb, Yb, sw, mat.NewDense(wr, wc, w))
+			},
 		}
-		return toFloatSlice(wMatrix)
 	}
 
-	epochProvider := &graddesc.StochasticProvider{
-		TotalSize: xcount,
-		EpochGen: func(i int) graddesc.Function {
-			xi := mat.Col(nil, i, X)
-			yi := mat.Col(nil, i, Y)
-
-			return graddesc.Function{
-				InputSize: wr * wc,
-				Gradient: func(w []float64) []float64 {
-					return gradient(xi, yi, wMatrix)
-				},
-			}
-		},
-		AfterUpdateFunc: func(w []float64) {
-			for r := 0; r < wr; r++ {
-				for c := 0; c < wc; c++ {
-					wMatrix.Set(r, c, w[r*wc+c])
-				}
-			}
-		},
+	if m.Minimizer != nil {
+		indices := make([]int, xcount)
+		for i := range indices {
+			indices[i] = i
+		}
+		result, err := m.Minimizer.Minimize(gen(indices), wData)
+		if result.X == nil {
+			return err
+		}
+		m.weights = mat.NewDense(wr, wc, result.X)
+		return err
 	}
+	epochProvider := helpers.NewEpochProvider(xcount, m.BatchSize, gen)
 
 	op := graddesc.Optimizer{
 		EpochProvider: epochProvider,
@@ -110,10 +208,12 @@ func (m *Model) Train(dataset mygoml.SupervisedDataSet) error {
 		CheckInterval: 20,
 	}
 
-	wData = op.Optimize(wData)
-	m.weights = mat.NewDense(wr, wc, wData)
-
-	return nil
+	result, err := op.Optimize(wData)
+	if result.X == nil {
+		return err
+	}
+	m.weights = mat.NewDense(wr, wc, result.X)
+	return err
 }
 
 func (m *Model) Predict(features []float64) ([]float64, error) {
@@ -0,0 +1,93 @@
+package logregres
+
+import (
+	"math"
+	"math/rand"
+	"mygoml/graddesc"
+	"mygoml/regularization"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestGradient(t *testing.T) {
+	batchSize, wr, wc := 8, 4, 2
+	Xb := mat.NewDense(batchSize, wr, nil)
+	Yb := mat.NewDense(batchSize, wc, nil)
+	sw := make([]float64, batchSize)
+	for i := 0; i < batchSize; i++ {
+		for j := 0; j < wr-1; j++ {
+			Xb.Set(i, j, rand.NormFloat64())
+		}
+		Xb.Set(i, wr-1, 1)
+		for j := 0; j < wc; j++ {
+			Yb.Set(i, j, float64(rand.Intn(2)))
+		}
+		sw[i] = rand.Float64() + 0.5
+	}
+	w := make([]float64, wr*wc)
+	for i := range w {
+		w[i] = rand.NormFloat64()
+	}
+
+	models := map[string]*Model{
+		"plain":       {},
+		"l2":          {Regularization: regularization.Penalty{Type: regularization.L2, Alpha: 0.3}},
+		"elastic net": {Regularization: regularization.Penalty{Type: regularization.ElasticNet, Alpha: 0.3, L1Ratio: 0.5}},
+		"weighted":    {ClassWeights: []float64{0.2, 3}},
+	}
+	for name, m := range models {
+		t.Run(name, func(t *testing.T) {
+			f := graddesc.Function{
+				InputSize: wr * wc,
+				Mapper: func(w []float64) []float64 {
+					return []float64{m.loss(Xb, Yb, sw, mat.NewDense(wr, wc, w))}
+				},
+				Gradient: func(w []float64) []float64 {
+					return m.gradient(Xb, Yb, sw, mat.NewDense(wr, wc, w))
+				},
+			}
+			check, err := graddesc.CheckGradient(f, w, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if e, i := check.MaxRelativeError(); e > 1e-6 {
+				t.Errorf("relative error %g at %d: analytic %v, numeric %v", e, i, check.Analytic, check.Numeric)
+			}
+		})
+	}
+}
+
+func TestLargeLogits(t *testing.T) {
+	// weights this large push sigmod to exactly 0 or 1 in float64
+	Xb := mat.NewDense(4, 2, []float64{
+		1, 1,
+		-1, 1,
+		2, 1,
+		-2, 1,
+	})
+	Yb := mat.NewDense(4, 1, []float64{1, 0, 0, 1})
+	sw := []float64{1, 1, 1, 1}
+	w := []float64{100, 0.5}
+
+	m := &Model{}
+	f := graddesc.Function{
+		InputSize: 2,
+		Mapper: func(w []float64) []float64 {
+			return []float64{m.loss(Xb, Yb, sw, mat.NewDense(2, 1, w))}
+		},
+		Gradient: func(w []float64) []float64 {
+			return m.gradient(Xb, Yb, sw, mat.NewDense(2, 1, w))
+		},
+	}
+	if loss := f.Mapper(w)[0]; math.IsNaN(loss) || math.IsInf(loss, 0) {
+		t.Fatalf("expected a finite loss, got %g", loss)
+	}
+	check, err := graddesc.CheckGradient(f, w, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, i := check.MaxRelativeError(); !(e <= 1e-6) {
+		t.Errorf("relative error %g at %d: analytic %v, numeric %v", e, i, check.Analytic, check.Numeric)
+	}
+}
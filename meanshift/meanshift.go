@@ -0,0 +1,260 @@
+package meanshift
+
+import (
+	"fmt"
+	"math"
+	"mygoml"
+	"sort"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/spatial/kdtree"
+)
+
+// Kernel weighs the data points around a center when it is shifted to
+// their mean.
+type Kernel int
+
+const (
+	// Flat weighs the data points within the bandwidth equally
+	Flat Kernel = iota
+	// Gaussian weighs a data point at distance d by exp(-d²/2h²) for the
+	// bandwidth h, ignoring the data points beyond 3h
+	Gaussian
+)
+
+func (k Kernel) String() string {
+	switch k {
+	case Flat:
+		return "flat"
+	case Gaussian:
+		return "gaussian"
+	}
+	return fmt.Sprintf("Kernel(%d)", int(k))
+}
+
+type Cluster struct {
+	mygoml.BasicCluster
+	center []float64
+}
+
+func (c Cluster) Center() []float64 {
+	return c.center
+}
+
+// Model moves centers uphill on the density of the data points, each step
+// shifting a center to the weighted mean of the data points around it,
+// until they settle on the modes of the density. Close modes are merged
+// and every data point joins its nearest mode, so the number of clusters
+// follows from the bandwidth.
+type Model struct {
+	// Bandwidth is the radius of the kernel, estimated with
+	// EstimateBandwidth and a quantile of 0.3 when 0
+	Bandwidth float64
+	Kernel    Kernel
+	// MaxIter is the maximum number of shifts of a center, 300 when 0
+	MaxIter int
+	// BinSeeding starts the centers from a grid of bandwidth sized bins
+	// holding at least MinBinFreq data points instead of from every data
+	// point, which is much faster on large data sets such as images
+	BinSeeding bool
+	// MinBinFreq is the number of data points of a seeded bin, 1 when 0
+	MinBinFreq int
+	bandwidth  float64
+}
+
+// EstimateBandwidth returns the mean distance of the data points to their
+// nearest neighbor at the given quantile, e.g. the 30th of 100 data points
+// for 0.3. At most 500 data points, evenly spread over the data set, are
+// averaged.
+func EstimateBandwidth(ds mygoml.UnsupervisedDataSet, quantile float64) float64 {
+	dps := ds.DataPoints()
+	n := len(dps)
+	if n < 2 {
+		return 0
+	}
+	k := int(math.Max(1, math.Min(float64(n-1), quantile*float64(n))))
+	tree := newTree(dps)
+	step := int(math.Max(1, float64(n)/500))
+	sum, count := 0.0, 0
+	for i := 0; i < n; i += step {
+		// the data point itself is its nearest neighbor
+		keeper := kdtree.NewNKeeper(k + 1)
+		tree.NearestSet(keeper, kdtree.Point(dps[i].Features()))
+		sum = sum + math.Sqrt(keeper.Heap[len(keeper.Heap)-1].Dist)
+		count++
+	}
+	return sum / float64(count)
+}
+
+func newTree(dps []mygoml.UnsupervisedDataPoint) *kdtree.Tree {
+	// the tree reorders its points, so it gets its own slice
+	ps := make(kdtree.Points, len(dps))
+	for i, dp := range dps {
+		ps[i] = dp.Features()
+	}
+	return kdtree.New(ps, false)
+}
+
+// Clustering returns a cluster per mode of the density of ds.
+func (m *Model) Clustering(ds mygoml.UnsupervisedDataSet) []mygoml.Cluster {
+	dps := ds.DataPoints()
+	if len(dps) == 0 {
+		return nil
+	}
+	m.bandwidth = m.Bandwidth
+	if m.bandwidth <= 0 {
+		m.bandwidth = EstimateBandwidth(ds, 0.3)
+	}
+	if m.bandwidth <= 0 {
+		// every data point is the same
+		m.bandwidth = 1
+	}
+	maxIter := m.MaxIter
+	if maxIter <= 0 {
+		maxIter = 300
+	}
+	X := make([][]float64, len(dps))
+	for i, dp := range dps {
+		X[i] = dp.Features()
+	}
+	tree := newTree(dps)
+
+	var seeds [][]float64
+	if m.BinSeeding {
+		seeds = m.binSeeds(X)
+	}
+	if len(seeds) == 0 {
+		seeds = X
+	}
+
+	// shift every seed to its mode, keeping the number of data points
+	// within the bandwidth of the mode
+	type mode struct {
+		center    []float64
+		intensity int
+	}
+	var modes []mode
+	for _, seed := range seeds {
+		center := append([]float64(nil), seed...)
+		for iter := 0; iter < maxIter; iter++ {
+			next := m.shift(tree, center)
+			if next == nil {
+				break
+			}
+			moved := floats.Distance(next, center, 2)
+			center = next
+			if moved < 1e-3*m.bandwidth {
+				break
+			}
+		}
+		modes = append(modes, mode{center, len(m.neighbors(tree, center, m.bandwidth))})
+	}
+
+	// keep the densest modes, dropping those within the bandwidth of one
+	// already kept
+	sort.SliceStable(modes, func(i, j int) bool {
+		return modes[i].intensity > modes[j].intensity
+	})
+	var clusters []*Cluster
+	for _, md := range modes {
+		near := false
+		for _, c := range clusters {
+			if floats.Distance(md.center, c.center, 2) < m.bandwidth {
+				near = true
+				break
+			}
+		}
+		if !near {
+			clusters = append(clusters, &Cluster{center: md.center})
+		}
+	}
+
+	for i, x := range X {
+		best := 0
+		for k, c := range clusters {
+			if floats.Distance(x, c.center, 2) < floats.Distance(x, clusters[best].center, 2) {
+				best = k
+			}
+		}
+		clusters[best].Add(dps[i])
+	}
+	var out []mygoml.Cluster
+	for _, c := range clusters {
+		out = append(out, c)
+	}
+	return out
+}
+
+// neighbors returns the data points within radius of center.
+func (m *Model) neighbors(tree *kdtree.Tree, center []float64, radius float64) []kdtree.Point {
+	keeper := kdtree.NewDistKeeper(radius * radius)
+	tree.NearestSet(keeper, kdtree.Point(center))
+	out := make([]kdtree.Point, len(keeper.Heap))
+	for i, c := range keeper.Heap {
+		out[i] = c.Comparable.(kdtree.Point)
+	}
+	return out
+}
+
+// shift returns the kernel weighted mean of the data points around center,
+// or nil when there are none.
+func (m *Model) shift(tree *kdtree.Tree, center []float64) []float64 {
+	radius := m.bandwidth
+	if m.Kernel == Gaussian {
+		radius = 3 * m.bandwidth
+	}
+	neighbors := m.neighbors(tree, center, radius)
+	if len(neighbors) == 0 {
+		return nil
+	}
+	mean := make([]float64, len(center))
+	total := 0.0
+	for _, p := range neighbors {
+		w := 1.0
+		if m.Kernel == Gaussian {
+			d := floats.Distance(p, center, 2) / m.bandwidth
+			w = math.Exp(-d * d / 2)
+		}
+		floats.AddScaled(mean, w, p)
+		total = total + w
+	}
+	floats.Scale(1/total, mean)
+	return mean
+}
+
+// binSeeds returns the centers of the bins of a grid of bandwidth sized
+// cells that hold at least MinBinFreq data points.
+func (m *Model) binSeeds(X [][]float64) [][]float64 {
+	minFreq := m.MinBinFreq
+	if minFreq <= 0 {
+		minFreq = 1
+	}
+	counts := make(map[string]int)
+	bins := make(map[string][]float64)
+	var order []string
+	for _, x := range X {
+		bin := make([]float64, len(x))
+		for j, v := range x {
+			bin[j] = math.Round(v/m.bandwidth) * m.bandwidth
+		}
+		key := fmt.Sprint(bin)
+		if counts[key] == 0 {
+			bins[key] = bin
+			order = append(order, key)
+		}
+		counts[key]++
+	}
+	var seeds [][]float64
+	for _, key := range order {
+		if counts[key] >= minFreq {
+			seeds = append(seeds, bins[key])
+		}
+	}
+	return seeds
+}
+
+// EstimatedBandwidth returns the bandwidth used by the last clustering,
+// which was estimated when Bandwidth is 0.
+func (m *Model) EstimatedBandwidth() float64 {
+	return m.bandwidth
+}
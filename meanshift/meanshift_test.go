@@ -0,0 +1,47 @@
+package meanshift
+
+import (
+	"mygoml"
+	"testing"
+)
+
+func TestClustering(t *testing.T) {
+	models := map[string]*Model{
+		"flat":         {Bandwidth: 1},
+		"gaussian":     {Bandwidth: 1, Kernel: Gaussian},
+		"bin seeding":  {Bandwidth: 1, BinSeeding: true},
+		"bin frequent": {Bandwidth: 1, BinSeeding: true, MinBinFreq: 5},
+	}
+	for name, m := range models {
+		t.Run(name, func(t *testing.T) {
+			clusters := m.Clustering(mygoml.Grids(0, 10))
+			if len(clusters) != 2 {
+				t.Fatalf("expected 2 clusters, got %d", len(clusters))
+			}
+			for _, c := range clusters {
+				members := c.Members()
+				if len(members) != 9 {
+					t.Fatalf("expected 9 data points per cluster, got %d", len(members))
+				}
+				offset := 0.0
+				if members[0].Features()[0] > 5 {
+					offset = 10
+				}
+				for _, p := range members {
+					if (p.Features()[0] > 5) != (offset == 10) {
+						t.Fatalf("expected the grids in separate clusters, got %v with %v", p, members[0])
+					}
+				}
+				center := c.(*Cluster).Center()
+				mygoml.FloatEqual(t, "center x", offset+0.1, center[0])
+				mygoml.FloatEqual(t, "center y", offset+0.1, center[1])
+			}
+		})
+	}
+
+	// a bandwidth spanning both grids gives a single cluster
+	m := &Model{Bandwidth: 20}
+	if clusters := m.Clustering(mygoml.Grids(0, 10)); len(clusters) != 1 || len(clusters[0].Members()) != 18 {
+		t.Errorf("expected a single cluster, got %d", len(clusters))
+	}
+}
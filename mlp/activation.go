@@ -0,0 +1,105 @@
+package mlp
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Activation is applied to the pre-activations Z of a layer, one data point
+// per row. Backward turns dL/dA into dL/dZ in place, given the layer output A.
+type Activation struct {
+	name     string
+	Forward  func(Z *mat.Dense)
+	Backward func(dA, A *mat.Dense)
+}
+
+var Identity = Activation{
+	name:     "identity",
+	Forward:  func(Z *mat.Dense) {},
+	Backward: func(dA, A *mat.Dense) {},
+}
+
+var ReLU = Activation{
+	name: "relu",
+	Forward: func(Z *mat.Dense) {
+		Z.Apply(func(i, j int, z float64) float64 {
+			return math.Max(0, z)
+		}, Z)
+	},
+	Backward: func(dA, A *mat.Dense) {
+		dA.Apply(func(i, j int, d float64) float64 {
+			if A.At(i, j) > 0 {
+				return d
+			}
+			return 0
+		}, dA)
+	},
+}
+
+var Tanh = Activation{
+	name: "tanh",
+	Forward: func(Z *mat.Dense) {
+		Z.Apply(func(i, j int, z float64) float64 {
+			return math.Tanh(z)
+		}, Z)
+	},
+	Backward: func(dA, A *mat.Dense) {
+		dA.Apply(func(i, j int, d float64) float64 {
+			a := A.At(i, j)
+			return d * (1 - a*a)
+		}, dA)
+	},
+}
+
+var Sigmoid = Activation{
+	name: "sigmoid",
+	Forward: func(Z *mat.Dense) {
+		Z.Apply(func(i, j int, z float64) float64 {
+			return 1 / (1 + math.Exp(-z))
+		}, Z)
+	},
+	Backward: func(dA, A *mat.Dense) {
+		dA.Apply(func(i, j int, d float64) float64 {
+			a := A.At(i, j)
+			return d * a * (1 - a)
+		}, dA)
+	},
+}
+
+var Softmax = Activation{
+	name: "softmax",
+	Forward: func(Z *mat.Dense) {
+		r, _ := Z.Dims()
+		for i := 0; i < r; i++ {
+			z := Z.RawRowView(i)
+			max := z[0]
+			for _, v := range z {
+				max = math.Max(max, v)
+			}
+			sum := 0.0
+			for j, v := range z {
+				z[j] = math.Exp(v - max)
+				sum = sum + z[j]
+			}
+			for j := range z {
+				z[j] = z[j] / sum
+			}
+		}
+	},
+	// dZ_j = a_j * (dA_j - sum_k(a_k * dA_k))
+	Backward: func(dA, A *mat.Dense) {
+		r, _ := dA.Dims()
+		for i := 0; i < r; i++ {
+			d := dA.RawRowView(i)
+			a := A.RawRowView(i)
+			dot := 0.0
+			for j := range d {
+				dot = dot + a[j]*d[j]
+			}
+			for j := range d {
+				d[j] = a[j] * (d[j] - dot)
+			}
+		}
+	},
+}
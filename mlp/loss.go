@@ -0,0 +1,91 @@
+package mlp
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// tiny keeps log and division away from zero
+const tiny = 1e-12
+
+// Loss compares the network output A with the targets Y, one data point per
+// row. Value is averaged over the rows and Gradient returns dL/dA.
+type Loss struct {
+	name     string
+	Value    func(A, Y *mat.Dense) float64
+	Gradient func(A, Y *mat.Dense) *mat.Dense
+}
+
+var MeanSquaredError = Loss{
+	name: "mse",
+	Value: func(A, Y *mat.Dense) float64 {
+		r, _ := A.Dims()
+		var diff mat.Dense
+		diff.Sub(A, Y)
+		norm := mat.Norm(&diff, 2)
+		return norm * norm / float64(2*r)
+	},
+	Gradient: func(A, Y *mat.Dense) *mat.Dense {
+		r, _ := A.Dims()
+		var grad mat.Dense
+		grad.Sub(A, Y)
+		grad.Scale(1/float64(r), &grad)
+		return &grad
+	},
+}
+
+// CrossEntropy expects one-hot targets, usually behind a Softmax output.
+var CrossEntropy = Loss{
+	name: "crossentropy",
+	Value: func(A, Y *mat.Dense) float64 {
+		r, c := A.Dims()
+		sum := 0.0
+		for i := 0; i < r; i++ {
+			for j := 0; j < c; j++ {
+				sum = sum - Y.At(i, j)*math.Log(math.Max(A.At(i, j), tiny))
+			}
+		}
+		return sum / float64(r)
+	},
+	Gradient: func(A, Y *mat.Dense) *mat.Dense {
+		r, _ := A.Dims()
+		var grad mat.Dense
+		grad.Apply(func(i, j int, a float64) float64 {
+			return -Y.At(i, j) / math.Max(a, tiny) / float64(r)
+		}, A)
+		return &grad
+	},
+}
+
+// BinaryCrossEntropy expects 0/1 targets, usually behind a Sigmoid output.
+var BinaryCrossEntropy = Loss{
+	name: "binarycrossentropy",
+	Value: func(A, Y *mat.Dense) float64 {
+		r, c := A.Dims()
+		sum := 0.0
+		for i := 0; i < r; i++ {
+			for j := 0; j < c; j++ {
+				a, y := A.At(i, j), Y.At(i, j)
+				sum = sum - y*math.Log(math.Max(a, tiny)) - (1-y)*math.Log(math.Max(1-a, tiny))
+			}
+		}
+		return sum / float64(r)
+	},
+	Gradient: func(A, Y *mat.Dense) *mat.Dense {
+		r, _ := A.Dims()
+		var grad mat.Dense
+		grad.Apply(func(i, j int, a float64) float64 {
+			y := Y.At(i, j)
+			return (-y/math.Max(a, tiny) + (1-y)/math.Max(1-a, tiny)) / float64(r)
+		}, A)
+		return &grad
+	},
+}
+
+// fused reports whether the output activation and the loss simplify to
+// dL/dZ = (A - Y) / n, which avoids dividing by tiny probabilities.
+func fused(output Activation, loss Loss) bool {
+	return (output.name == "softmax" && loss.name == "crossentropy") ||
+		(output.name == "sigmoid" && loss.name == "binarycrossentropy")
+}
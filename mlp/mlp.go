@@ -0,0 +1,250 @@
+package mlp
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"mygoml"
+	"mygoml/graddesc"
+	"mygoml/helpers"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+const (
+	defaultLearningRate = 0.1
+	defaultMaxStep      = 1000
+)
+
+// Model is a fully connected feed-forward network. Each layer keeps its
+// weights as an (inputs+1) x outputs matrix whose last row is the bias.
+type Model struct {
+	HiddenLayers []int
+	// Activation is used by the hidden layers, ReLU by default
+	Activation Activation
+	// Output is used by the last layer, Softmax by default
+	Output Activation
+	// Loss is CrossEntropy by default
+	Loss         Loss
+	Updater      graddesc.Updater
+	LearningRate float64
+	MaxStep      int
+	BatchSize    int
+	weights      []*mat.Dense
+}
+
+func (m *Model) Weights() []mat.Matrix {
+	var out []mat.Matrix
+	for _, w := range m.weights {
+		out = append(out, mat.DenseCopyOf(w))
+	}
+	return out
+}
+
+func (m *Model) hidden() Activation {
+	if m.Activation.Forward == nil {
+		return ReLU
+	}
+	return m.Activation
+}
+
+func (m *Model) output() Activation {
+	if m.Output.Forward == nil {
+		return Softmax
+	}
+	return m.Output
+}
+
+func (m *Model) loss() Loss {
+	if m.Loss.Value == nil {
+		return CrossEntropy
+	}
+	return m.Loss
+}
+
+func (m *Model) activation(layer int) Activation {
+	if layer == len(m.weights)-1 {
+		return m.output()
+	}
+	return m.hidden()
+}
+
+// layerSizes returns the number of units of every layer, inputs included.
+func (m *Model) layerSizes(inputs, outputs int) []int {
+	sizes := []int{inputs}
+	sizes = append(sizes, m.HiddenLayers...)
+	return append(sizes, outputs)
+}
+
+// unflatten makes the layer matrices share the memory of params.
+func unflatten(params []float64, sizes []int) []*mat.Dense {
+	var layers []*mat.Dense
+	offset := 0
+	for l := 0; l < len(sizes)-1; l++ {
+		r, c := sizes[l]+1, sizes[l+1]
+		layers = append(layers, mat.NewDense(r, c, params[offset:offset+r*c]))
+		offset = offset + r*c
+	}
+	return layers
+}
+
+func initialParams(sizes []int) []float64 {
+	var params []float64
+	for l := 0; l < len(sizes)-1; l++ {
+		in, out := sizes[l], sizes[l+1]
+		scale := math.Sqrt(2 / float64(in))
+		for i := 0; i < in*out; i++ {
+			params = append(params, rand.NormFloat64()*scale)
+		}
+		// bias
+		params = append(params, make([]float64, out)...)
+	}
+	return params
+}
+
+// forward returns the output of every layer, starting with X itself.
+func (m *Model) forward(layers []*mat.Dense, X *mat.Dense) []*mat.Dense {
+	outputs := []*mat.Dense{X}
+	A := X
+	for l, W := range layers {
+		r, c := W.Dims()
+		n, _ := A.Dims()
+		Z := mat.NewDense(n, c, nil)
+		Z.Mul(A, W.Slice(0, r-1, 0, c))
+		bias := W.RawRowView(r - 1)
+		for i := 0; i < n; i++ {
+			row := Z.RawRowView(i)
+			for j := range row {
+				row[j] = row[j] + bias[j]
+			}
+		}
+		m.activation(l).Forward(Z)
+		outputs = append(outputs, Z)
+		A = Z
+	}
+	return outputs
+}
+
+// backward returns dL/dparams, laid out like the parameters.
+func (m *Model) backward(layers []*mat.Dense, outputs []*mat.Dense, Y *mat.Dense) []float64 {
+	var grads []*mat.Dense
+	for _, W := range layers {
+		r, c := W.Dims()
+		grads = append(grads, mat.NewDense(r, c, nil))
+	}
+
+	// dL/dZ of the output layer
+	last := len(layers) - 1
+	A := outputs[last+1]
+	var dZ *mat.Dense
+	if fused(m.output(), m.loss()) {
+		n, c := A.Dims()
+		dZ = mat.NewDense(n, c, nil)
+		dZ.Sub(A, Y)
+		dZ.Scale(1/float64(n), dZ)
+	} else {
+		dZ = m.loss().Gradient(A, Y)
+		m.output().Backward(dZ, A)
+	}
+
+	for l := last; l >= 0; l-- {
+		W := layers[l]
+		r, c := W.Dims()
+		Ain := outputs[l]
+
+		// dW = A^t * dZ, db = column sums of dZ
+		grads[l].Slice(0, r-1, 0, c).(*mat.Dense).Mul(Ain.T(), dZ)
+		db := grads[l].RawRowView(r - 1)
+		n, _ := dZ.Dims()
+		for i := 0; i < n; i++ {
+			for j, v := range dZ.RawRowView(i) {
+				db[j] = db[j] + v
+			}
+		}
+
+		if l > 0 {
+			// dA = dZ * W^t, then back through the activation
+			dA := mat.NewDense(n, r-1, nil)
+			dA.Mul(dZ, W.Slice(0, r-1, 0, c).T())
+			m.activation(l-1).Backward(dA, Ain)
+			dZ = dA
+		}
+	}
+
+	var flat []float64
+	for _, g := range grads {
+		flat = append(flat, g.RawMatrix().Data...)
+	}
+	return flat
+}
+
+func (m *Model) Train(dataset mygoml.SupervisedDataSet) error {
+	if len(dataset.DataPoints()) == 0 {
+		return mygoml.ErrDatasetEmpty
+	}
+	X, Y := helpers.ConvertSupervisedDataset(dataset, false)
+	inputs, xcount := X.Dims()
+	outputs, _ := Y.Dims()
+	sizes := m.layerSizes(inputs, outputs)
+	params := initialParams(sizes)
+
+	// keep one data point per row so that a batch is a set of rows
+	Xrows := mat.DenseCopyOf(X.T())
+	Yrows := mat.DenseCopyOf(Y.T())
+	epochProvider := helpers.NewEpochProvider(xcount, m.BatchSize, func(indices []int) graddesc.Function {
+		Xb := helpers.Rows(Xrows, indices)
+		Yb := helpers.Rows(Yrows, indices)
+
+		return graddesc.Function{
+			InputSize: len(params),
+			Mapper: func(p []float64) []float64 {
+				outputs := m.forward(unflatten(p, sizes), Xb)
+				return []float64{m.loss().Value(outputs[len(outputs)-1], Yb)}
+			},
+			Gradient: func(p []float64) []float64 {
+				layers := unflatten(p, sizes)
+				return m.backward(layers, m.forward(layers, Xb), Yb)
+			},
+		}
+	})
+
+	updater := m.Updater
+	if updater == nil {
+		updater = &graddesc.BaseUpdater{}
+	}
+	learningRate := m.LearningRate
+	if learningRate <= 0 {
+		learningRate = defaultLearningRate
+	}
+	maxStep := m.MaxStep
+	if maxStep <= 0 {
+		maxStep = defaultMaxStep
+	}
+	op := graddesc.Optimizer{
+		EpochProvider: epochProvider,
+		LearningRate:  learningRate,
+		MaxStep:       maxStep,
+		Updater:       updater,
+	}
+
+	result, err := op.Optimize(params)
+	if result.X == nil {
+		return err
+	}
+	m.weights = unflatten(result.X, sizes)
+	return err
+}
+
+func (m *Model) Predict(features []float64) ([]float64, error) {
+	if len(m.weights) == 0 {
+		return nil, mygoml.ErrIncompatibleDataAndModel("model is not trained")
+	}
+	if r, _ := m.weights[0].Dims(); len(features) != r-1 {
+		msg := fmt.Sprintf("model expects %d features but got %d features", r-1, len(features))
+		return nil, mygoml.ErrIncompatibleDataAndModel(msg)
+	}
+
+	X := mat.NewDense(1, len(features), features)
+	outputs := m.forward(m.weights, X)
+	return mat.Row(nil, 0, outputs[len(outputs)-1]), nil
+}
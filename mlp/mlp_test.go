@@ -0,0 +1,90 @@
+package mlp
+
+import (
+	"math/rand"
+	"mygoml"
+	"mygoml/graddesc"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// randomDense fills an r x c matrix with values drawn by value.
+func randomDense(r, c int, value func() float64) *mat.Dense {
+	m := mat.NewDense(r, c, nil)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			m.Set(i, j, value())
+		}
+	}
+	return m
+}
+
+func TestGradient(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	n, inputs, outputs := 6, 3, 3
+	X := randomDense(n, inputs, rnd.NormFloat64)
+	targets := map[string]*mat.Dense{
+		"mse":          randomDense(n, outputs, rnd.NormFloat64),
+		"crossentropy": mat.NewDense(n, outputs, nil),
+		"binarycrossentropy": randomDense(n, outputs, func() float64 {
+			return float64(rnd.Intn(2))
+		}),
+	}
+	for i := 0; i < n; i++ {
+		targets["crossentropy"].Set(i, rnd.Intn(outputs), 1)
+	}
+
+	tests := []struct {
+		output Activation
+		loss   Loss
+	}{
+		{Identity, MeanSquaredError},
+		{Sigmoid, MeanSquaredError},
+		{Tanh, MeanSquaredError},
+		{Softmax, MeanSquaredError},
+		{Softmax, CrossEntropy},
+		{Sigmoid, BinaryCrossEntropy},
+	}
+	for _, hidden := range []Activation{Identity, ReLU, Tanh, Sigmoid} {
+		for _, test := range tests {
+			name := hidden.name + "/" + test.output.name + "/" + test.loss.name
+			t.Run(name, func(t *testing.T) {
+				m := &Model{HiddenLayers: []int{4, 3}, Activation: hidden, Output: test.output, Loss: test.loss}
+				Y := targets[test.loss.name]
+				sizes := m.layerSizes(inputs, outputs)
+				// random biases keep the ReLU units off their kink, where
+				// the numerical gradient is meaningless
+				params := len(initialParams(sizes))
+				x := randomDense(1, params, rnd.NormFloat64).RawRowView(0)
+				// the layer count picks the output activation
+				m.weights = unflatten(x, sizes)
+				f := graddesc.Function{
+					InputSize: params,
+					Mapper: func(p []float64) []float64 {
+						outputs := m.forward(unflatten(p, sizes), X)
+						return []float64{m.loss().Value(outputs[len(outputs)-1], Y)}
+					},
+					Gradient: func(p []float64) []float64 {
+						layers := unflatten(p, sizes)
+						return m.backward(layers, m.forward(layers, X), Y)
+					},
+				}
+				check, err := graddesc.CheckGradient(f, x, 0)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if e, i := check.MaxRelativeError(); e > 1e-5 {
+					t.Errorf("relative error %g at %d: analytic %v, numeric %v", e, i, check.Analytic, check.Numeric)
+				}
+			})
+		}
+	}
+}
+
+func TestUntrained(t *testing.T) {
+	m := &Model{}
+	if _, err := m.Predict([]float64{1, 2}); err != mygoml.ErrIncompatibleDataAndModel("model is not trained") {
+		t.Errorf("expected an error from an untrained model, got %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package naivebayes
+
+import (
+	"fmt"
+	"math"
+	"mygoml"
+	"sort"
+)
+
+// base keeps the classes seen so far and how many data points each had.
+// Classes are stored in the order they were first seen, so that PartialFit
+// can add one by appending to the per-class statistics of every model.
+type base struct {
+	labels   []float64
+	index    map[float64]int
+	counts   []float64
+	features int
+}
+
+// add checks a batch of data points and registers their classes, calling
+// newClass once for every class seen for the first time. It returns the
+// features and the class index of every data point.
+func (b *base) add(dataset mygoml.SupervisedDataSet, newClass func()) ([][]float64, []int, error) {
+	dps := dataset.DataPoints()
+	if len(dps) == 0 {
+		return nil, nil, mygoml.ErrDatasetEmpty
+	}
+	// the first batch sets the number of features, which is only kept once
+	// the whole batch is valid so that a failed batch leaves b untouched
+	features := b.features
+	if b.index == nil {
+		features = len(dps[0].Features())
+	}
+	X := make([][]float64, len(dps))
+	classes := make([]int, len(dps))
+	for i, dp := range dps {
+		X[i] = dp.Features()
+		if len(X[i]) != features {
+			msg := fmt.Sprintf("model expects %d features but got %d features", features, len(X[i]))
+			return nil, nil, mygoml.ErrIncompatibleDataAndModel(msg)
+		}
+	}
+	if b.index == nil {
+		b.index = make(map[float64]int)
+		b.features = features
+	}
+	for i, dp := range dps {
+		label := dp.Target()[0]
+		c, ok := b.index[label]
+		if !ok {
+			c = len(b.labels)
+			b.index[label] = c
+			b.labels = append(b.labels, label)
+			b.counts = append(b.counts, 0)
+			newClass()
+		}
+		b.counts[c]++
+		classes[i] = c
+	}
+	return X, classes, nil
+}
+
+func (b *base) check(features []float64) error {
+	if b.index == nil {
+		return mygoml.ErrIncompatibleDataAndModel("model is not trained")
+	}
+	if len(features) != b.features {
+		msg := fmt.Sprintf("model expects %d features but got %d features", b.features, len(features))
+		return mygoml.ErrIncompatibleDataAndModel(msg)
+	}
+	return nil
+}
+
+func (b *base) logPrior(c int) float64 {
+	total := 0.0
+	for _, count := range b.counts {
+		total = total + count
+	}
+	return math.Log(b.counts[c] / total)
+}
+
+// sorted returns the class indices in increasing order of their labels.
+func (b *base) sorted() []int {
+	order := make([]int, len(b.labels))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return b.labels[order[i]] < b.labels[order[j]]
+	})
+	return order
+}
+
+// Classes returns the sorted class labels seen so far.
+func (b *base) Classes() []float64 {
+	out := make([]float64, len(b.labels))
+	for i, c := range b.sorted() {
+		out[i] = b.labels[c]
+	}
+	return out
+}
+
+// predict returns the label with the highest joint log-likelihood.
+func (b *base) predict(jll []float64) []float64 {
+	best := 0
+	for c, v := range jll {
+		if v > jll[best] || v == jll[best] && b.labels[c] < b.labels[best] {
+			best = c
+		}
+	}
+	return []float64{b.labels[best]}
+}
+
+// proba normalizes the joint log-likelihoods into probabilities, in the
+// order of Classes.
+func (b *base) proba(jll []float64) []float64 {
+	max := math.Inf(-1)
+	for _, v := range jll {
+		max = math.Max(max, v)
+	}
+	sum := 0.0
+	for _, v := range jll {
+		sum = sum + math.Exp(v-max)
+	}
+	out := make([]float64, len(jll))
+	for i, c := range b.sorted() {
+		out[i] = math.Exp(jll[c]-max) / sum
+	}
+	return out
+}
+
+// smoothing returns alpha, or 1 for Laplace smoothing when it is 0.
+func smoothing(alpha float64) float64 {
+	if alpha <= 0 {
+		return 1
+	}
+	return alpha
+}
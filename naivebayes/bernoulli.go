@@ -0,0 +1,78 @@
+package naivebayes
+
+import (
+	"math"
+	"mygoml"
+)
+
+// Bernoulli models every feature of a class as an independent binary
+// variable, a feature being on when it is above Binarize.
+type Bernoulli struct {
+	// Alpha is the additive smoothing of the feature counts, 1 (Laplace
+	// smoothing) when 0
+	Alpha float64
+	// Binarize is the threshold above which a feature is on
+	Binarize float64
+	base
+	// on counts the data points of every class with each feature on
+	on [][]float64
+}
+
+// Train forgets what the model learned and fits dataset.
+func (m *Bernoulli) Train(dataset mygoml.SupervisedDataSet) error {
+	*m = Bernoulli{Alpha: m.Alpha, Binarize: m.Binarize}
+	return m.PartialFit(dataset)
+}
+
+// PartialFit updates the model with another batch of data points, which
+// may bring new classes.
+func (m *Bernoulli) PartialFit(dataset mygoml.SupervisedDataSet) error {
+	X, classes, err := m.add(dataset, func() {
+		m.on = append(m.on, make([]float64, m.features))
+	})
+	if err != nil {
+		return err
+	}
+	for i, x := range X {
+		for j, v := range x {
+			if v > m.Binarize {
+				m.on[classes[i]][j]++
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Bernoulli) jointLogLikelihood(features []float64) []float64 {
+	alpha := smoothing(m.Alpha)
+	jll := make([]float64, len(m.labels))
+	for c := range jll {
+		jll[c] = m.logPrior(c)
+		for j, x := range features {
+			p := (m.on[c][j] + alpha) / (m.counts[c] + 2*alpha)
+			if x > m.Binarize {
+				jll[c] = jll[c] + math.Log(p)
+			} else {
+				jll[c] = jll[c] + math.Log(1-p)
+			}
+		}
+	}
+	return jll
+}
+
+// Predict returns the most probable class label.
+func (m *Bernoulli) Predict(features []float64) ([]float64, error) {
+	if err := m.check(features); err != nil {
+		return nil, err
+	}
+	return m.predict(m.jointLogLikelihood(features)), nil
+}
+
+// PredictProba returns the probability of every class, in the order of
+// Classes.
+func (m *Bernoulli) PredictProba(features []float64) ([]float64, error) {
+	if err := m.check(features); err != nil {
+		return nil, err
+	}
+	return m.proba(m.jointLogLikelihood(features)), nil
+}
@@ -0,0 +1,99 @@
+package naivebayes
+
+import (
+	"math"
+	"mygoml"
+)
+
+// Gaussian models every feature of a class as an independent normal
+// distribution.
+type Gaussian struct {
+	// VarSmoothing adds that fraction of the largest feature variance to
+	// every variance for stability, 1e-9 when 0
+	VarSmoothing float64
+	base
+	// mean and m2 hold the running mean and sum of squared deviations of
+	// every feature of every class
+	mean [][]float64
+	m2   [][]float64
+}
+
+// Train forgets what the model learned and fits dataset.
+func (m *Gaussian) Train(dataset mygoml.SupervisedDataSet) error {
+	*m = Gaussian{VarSmoothing: m.VarSmoothing}
+	return m.PartialFit(dataset)
+}
+
+// PartialFit updates the model with another batch of data points, which
+// may bring new classes.
+func (m *Gaussian) PartialFit(dataset mygoml.SupervisedDataSet) error {
+	X, classes, err := m.add(dataset, func() {
+		m.mean = append(m.mean, make([]float64, m.features))
+		m.m2 = append(m.m2, make([]float64, m.features))
+	})
+	if err != nil {
+		return err
+	}
+
+	// add already counted the batch, start from the counts before it
+	seen := append([]float64(nil), m.counts...)
+	for _, c := range classes {
+		seen[c]--
+	}
+	for i, x := range X {
+		c := classes[i]
+		seen[c]++
+		for j, v := range x {
+			delta := v - m.mean[c][j]
+			m.mean[c][j] += delta / seen[c]
+			m.m2[c][j] += delta * (v - m.mean[c][j])
+		}
+	}
+	return nil
+}
+
+func (m *Gaussian) jointLogLikelihood(features []float64) []float64 {
+	smoothing := m.VarSmoothing
+	if smoothing <= 0 {
+		smoothing = 1e-9
+	}
+	largest := 0.0
+	for c := range m.m2 {
+		for j := range m.m2[c] {
+			largest = math.Max(largest, m.m2[c][j]/m.counts[c])
+		}
+	}
+	if largest == 0 {
+		largest = 1
+	}
+	epsilon := smoothing * largest
+
+	jll := make([]float64, len(m.labels))
+	for c := range jll {
+		sum := m.logPrior(c)
+		for j, x := range features {
+			variance := m.m2[c][j]/m.counts[c] + epsilon
+			d := x - m.mean[c][j]
+			sum = sum - 0.5*math.Log(2*math.Pi*variance) - d*d/(2*variance)
+		}
+		jll[c] = sum
+	}
+	return jll
+}
+
+// Predict returns the most probable class label.
+func (m *Gaussian) Predict(features []float64) ([]float64, error) {
+	if err := m.check(features); err != nil {
+		return nil, err
+	}
+	return m.predict(m.jointLogLikelihood(features)), nil
+}
+
+// PredictProba returns the probability of every class, in the order of
+// Classes.
+func (m *Gaussian) PredictProba(features []float64) ([]float64, error) {
+	if err := m.check(features); err != nil {
+		return nil, err
+	}
+	return m.proba(m.jointLogLikelihood(features)), nil
+}
@@ -0,0 +1,170 @@
+package naivebayes
+
+import (
+	"math"
+	"mygoml"
+)
+
+// featureCounts sums the non-negative features, e.g. word counts or pixel
+// intensities, of every class.
+type featureCounts struct {
+	base
+	sums [][]float64
+}
+
+func (f *featureCounts) partialFit(dataset mygoml.SupervisedDataSet) error {
+	for _, dp := range dataset.DataPoints() {
+		for _, v := range dp.Features() {
+			if v < 0 {
+				return mygoml.ErrIncompatibleDataAndModel("naive bayes on counts needs non-negative features")
+			}
+		}
+	}
+	X, classes, err := f.add(dataset, func() {
+		f.sums = append(f.sums, make([]float64, f.features))
+	})
+	if err != nil {
+		return err
+	}
+	for i, x := range X {
+		for j, v := range x {
+			f.sums[classes[i]][j] += v
+		}
+	}
+	return nil
+}
+
+// logProbabilities returns log((N_j + alpha) / (N + alpha*features)) for
+// the feature sums N_j and their total N.
+func logProbabilities(sums []float64, alpha float64) []float64 {
+	total := 0.0
+	for _, v := range sums {
+		total = total + v
+	}
+	out := make([]float64, len(sums))
+	for j, v := range sums {
+		out[j] = math.Log((v + alpha) / (total + alpha*float64(len(sums))))
+	}
+	return out
+}
+
+// Multinomial models the features of a class as counts drawn from a
+// multinomial distribution.
+type Multinomial struct {
+	// Alpha is the additive smoothing of the feature counts, 1 (Laplace
+	// smoothing) when 0
+	Alpha float64
+	featureCounts
+}
+
+// Train forgets what the model learned and fits dataset.
+func (m *Multinomial) Train(dataset mygoml.SupervisedDataSet) error {
+	m.featureCounts = featureCounts{}
+	return m.partialFit(dataset)
+}
+
+// PartialFit updates the model with another batch of data points, which
+// may bring new classes.
+func (m *Multinomial) PartialFit(dataset mygoml.SupervisedDataSet) error {
+	return m.partialFit(dataset)
+}
+
+func (m *Multinomial) jointLogLikelihood(features []float64) []float64 {
+	jll := make([]float64, len(m.labels))
+	for c := range jll {
+		jll[c] = m.logPrior(c)
+		for j, logp := range logProbabilities(m.sums[c], smoothing(m.Alpha)) {
+			jll[c] = jll[c] + features[j]*logp
+		}
+	}
+	return jll
+}
+
+// Predict returns the most probable class label.
+func (m *Multinomial) Predict(features []float64) ([]float64, error) {
+	if err := m.check(features); err != nil {
+		return nil, err
+	}
+	return m.predict(m.jointLogLikelihood(features)), nil
+}
+
+// PredictProba returns the probability of every class, in the order of
+// Classes.
+func (m *Multinomial) PredictProba(features []float64) ([]float64, error) {
+	if err := m.check(features); err != nil {
+		return nil, err
+	}
+	return m.proba(m.jointLogLikelihood(features)), nil
+}
+
+// Complement estimates the feature distribution of every class from the
+// data points of all the other classes, which copes better with
+// imbalanced classes than Multinomial. A data point belongs to the class
+// whose complement explains it the worst.
+type Complement struct {
+	// Alpha is the additive smoothing of the feature counts, 1 (Laplace
+	// smoothing) when 0
+	Alpha float64
+	// Norm normalizes the weights of every class by their L1 norm
+	Norm bool
+	featureCounts
+}
+
+// Train forgets what the model learned and fits dataset.
+func (m *Complement) Train(dataset mygoml.SupervisedDataSet) error {
+	m.featureCounts = featureCounts{}
+	return m.partialFit(dataset)
+}
+
+// PartialFit updates the model with another batch of data points, which
+// may bring new classes.
+func (m *Complement) PartialFit(dataset mygoml.SupervisedDataSet) error {
+	return m.partialFit(dataset)
+}
+
+func (m *Complement) jointLogLikelihood(features []float64) []float64 {
+	all := make([]float64, m.features)
+	for _, sums := range m.sums {
+		for j, v := range sums {
+			all[j] += v
+		}
+	}
+
+	jll := make([]float64, len(m.labels))
+	complement := make([]float64, m.features)
+	for c := range jll {
+		for j := range complement {
+			complement[j] = all[j] - m.sums[c][j]
+		}
+		weights := logProbabilities(complement, smoothing(m.Alpha))
+		norm := 1.0
+		if m.Norm {
+			norm = 0
+			for _, w := range weights {
+				norm = norm + math.Abs(w)
+			}
+		}
+		for j, w := range weights {
+			jll[c] = jll[c] - features[j]*w/norm
+		}
+	}
+	return jll
+}
+
+// Predict returns the most probable class label.
+func (m *Complement) Predict(features []float64) ([]float64, error) {
+	if err := m.check(features); err != nil {
+		return nil, err
+	}
+	return m.predict(m.jointLogLikelihood(features)), nil
+}
+
+// PredictProba returns the normalized exponentials of the class scores, in
+// the order of Classes. They rank the classes like Predict but are not
+// calibrated probabilities.
+func (m *Complement) PredictProba(features []float64) ([]float64, error) {
+	if err := m.check(features); err != nil {
+		return nil, err
+	}
+	return m.proba(m.jointLogLikelihood(features)), nil
+}
@@ -0,0 +1,140 @@
+package naivebayes
+
+import (
+	"math"
+	"math/rand"
+	"mygoml"
+	"testing"
+)
+
+// counts draws word counts where class c favours the features c*3 to c*3+2.
+func counts(n int) mygoml.Points {
+	var d mygoml.Points
+	for i := 0; i < n; i++ {
+		c := i % 3
+		f := make([]float64, 9)
+		for k := 0; k < 20; k++ {
+			j := rand.Intn(9)
+			if rand.Float64() < 0.6 {
+				j = c*3 + rand.Intn(3)
+			}
+			f[j]++
+		}
+		d = append(d, mygoml.Point{X: f, Y: float64(10 - c)})
+	}
+	return d
+}
+
+type streaming interface {
+	mygoml.SupervisedModel
+	PartialFit(mygoml.SupervisedDataSet) error
+	PredictProba([]float64) ([]float64, error)
+	Classes() []float64
+}
+
+func TestPartialFit(t *testing.T) {
+	models := map[string]func() streaming{
+		"gaussian":    func() streaming { return &Gaussian{} },
+		"multinomial": func() streaming { return &Multinomial{} },
+		"complement":  func() streaming { return &Complement{Norm: true} },
+		"bernoulli":   func() streaming { return &Bernoulli{Binarize: 1} },
+	}
+	d := counts(300)
+	for name, create := range models {
+		t.Run(name, func(t *testing.T) {
+			whole := create()
+			if err := whole.Train(d); err != nil {
+				t.Fatal(err)
+			}
+			// the first batch holds a single class, the others come later
+			stream := create()
+			for _, batch := range []mygoml.Points{d[:1], d[1:150], d[150:]} {
+				if err := stream.PartialFit(batch); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			correct := 0
+			for _, p := range d {
+				a, _ := whole.PredictProba(p.X)
+				b, _ := stream.PredictProba(p.X)
+				for c := range a {
+					if math.Abs(a[c]-b[c]) > 1e-9 {
+						t.Fatalf("probabilities %v, want %v", b, a)
+					}
+				}
+				y, _ := whole.Predict(p.X)
+				if y[0] == p.Y {
+					correct++
+				}
+			}
+			if classes := whole.Classes(); len(classes) != 3 || classes[0] != 8 || classes[2] != 10 {
+				t.Errorf("classes %v", classes)
+			}
+			if acc := float64(correct) / float64(len(d)); acc < 0.9 {
+				t.Errorf("accuracy %g", acc)
+			}
+		})
+	}
+}
+
+func TestStreamingMatchesBatch(t *testing.T) {
+	d := counts(300)
+	whole := &Gaussian{}
+	whole.Train(d)
+	stream := &Gaussian{}
+	for start := 0; start < len(d); start += 40 {
+		end := start + 40
+		if end > len(d) {
+			end = len(d)
+		}
+		stream.PartialFit(d[start:end])
+	}
+	for c := range whole.mean {
+		for j := range whole.mean[c] {
+			if math.Abs(whole.mean[c][j]-stream.mean[c][j]) > 1e-9 || math.Abs(whole.m2[c][j]-stream.m2[c][j]) > 1e-9 {
+				t.Fatalf("class %d feature %d: streamed statistics differ", c, j)
+			}
+		}
+	}
+}
+
+func TestNegativeCounts(t *testing.T) {
+	m := &Multinomial{}
+	if err := m.Train(mygoml.Points{{X: []float64{1, -1}, Y: 0}}); err == nil {
+		t.Error("expected an error on negative counts")
+	}
+	if _, err := m.Predict([]float64{1, 1}); err == nil {
+		t.Error("expected an error from an untrained model")
+	}
+}
+
+func TestFailedFirstBatch(t *testing.T) {
+	models := map[string]interface {
+		mygoml.SupervisedModel
+		PartialFit(mygoml.SupervisedDataSet) error
+	}{
+		"gaussian":    &Gaussian{},
+		"multinomial": &Multinomial{},
+		"complement":  &Complement{},
+		"bernoulli":   &Bernoulli{},
+	}
+	for name, m := range models {
+		t.Run(name, func(t *testing.T) {
+			bad := mygoml.Points{{X: []float64{1, 0}, Y: 0}, {X: []float64{1}, Y: 1}}
+			if err := m.PartialFit(bad); err == nil {
+				t.Fatal("expected an error on a mismatched number of features")
+			}
+			if _, err := m.Predict([]float64{1, 0}); err == nil {
+				t.Error("expected an error from a model whose only batch failed")
+			}
+			good := mygoml.Points{{X: []float64{1}, Y: 0}, {X: []float64{0}, Y: 1}}
+			if err := m.PartialFit(good); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := m.Predict([]float64{1}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
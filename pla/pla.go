@@ -2,96 +2,115 @@ package pla
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
 	"mygoml"
-	"mygoml/graddesc"
 	"mygoml/helpers"
 
 	"gonum.org/v1/gonum/floats"
-
 	"gonum.org/v1/gonum/mat"
 )
 
 type Model struct {
-	weights mat.Matrix
+	Variant Variant
+	// MaxStep is the number of epochs, 100 when 0
+	MaxStep   int
+	weights   mat.Matrix
+	votes     []vote
+	separated bool
+	history   []float64
+}
+
+// Separated reports whether an epoch of the last training went by without
+// any update, meaning the perceptron separated the training data.
+func (p *Model) Separated() bool {
+	return p.separated
 }
 
-func toFloatSlice(m mat.Matrix) []float64 {
-	var data []float64
-	mt := m.T()
-	r, c := mt.Dims()
+// ErrorHistory returns the fraction of misclassified training data points
+// at the end of every epoch of the last training.
+func (p *Model) ErrorHistory() []float64 {
+	return append([]float64(nil), p.history...)
+}
+
+// loss is the perceptron criterion, the sum of max(0, -y*z) over every
+// target of the batch, with Z = Xb * W and targets in {-1, 1}.
+func loss(Xb, Yb, W *mat.Dense) float64 {
+	var Z mat.Dense
+	Z.Mul(Xb, W)
+	r, c := Z.Dims()
+	sum := 0.0
 	for i := 0; i < r; i++ {
 		for j := 0; j < c; j++ {
-			data = append(data, mt.At(i, j))
+			sum = sum + math.Max(0, -Yb.At(i, j)*Z.At(i, j))
 		}
 	}
-	return data
+	return sum
 }
 
-func copyFloats(x []float64) []float64 {
-	n := make([]float64, len(x))
-	copy(n, x)
-	return n
+// gradient adds -y*x to the column of every misclassified target.
+func gradient(Xb, Yb, W *mat.Dense) []float64 {
+	var E mat.Dense
+	E.Mul(Xb, W)
+	E.Apply(func(i, j int, z float64) float64 {
+		if y := Yb.At(i, j); y*z <= 0 {
+			return -y
+		}
+		return 0
+	}, &E)
+	wr, wc := W.Dims()
+	grad := mat.NewDense(wr, wc, nil)
+	grad.Mul(Xb.T(), &E)
+	return grad.RawMatrix().Data
 }
 
 func (p *Model) Train(dataset mygoml.SupervisedDataSet) error {
+	if len(dataset.DataPoints()) == 0 {
+		return mygoml.ErrDatasetEmpty
+	}
 	X, Y := helpers.ConvertSupervisedDataset(dataset, true)
-	wr, xcount := X.Dims()
+	wr, _ := X.Dims()
 	wc, _ := Y.Dims()
-	gradient := func(xi, yi, trueyi []float64) func([]float64) []float64 {
-		return func(w []float64) []float64 {
-			sameSign := floats.EqualFunc(yi, trueyi, func(a, b float64) bool {
-				return a*b > 0
-			})
-			if sameSign {
-				return make([]float64, len(xi))
-			}
-			xVec := mat.NewVecDense(len(xi), copyFloats(xi))
-			wMatrix := mat.NewDense(wr, wc, nil)
-			for i, y := range trueyi {
-				var wi mat.VecDense
-				wi.ScaleVec(-y, xVec)
-				wMatrix.SetCol(i, toFloatSlice(&wi))
-			}
-			return toFloatSlice(wMatrix)
-		}
-	}
 
 	var wData []float64
 	for i := 0; i < wr*wc; i++ {
 		wData = append(wData, rand.Float64()/100+0.1)
 	}
-	W := mat.NewDense(wr, wc, wData)
-
-	var classifiedY mat.Dense
-	classifiedY.Mul(W.T(), X)
-	epochProvider := &graddesc.StochasticProvider{
-		TotalSize: xcount,
-		EpochGen: func(i int) graddesc.Function {
-			xi := mat.Col(nil, i, X)
-			yi := mat.Col(nil, i, &classifiedY)
-			trueyi := mat.Col(nil, i, Y)
 
-			return graddesc.Function{
-				InputSize: wr * wc,
-				Gradient:  gradient(xi, yi, trueyi),
-			}
-		},
-		AfterUpdateFunc: func(w []float64) {
-			W = mat.NewDense(wr, wc, w)
-			classifiedY.Mul(W.T(), X)
-		},
+	// keep one data point per row
+	Xrows := mat.DenseCopyOf(X.T())
+	Yrows := mat.DenseCopyOf(Y.T())
+	n, _ := Xrows.Dims()
+	maxStep := p.MaxStep
+	if maxStep <= 0 {
+		maxStep = 100
 	}
 
-	op := graddesc.Optimizer{
-		EpochProvider: epochProvider,
-		LearningRate:  1,
-		MaxStep:       100,
-		Updater:       &graddesc.BaseUpdater{},
+	// every visit of a misclassified data point moves the weights by minus
+	// the gradient of its perceptron criterion, an epoch without updates
+	// separates the data
+	W := mat.NewDense(wr, wc, wData)
+	t := newTracker(Xrows, Yrows, wr, wc, p.Variant, wData)
+	separated := false
+	for epoch := 0; epoch < maxStep && !separated; epoch++ {
+		separated = true
+		for _, i := range rand.Perm(n) {
+			grad := gradient(helpers.Rows(Xrows, []int{i}), helpers.Rows(Yrows, []int{i}), W)
+			if floats.Norm(grad, math.Inf(1)) == 0 {
+				t.survived()
+				continue
+			}
+			separated = false
+			floats.Sub(wData, grad)
+			t.updated(wData)
+		}
+		t.epochEnd()
 	}
 
-	wData = op.Optimize(wData)
-	p.weights = mat.NewDense(wr, wc, wData)
+	p.weights = mat.NewDense(wr, wc, t.weights())
+	p.votes = t.votes
+	p.separated = separated
+	p.history = t.history
 	return nil
 }
 
@@ -105,12 +124,29 @@ func (p *Model) Predict(features []float64) ([]float64, error) {
 	var result mat.Dense
 	result.Mul(p.weights.T(), featureVector)
 	predicted := mat.Col(nil, 0, &result)
-	for i := 0; i < len(predicted); i++ {
-		if predicted[i] > 0 {
-			predicted[i] = 1
-		} else if predicted[i] < 0 {
-			predicted[i] = -1
+	if p.Variant == Voted && len(p.votes) > 0 {
+		r, c := p.weights.Dims()
+		for i := range predicted {
+			predicted[i] = 0
+		}
+		for _, v := range p.votes {
+			result.Mul(mat.NewDense(r, c, v.weights).T(), featureVector)
+			for i := range predicted {
+				predicted[i] = predicted[i] + v.count*sign(result.At(i, 0))
+			}
 		}
 	}
+	for i := 0; i < len(predicted); i++ {
+		predicted[i] = sign(predicted[i])
+	}
 	return predicted, nil
 }
+
+func sign(x float64) float64 {
+	if x > 0 {
+		return 1
+	} else if x < 0 {
+		return -1
+	}
+	return 0
+}
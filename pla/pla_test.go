@@ -0,0 +1,130 @@
+package pla
+
+import (
+	"math/rand"
+	"mygoml"
+	"mygoml/graddesc"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// blobs draws n points around (-c, -c) labelled -1 and n around (c, c)
+// labelled 1, with unit variance.
+func blobs(rnd *rand.Rand, n int, c float64) mygoml.Points {
+	d := mygoml.Blobs(rnd, n, 1, []float64{-c, -c}, []float64{c, c})
+	for i := range d {
+		d[i].Y = 2*d[i].Y - 1
+	}
+	return d
+}
+
+func TestGradient(t *testing.T) {
+	batchSize, wr, wc := 8, 4, 2
+	w := make([]float64, wr*wc)
+	for i := range w {
+		w[i] = rand.NormFloat64()
+	}
+	W := mat.NewDense(wr, wc, w)
+
+	// the loss has a kink at z = 0, keep every point away from it so that
+	// finite differences stay on one side
+	Xb := mat.NewDense(batchSize, wr, nil)
+	Yb := mat.NewDense(batchSize, wc, nil)
+	for i := 0; i < batchSize; i++ {
+		xi := Xb.RawRowView(i)
+		for awayFromKink := false; !awayFromKink; {
+			for j := 0; j < wr-1; j++ {
+				xi[j] = rand.NormFloat64()
+			}
+			xi[wr-1] = 1
+			awayFromKink = true
+			for j := 0; j < wc; j++ {
+				if z := mat.Dot(mat.NewVecDense(wr, xi), W.ColView(j)); z > -0.01 && z < 0.01 {
+					awayFromKink = false
+				}
+			}
+		}
+		for j := 0; j < wc; j++ {
+			Yb.Set(i, j, float64(2*rand.Intn(2)-1))
+		}
+	}
+
+	f := graddesc.Function{
+		InputSize: wr * wc,
+		Mapper: func(w []float64) []float64 {
+			return []float64{loss(Xb, Yb, mat.NewDense(wr, wc, w))}
+		},
+		Gradient: func(w []float64) []float64 {
+			return gradient(Xb, Yb, mat.NewDense(wr, wc, w))
+		},
+	}
+	check, err := graddesc.CheckGradient(f, w, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, i := check.MaxRelativeError(); e > 1e-6 {
+		t.Errorf("relative error %g at %d: analytic %v, numeric %v", e, i, check.Analytic, check.Numeric)
+	}
+}
+
+func TestVariants(t *testing.T) {
+	rand.Seed(1)
+	variants := map[string]Variant{
+		"standard": Standard,
+		"pocket":   Pocket,
+		"averaged": Averaged,
+		"voted":    Voted,
+	}
+
+	t.Run("separable", func(t *testing.T) {
+		d := blobs(rand.New(rand.NewSource(2)), 50, 5)
+		for name, variant := range variants {
+			t.Run(name, func(t *testing.T) {
+				m := &Model{Variant: variant}
+				if err := m.Train(d); err != nil {
+					t.Fatal(err)
+				}
+				if !m.Separated() {
+					t.Error("expected the data to be separated")
+				}
+				history := m.ErrorHistory()
+				if len(history) == 0 || history[len(history)-1] != 0 {
+					t.Errorf("expected the error history to end with 0, got %v", history)
+				}
+				if e := 1 - mygoml.ModelAccuracy(t, m, d); e != 0 {
+					t.Errorf("expected no training error, got %g", e)
+				}
+				if variant != Voted {
+					return
+				}
+				// every visit of every epoch is credited to one vote
+				visits := 0.0
+				for _, v := range m.votes {
+					visits = visits + v.count
+				}
+				if expected := float64(len(d) * len(history)); visits != expected {
+					t.Errorf("expected %g visits in the votes, got %g", expected, visits)
+				}
+			})
+		}
+	})
+
+	t.Run("not separable", func(t *testing.T) {
+		d := blobs(rand.New(rand.NewSource(3)), 100, 0.8)
+		errors := map[Variant]float64{}
+		for _, variant := range []Variant{Standard, Pocket} {
+			m := &Model{Variant: variant, MaxStep: 50}
+			if err := m.Train(d); err != nil {
+				t.Fatal(err)
+			}
+			if m.Separated() {
+				t.Errorf("%v: expected the data not to be separated", variant)
+			}
+			errors[variant] = 1 - mygoml.ModelAccuracy(t, m, d)
+		}
+		if errors[Pocket] > errors[Standard] {
+			t.Errorf("expected the pocket error %g to be at most the standard error %g", errors[Pocket], errors[Standard])
+		}
+	})
+}
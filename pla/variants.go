@@ -0,0 +1,151 @@
+package pla
+
+import (
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Variant chooses which weights a trained perceptron predicts with.
+type Variant int
+
+const (
+	// Standard keeps the weights of the last update
+	Standard Variant = iota
+	// Pocket keeps the weights with the fewest training errors among those
+	// scored, which suits data that is not linearly separable
+	Pocket
+	// Averaged keeps the average of the weights after every visited data
+	// point
+	Averaged
+	// Voted keeps every weight vector and lets them vote, each weighted by
+	// the number of data points it survived
+	Voted
+)
+
+type vote struct {
+	weights []float64
+	count   float64
+}
+
+// tracker follows the visits and updates of Train to keep the pocket,
+// averaged and voted weights and the training error history.
+type tracker struct {
+	variant Variant
+	// X and Y hold one data point per row, W is wr x wc
+	X, Y   *mat.Dense
+	wr, wc int
+
+	// current are the weights since the last update, count the visits they
+	// survived, the visit of that update included, and run the data points
+	// they classified right in a row
+	current []float64
+	count   int
+	run     int
+	scored  bool
+
+	sum     []float64
+	total   float64
+	votes   []vote
+	history []float64
+
+	best       []float64
+	bestErrors int
+	bestRun    int
+}
+
+func newTracker(X, Y *mat.Dense, wr, wc int, variant Variant, w []float64) *tracker {
+	t := &tracker{variant: variant, X: X, Y: Y, wr: wr, wc: wc}
+	t.current = append([]float64(nil), w...)
+	t.sum = make([]float64, len(w))
+	if variant == Pocket {
+		t.best = append([]float64(nil), w...)
+		t.bestErrors = t.errors(w)
+	}
+	return t
+}
+
+// errors counts the data points with a misclassified target.
+func (t *tracker) errors(w []float64) int {
+	var Z mat.Dense
+	Z.Mul(t.X, mat.NewDense(t.wr, t.wc, w))
+	n, _ := t.X.Dims()
+	count := 0
+	for i := 0; i < n; i++ {
+		for j := 0; j < t.wc; j++ {
+			if t.Y.At(i, j)*Z.At(i, j) <= 0 {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
+
+// pocket replaces the pocket weights by the current ones when they make
+// fewer errors.
+func (t *tracker) pocket(errors int) {
+	if errors < t.bestErrors {
+		copy(t.best, t.current)
+		t.bestErrors, t.bestRun = errors, t.run
+	}
+}
+
+// survived records a visited data point the current weights classify right.
+// Pocket weights follow Gallant's ratchet: the current weights are scored
+// once, when their run of right classifications outlasts the pocket's.
+func (t *tracker) survived() {
+	t.count++
+	t.run++
+	if t.variant == Pocket && !t.scored && t.run > t.bestRun {
+		t.scored = true
+		t.pocket(t.errors(t.current))
+	}
+}
+
+// updated records an update to the weights w, crediting the visit that
+// caused it to the new weights.
+func (t *tracker) updated(w []float64) {
+	t.commit()
+	t.current = append([]float64(nil), w...)
+	t.count, t.run, t.scored = 1, 0, false
+}
+
+// epochEnd records the training error of the current weights.
+func (t *tracker) epochEnd() {
+	errors := t.errors(t.current)
+	n, _ := t.X.Dims()
+	t.history = append(t.history, float64(errors)/float64(n))
+	if t.variant == Pocket {
+		t.pocket(errors)
+	}
+}
+
+// commit credits the current weights with the visits they survived.
+func (t *tracker) commit() {
+	if t.count == 0 {
+		return
+	}
+	floats.AddScaled(t.sum, float64(t.count), t.current)
+	t.total = t.total + float64(t.count)
+	if t.variant == Voted {
+		t.votes = append(t.votes, vote{weights: t.current, count: float64(t.count)})
+	}
+	t.count = 0
+}
+
+// weights credits the last weights and returns the weights the variant
+// predicts with.
+func (t *tracker) weights() []float64 {
+	t.commit()
+	switch t.variant {
+	case Pocket:
+		return t.best
+	case Averaged:
+		if t.total > 0 {
+			avg := make([]float64, len(t.sum))
+			floats.ScaleTo(avg, 1/t.total, t.sum)
+			return avg
+		}
+	}
+	return t.current
+}
@@ -0,0 +1,80 @@
+package regularization
+
+type Type int
+
+const (
+	None Type = iota
+	L1
+	L2
+	ElasticNet
+)
+
+// Penalty describes the term added to a loss function:
+//
+//	L1Strength() * |w|_1 + L2Strength() / 2 * |w|_2^2
+//
+// L1Ratio is only used by ElasticNet and mixes the two terms.
+type Penalty struct {
+	Type    Type
+	Alpha   float64
+	L1Ratio float64
+}
+
+func (p Penalty) L1Strength() float64 {
+	switch p.Type {
+	case L1:
+		return p.Alpha
+	case ElasticNet:
+		return p.Alpha * p.L1Ratio
+	}
+	return 0
+}
+
+func (p Penalty) L2Strength() float64 {
+	switch p.Type {
+	case L2:
+		return p.Alpha
+	case ElasticNet:
+		return p.Alpha * (1 - p.L1Ratio)
+	}
+	return 0
+}
+
+func (p Penalty) Value(w []float64) float64 {
+	l1, l2 := p.L1Strength(), p.L2Strength()
+	if l1 == 0 && l2 == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range w {
+		sum = sum + l1*abs(v) + l2/2*v*v
+	}
+	return sum
+}
+
+// AddGradient adds the (sub)gradient of the penalty at w to grad.
+func (p Penalty) AddGradient(grad, w []float64) {
+	l1, l2 := p.L1Strength(), p.L2Strength()
+	if l1 == 0 && l2 == 0 {
+		return
+	}
+	for i, v := range w {
+		grad[i] = grad[i] + l1*sign(v) + l2*v
+	}
+}
+
+func abs(x float64) float64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func sign(x float64) float64 {
+	if x > 0 {
+		return 1
+	} else if x < 0 {
+		return -1
+	}
+	return 0
+}
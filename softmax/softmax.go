@@ -6,6 +6,7 @@ import (
 	"mygoml"
 	"mygoml/graddesc"
 	"mygoml/helpers"
+	"mygoml/regularization"
 
 	"gonum.org/v1/gonum/floats"
 
@@ -13,19 +14,24 @@ import (
 )
 
 type Model struct {
-	weights mat.Matrix
+	Regularization regularization.Penalty
+	// ClassWeights scales the loss of each class, indexed like the targets
+	ClassWeights []float64
+	// SampleWeights scales the loss of each data point, in dataset order
+	SampleWeights []float64
+	// BatchSize is the number of data points per update, 0 picks one
+	// from the size of the data set
+	BatchSize int
+	// Minimizer, when set, replaces gradient descent and minimizes the
+	// full-batch loss, e.g. with graddesc.LBFGSMinimizer
+	Minimizer graddesc.Minimizer
+	weights   mat.Matrix
 }
 
 func (m *Model) Weights() mat.Matrix {
 	return mat.DenseCopyOf(m.weights)
 }
 
-func copyFloats(x []float64) []float64 {
-	n := make([]float64, len(x))
-	copy(n, x)
-	return n
-}
-
 func softmax(z []float64) []float64 {
 	max := floats.Max(z)
 	temp := make([]float64, len(z))
@@ -40,74 +46,178 @@ func softmax(z []float64) []float64 {
 	return out
 }
 
-func toFloatSlice(m mat.Matrix) []float64 {
-	var data []float64
-	mt := m.T()
-	r, c := mt.Dims()
-	for i := 0; i < r; i++ {
-		for j := 0; j < c; j++ {
-			data = append(data, mt.At(i, j))
+// logSumExp computes log(sum(exp(z))) without overflow.
+func logSumExp(z []float64) float64 {
+	max := floats.Max(z)
+	sum := 0.0
+	for _, v := range z {
+		sum = sum + math.Exp(v-max)
+	}
+	return max + math.Log(sum)
+}
+
+// classWeight weighs a one-hot (or soft) target by its class weights.
+func (m *Model) classWeight(y []float64) float64 {
+	if m.ClassWeights == nil {
+		return 1
+	}
+	return floats.Dot(y, m.ClassWeights)
+}
+
+// loss is the weighted cross-entropy over a batch plus the penalty,
+// Xb and Yb holding one data point per row and sw their sample weights.
+func (m *Model) loss(Xb, Yb *mat.Dense, sw []float64, W *mat.Dense) float64 {
+	wr, wc := W.Dims()
+	batchSize, _ := Xb.Dims()
+
+	var Z mat.Dense
+	Z.Mul(Xb, W)
+	sum := 0.0
+	for i := 0; i < batchSize; i++ {
+		zi := Z.RawRowView(i)
+		yi := Yb.RawRowView(i)
+		// log(softmax(z)_j) = z_j - logsumexp(z) stays finite when the
+		// softmax of a class underflows to zero
+		lse := logSumExp(zi)
+		ce := 0.0
+		for j := range zi {
+			ce = ce - yi[j]*(zi[j]-lse)
 		}
+		sum = sum + ce*sw[i]*m.classWeight(yi)
+	}
+	return sum/float64(batchSize) + m.Regularization.Value(W.RawMatrix().Data[:(wr-1)*wc])
+}
+
+// gradient computes dL/dW over a batch, Xb and Yb holding one data point
+// per row and sw their sample weights.
+func (m *Model) gradient(Xb, Yb *mat.Dense, sw []float64, W *mat.Dense) []float64 {
+	wr, wc := W.Dims()
+	batchSize, _ := Xb.Dims()
+
+	// Z = Xb * W
+	var E mat.Dense
+	E.Mul(Xb, W)
+
+	// E = softmax(Z) - Yb, row by row
+	for i := 0; i < batchSize; i++ {
+		ei := E.RawRowView(i)
+		yi := Yb.RawRowView(i)
+		copy(ei, softmax(ei))
+		floats.Sub(ei, yi)
+		floats.Scale(sw[i]*m.classWeight(yi), ei)
 	}
+
+	// dL/dW = Xb^t * E / batchSize
+	grad := mat.NewDense(wr, wc, nil)
+	grad.Mul(Xb.T(), &E)
+	grad.Scale(1/float64(batchSize), grad)
+
+	// the last row of W holds the bias and is not penalized
+	data := grad.RawMatrix().Data
+	m.Regularization.AddGradient(data[:(wr-1)*wc], W.RawMatrix().Data[:(wr-1)*wc])
 	return data
 }
 
+// hessian computes d2L/dW2 over a batch, ordered like the flattened W.
+// Only the L2 part of the penalty has curvature.
+func (m *Model) hessian(Xb, Yb *mat.Dense, sw []float64, W *mat.Dense) *mat.SymDense {
+	wr, wc := W.Dims()
+	batchSize, _ := Xb.Dims()
+
+	var Z mat.Dense
+	Z.Mul(Xb, W)
+	hessian := mat.NewSymDense(wr*wc, nil)
+	for i := 0; i < batchSize; i++ {
+		xi := Xb.RawRowView(i)
+		ai := softmax(Z.RawRowView(i))
+		c := sw[i] * m.classWeight(Yb.RawRowView(i)) / float64(batchSize)
+		for k := 0; k < wc; k++ {
+			for n := 0; n < wc; n++ {
+				// d(softmax_k)/d(z_n)
+				s := -ai[k] * ai[n]
+				if k == n {
+					s = s + ai[k]
+				}
+				for j := 0; j < wr; j++ {
+					for l := 0; l < wr; l++ {
+						p, q := j*wc+k, l*wc+n
+						if p <= q {
+							hessian.SetSym(p, q, hessian.At(p, q)+c*s*xi[j]*xi[l])
+						}
+					}
+				}
+			}
+		}
+	}
+	l2 := m.Regularization.L2Strength()
+	for p := 0; p < (wr-1)*wc; p++ {
+		hessian.SetSym(p, p, hessian.At(p, p)+l2)
+	}
+	return hessian
+}
+
 func (m *Model) Train(dataset mygoml.SupervisedDataSet) error {
+	if len(dataset.DataPoints()) == 0 {
+		return mygoml.ErrDatasetEmpty
+	}
 	X, Y := helpers.ConvertSupervisedDataset(dataset, true)
 	wr, xcount := X.Dims()
 	wc, _ := Y.Dims()
+	if m.ClassWeights != nil && len(m.ClassWeights) != wc {
+		msg := fmt.Sprintf("model expects %d class weights but got %d", wc, len(m.ClassWeights))
+		return mygoml.ErrIncompatibleDataAndModel(msg)
+	}
+	if m.SampleWeights != nil && len(m.SampleWeights) != xcount {
+		msg := fmt.Sprintf("model expects %d sample weights but got %d", xcount, len(m.SampleWeights))
+		return mygoml.ErrIncompatibleDataAndModel(msg)
+	}
 
 	var wData []float64
 	for i := 0; i < wr*wc; i++ {
 		wData = append(wData, float64(i+1))
 	}
-	wMatrix := mat.NewDense(wr, wc, wData)
 
-	gradient := func(xi, yi []float64, w mat.Matrix) []float64 {
-		xivec := mat.NewVecDense(len(xi), copyFloats(xi))
-
-		// zi = W^t * xi
-		var zivec mat.VecDense
-		zivec.MulVec(w.T(), xivec)
-		zival := mat.Col(nil, 0, &zivec)
-
-		// ai = softmax(zi)
-		ai := softmax(zival)
-
-		// ei = ai - yi
-		ei := make([]float64, len(ai))
-		floats.SubTo(ei, ai, yi)
-		eivec := mat.NewVecDense(len(ei), ei)
-
-		// dL/dW = xi*ei^t
-		dW := mat.NewDense(wr, wc, nil)
-		dW.Mul(xivec, eivec.T())
+	// keep one data point per row so that a batch is a set of rows
+	Xrows := mat.DenseCopyOf(X.T())
+	Yrows := mat.DenseCopyOf(Y.T())
+	gen := func(indices []int) graddesc.Function {
+		Xb := helpers.Rows(Xrows, indices)
+		Yb := helpers.Rows(Yrows, indices)
+		sw := make([]float64, len(indices))
+		for k, i := range indices {
+			sw[k] = 1
+			if m.SampleWeights != nil {
+				sw[k] = m.SampleWeights[i]
+			}
+		}
 
-		return toFloatSlice(dW.T())
+		return graddesc.Function{
+			InputSize: wr * wc,
+			Mapper: func(w []float64) []float64 {
+				return []float64{m.loss(Xb, Yb, sw, mat.NewDense(wr, wc, w))}
+			},
+			Gradient: func(w []float64) []float64 {
+				return m.gradient(Xb, Yb, sw, mat.NewDense(wr, wc, w))
+			},
+			Hessian: func(w []float64) *mat.SymDense {
+				return m.hessian(Xb, Yb, sw, mat.NewDense(wr, wc, w))
+			},
+		}
 	}
 
-	epochProvider := &graddesc.StochasticProvider{
-		TotalSize: xcount,
-		EpochGen: func(i int) graddesc.Function {
-			xi := mat.Col(nil, i, X)
-			yi := mat.Col(nil, i, Y)
-
-			return graddesc.Function{
-				InputSize: wr * wc,
-				Gradient: func(w []float64) []float64 {
-					grad := gradient(xi, yi, wMatrix)
-					return grad
-				},
-			}
-		},
-		AfterUpdateFunc: func(w []float64) {
-			for r := 0; r < wr; r++ {
-				for c := 0; c < wc; c++ {
-					wMatrix.Set(r, c, w[r*wc+c])
-				}
-			}
-		},
+	if m.Minimizer != nil {
+		indices := make([]int, xcount)
+		for i := range indices {
+			indices[i] = i
+		}
+		result, err := m.Minimizer.Minimize(gen(indices), wData)
+		if result.X == nil {
+			return err
+		}
+		m.weights = mat.NewDense(wr, wc, result.X)
+		return err
 	}
+	epochProvider := helpers.NewEpochProvider(xcount, m.BatchSize, gen)
 
 	op := graddesc.Optimizer{
 		EpochProvider: epochProvider,
@@ -116,10 +226,12 @@ func (m *Model) Train(dataset mygoml.SupervisedDataSet) error {
 		Updater:       &graddesc.BaseUpdater{},
 	}
 
-	wData = op.Optimize(wData)
-	m.weights = mat.NewDense(wr, wc, wData)
-
-	return nil
+	result, err := op.Optimize(wData)
+	if result.X == nil {
+		return err
+	}
+	m.weights = mat.NewDense(wr, wc, result.X)
+	return err
 }
 
 func (m *Model) Predict(features []float64) ([]float64, error) {
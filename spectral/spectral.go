@@ -0,0 +1,217 @@
+package spectral
+
+import (
+	"fmt"
+	"math"
+	"mygoml"
+	"mygoml/kmeans"
+	"sort"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Affinity builds the similarity graph of the data points.
+type Affinity int
+
+const (
+	// RBF links every pair of data points with weight exp(-gamma*d²)
+	RBF Affinity = iota
+	// NearestNeighbors links every data point to its nearest neighbors
+	// with weight 1, the weights being averaged both ways
+	NearestNeighbors
+)
+
+func (a Affinity) String() string {
+	switch a {
+	case RBF:
+		return "rbf"
+	case NearestNeighbors:
+		return "nearest neighbors"
+	}
+	return fmt.Sprintf("Affinity(%d)", int(a))
+}
+
+// Model embeds the data points with the eigenvectors of the normalized
+// Laplacian of their similarity graph and clusters the embedding with
+// kmeans. It finds clusters of any shape as long as they are well
+// connected, but takes memory quadratic and time cubic in the number of
+// data points.
+type Model struct {
+	// ClusterCount is the number of clusters, 2 when 0
+	ClusterCount int
+	Affinity     Affinity
+	// Gamma scales the squared distances of RBF, 1 when 0
+	Gamma float64
+	// Neighbors is the number of neighbors of NearestNeighbors, 10 when 0
+	Neighbors int
+	// Seed picks the initial centers of kmeans reproducibly, a time based
+	// seed is used when 0
+	Seed      int64
+	embedding [][]float64
+	labels    []int
+}
+
+// embedded is a data point of the embedding that remembers its position
+// in the data set.
+type embedded struct {
+	coordinates []float64
+	i           int
+}
+
+func (e embedded) Features() []float64 {
+	// kmeans writes to the features it is given
+	return append([]float64(nil), e.coordinates...)
+}
+
+type embedding []embedded
+
+func (e embedding) DataPoints() []mygoml.UnsupervisedDataPoint {
+	var out []mygoml.UnsupervisedDataPoint
+	for _, v := range e {
+		out = append(out, v)
+	}
+	return out
+}
+
+// Clustering returns ClusterCount clusters of ds.
+func (m *Model) Clustering(ds mygoml.UnsupervisedDataSet) []mygoml.Cluster {
+	dps := ds.DataPoints()
+	m.embedding, m.labels = nil, nil
+	n := len(dps)
+	if n == 0 {
+		return nil
+	}
+	k := m.ClusterCount
+	if k <= 0 {
+		k = 2
+	}
+	if k > n {
+		k = n
+	}
+	X := make([][]float64, n)
+	for i, dp := range dps {
+		X[i] = dp.Features()
+	}
+
+	// D^-1/2 W D^-1/2 has the eigenvectors of the normalized Laplacian
+	// I - D^-1/2 W D^-1/2, its largest eigenvalues being the smallest of
+	// the Laplacian
+	W := m.affinity(X)
+	scale := make([]float64, n)
+	for i := range scale {
+		degree := 0.0
+		for j := 0; j < n; j++ {
+			degree = degree + W.At(i, j)
+		}
+		if degree > 0 {
+			scale[i] = 1 / math.Sqrt(degree)
+		}
+	}
+	normalized := mat.NewSymDense(n, nil)
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			normalized.SetSym(i, j, scale[i]*W.At(i, j)*scale[j])
+		}
+	}
+	var eig mat.EigenSym
+	if !eig.Factorize(normalized, true) {
+		return nil
+	}
+	var vectors mat.Dense
+	eig.VectorsTo(&vectors)
+
+	// rows of the top k eigenvectors, normalized to unit length
+	values := eig.Values(nil)
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return values[order[a]] > values[order[b]]
+	})
+	m.embedding = make([][]float64, n)
+	points := make(embedding, n)
+	for i := range m.embedding {
+		row := make([]float64, k)
+		for c := 0; c < k; c++ {
+			row[c] = vectors.At(i, order[c])
+		}
+		if norm := floats.Norm(row, 2); norm > 0 {
+			floats.Scale(1/norm, row)
+		}
+		m.embedding[i] = row
+		points[i] = embedded{coordinates: row, i: i}
+	}
+
+	km := kmeans.Model{ClusterCount: k, Seed: m.Seed}
+	m.labels = make([]int, n)
+	var out []mygoml.Cluster
+	for c, kc := range km.Clustering(points) {
+		cluster := &mygoml.BasicCluster{}
+		for _, member := range kc.Members() {
+			i := member.(embedded).i
+			m.labels[i] = c
+			cluster.Add(dps[i])
+		}
+		out = append(out, cluster)
+	}
+	return out
+}
+
+// affinity returns the weights of the similarity graph of X, without
+// self loops.
+func (m *Model) affinity(X [][]float64) *mat.SymDense {
+	n := len(X)
+	W := mat.NewSymDense(n, nil)
+	if m.Affinity == NearestNeighbors {
+		neighbors := m.Neighbors
+		if neighbors <= 0 {
+			neighbors = 10
+		}
+		if neighbors > n-1 {
+			neighbors = n - 1
+		}
+		others := make([]int, 0, n-1)
+		distances := make([]float64, n)
+		for i := range X {
+			others = others[:0]
+			for j := range X {
+				distances[j] = floats.Distance(X[i], X[j], 2)
+				if j != i {
+					others = append(others, j)
+				}
+			}
+			sort.SliceStable(others, func(a, b int) bool {
+				return distances[others[a]] < distances[others[b]]
+			})
+			for _, j := range others[:neighbors] {
+				W.SetSym(i, j, W.At(i, j)+0.5)
+			}
+		}
+		return W
+	}
+
+	gamma := m.Gamma
+	if gamma <= 0 {
+		gamma = 1
+	}
+	for i := range X {
+		for j := i + 1; j < n; j++ {
+			d := floats.Distance(X[i], X[j], 2)
+			W.SetSym(i, j, math.Exp(-gamma*d*d))
+		}
+	}
+	return W
+}
+
+// Embedding returns the spectral embedding of the data points of the last
+// clustering, which kmeans clustered.
+func (m *Model) Embedding() [][]float64 {
+	return m.embedding
+}
+
+// Labels returns the cluster of every data point of the last clustering.
+func (m *Model) Labels() []int {
+	return m.labels
+}
@@ -0,0 +1,51 @@
+package spectral
+
+import (
+	"math"
+	"mygoml"
+	"testing"
+)
+
+// rings returns 20 data points on a circle of radius 1 followed by 40 on
+// a concentric circle of radius 5, which no center based model separates.
+func rings() mygoml.Samples {
+	var d mygoml.Samples
+	for _, ring := range []struct {
+		n      int
+		radius float64
+	}{{20, 1}, {40, 5}} {
+		for i := 0; i < ring.n; i++ {
+			angle := 2 * math.Pi * float64(i) / float64(ring.n)
+			d = append(d, mygoml.Sample{ring.radius * math.Cos(angle), ring.radius * math.Sin(angle)})
+		}
+	}
+	return d
+}
+
+func TestClustering(t *testing.T) {
+	models := map[string]*Model{
+		"rbf":               {Gamma: 1, Seed: 1},
+		"nearest neighbors": {Affinity: NearestNeighbors, Neighbors: 3, Seed: 1},
+	}
+	for name, m := range models {
+		t.Run(name, func(t *testing.T) {
+			clusters := m.Clustering(rings())
+			if len(clusters) != 2 {
+				t.Fatalf("expected 2 clusters, got %d", len(clusters))
+			}
+			labels := m.Labels()
+			for i := range labels {
+				inner := i < 20
+				if (labels[i] == labels[0]) != inner {
+					t.Fatalf("expected a cluster per ring, got labels %v", labels)
+				}
+			}
+			if sizes := []int{len(clusters[labels[0]].Members()), len(clusters[labels[20]].Members())}; sizes[0] != 20 || sizes[1] != 40 {
+				t.Errorf("expected clusters of 20 and 40 data points, got %v", sizes)
+			}
+			if len(m.Embedding()) != 60 || len(m.Embedding()[0]) != 2 {
+				t.Errorf("expected a 60x2 embedding, got %d rows", len(m.Embedding()))
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package svm
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// Kernel is an inner product between two feature vectors in some feature
+// space.
+type Kernel func(a, b []float64) float64
+
+// LinearKernel is the plain dot product a·b.
+func LinearKernel() Kernel {
+	return func(a, b []float64) float64 {
+		return floats.Dot(a, b)
+	}
+}
+
+// PolynomialKernel is (gamma*a·b + coef0)^degree.
+func PolynomialKernel(degree int, gamma, coef0 float64) Kernel {
+	return func(a, b []float64) float64 {
+		return math.Pow(gamma*floats.Dot(a, b)+coef0, float64(degree))
+	}
+}
+
+// RBFKernel is exp(-gamma*|a-b|²).
+func RBFKernel(gamma float64) Kernel {
+	return func(a, b []float64) float64 {
+		sum := 0.0
+		for i := range a {
+			d := a[i] - b[i]
+			sum = sum + d*d
+		}
+		return math.Exp(-gamma * sum)
+	}
+}
+
+// SigmoidKernel is tanh(gamma*a·b + coef0).
+func SigmoidKernel(gamma, coef0 float64) Kernel {
+	return func(a, b []float64) float64 {
+		return math.Tanh(gamma*floats.Dot(a, b) + coef0)
+	}
+}
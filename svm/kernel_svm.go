@@ -0,0 +1,222 @@
+package svm
+
+import (
+	"math"
+	"mygoml"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// KernelModel is a soft-margin kernel SVM whose dual problem
+//
+//	min ½·ΣΣ αi·αj·yi·yj·K(xi, xj) - Σ αi,  0 ≤ αi ≤ C,  Σ αi·yi = 0
+//
+// is solved by SMO, updating the maximal violating pair of multipliers at
+// every iteration.
+type KernelModel struct {
+	// Kernel defaults to RBFKernel with gamma 1/features
+	Kernel Kernel
+	// C trades margin width for training errors, 1 when 0
+	C float64
+	// Tolerance on the optimality gap, 1e-3 when 0
+	Tolerance float64
+	// MaxIter caps the SMO iterations, 100 per data point with a minimum
+	// of 10000 when 0. Train returns mygoml.ErrNotConverged, keeping the
+	// model, when a binary problem reaches it before the tolerance
+	MaxIter  int
+	Strategy Strategy
+	mc       multiclass
+}
+
+// kernelBinary is a trained binary kernel SVM keeping only its support
+// vectors, coefficients holding αi·yi.
+type kernelBinary struct {
+	kernel       Kernel
+	vectors      [][]float64
+	coefficients []float64
+	bias         float64
+	converged    bool
+}
+
+func (k *kernelBinary) decision(x []float64) float64 {
+	sum := k.bias
+	for i, v := range k.vectors {
+		sum = sum + k.coefficients[i]*k.kernel(v, x)
+	}
+	return sum
+}
+
+func (m *KernelModel) fit(X [][]float64, y []float64) (binary, error) {
+	n := len(X)
+	kernel := m.Kernel
+	if kernel == nil {
+		kernel = RBFKernel(1 / float64(len(X[0])))
+	}
+	C := m.C
+	if C <= 0 {
+		C = 1
+	}
+	tol := m.Tolerance
+	if tol <= 0 {
+		tol = 1e-3
+	}
+	maxIter := m.MaxIter
+	if maxIter <= 0 {
+		maxIter = int(math.Max(10000, 100*float64(n)))
+	}
+
+	K := mat.NewSymDense(n, nil)
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			K.SetSym(i, j, kernel(X[i], X[j]))
+		}
+	}
+
+	// G is the gradient of the dual objective, Σj αj·yi·yj·Kij - 1
+	alpha := make([]float64, n)
+	G := make([]float64, n)
+	for i := range G {
+		G[i] = -1
+	}
+	up := func(t int) bool {
+		return y[t] > 0 && alpha[t] < C || y[t] < 0 && alpha[t] > 0
+	}
+	low := func(t int) bool {
+		return y[t] > 0 && alpha[t] > 0 || y[t] < 0 && alpha[t] < C
+	}
+
+	// snap puts multipliers rounded to within a hair of a bound on it,
+	// otherwise they would be picked again with no room to move
+	snap := func(a float64) float64 {
+		if a < 1e-12*C {
+			return 0
+		}
+		if a > C*(1-1e-12) {
+			return C
+		}
+		return a
+	}
+
+	// violating returns the pair violating the optimality conditions the
+	// most, with their values of -y·G
+	violating := func() (i, j int, upper, lower float64) {
+		i, j = -1, -1
+		upper, lower = math.Inf(-1), math.Inf(1)
+		for t := 0; t < n; t++ {
+			v := -y[t] * G[t]
+			if up(t) && v > upper {
+				i, upper = t, v
+			}
+			if low(t) && v < lower {
+				j, lower = t, v
+			}
+		}
+		return i, j, upper, lower
+	}
+
+	var upper, lower float64
+	converged := false
+	for iter := 0; ; iter++ {
+		var i, j int
+		i, j, upper, lower = violating()
+		if i < 0 || j < 0 || upper-lower < tol {
+			converged = true
+			break
+		}
+		if iter == maxIter {
+			break
+		}
+
+		eta := K.At(i, i) + K.At(j, j) - 2*K.At(i, j)
+		if eta <= 0 {
+			eta = 1e-12
+		}
+		var L, H float64
+		if y[i] != y[j] {
+			L, H = math.Max(0, alpha[j]-alpha[i]), math.Min(C, C+alpha[j]-alpha[i])
+		} else {
+			L, H = math.Max(0, alpha[i]+alpha[j]-C), math.Min(C, alpha[i]+alpha[j])
+		}
+		aj := snap(math.Max(L, math.Min(H, alpha[j]-y[j]*(upper-lower)/eta)))
+		ai := snap(alpha[i] + y[i]*y[j]*(alpha[j]-aj))
+		di, dj := ai-alpha[i], aj-alpha[j]
+		alpha[i], alpha[j] = ai, aj
+		for t := 0; t < n; t++ {
+			G[t] = G[t] + y[t]*(y[i]*K.At(t, i)*di+y[j]*K.At(t, j)*dj)
+		}
+	}
+
+	// the bias averages -yi·Gi over the free multipliers, or lies in the
+	// middle of the feasible interval when there are none
+	model := &kernelBinary{kernel: kernel, converged: converged}
+	sum, free := 0.0, 0
+	for t := 0; t < n; t++ {
+		if alpha[t] > 0 && alpha[t] < C {
+			sum = sum - y[t]*G[t]
+			free++
+		}
+	}
+	if free > 0 {
+		model.bias = sum / float64(free)
+	} else {
+		switch {
+		case math.IsInf(upper, -1):
+			model.bias = lower
+		case math.IsInf(lower, 1):
+			model.bias = upper
+		default:
+			model.bias = (upper + lower) / 2
+		}
+	}
+	for t := 0; t < n; t++ {
+		if alpha[t] > 0 {
+			model.vectors = append(model.vectors, X[t])
+			model.coefficients = append(model.coefficients, alpha[t]*y[t])
+		}
+	}
+	return model, nil
+}
+
+func (m *KernelModel) Train(dataset mygoml.SupervisedDataSet) error {
+	if err := m.mc.train(dataset, m.Strategy, m.fit); err != nil {
+		return err
+	}
+	if !m.Converged() {
+		return mygoml.ErrNotConverged
+	}
+	return nil
+}
+
+// Converged reports whether SMO reached the tolerance on every binary
+// problem of the last training.
+func (m *KernelModel) Converged() bool {
+	for _, model := range m.mc.models {
+		if !model.(*kernelBinary).converged {
+			return false
+		}
+	}
+	return true
+}
+
+// Predict returns the predicted class label.
+func (m *KernelModel) Predict(features []float64) ([]float64, error) {
+	return m.mc.predict(features)
+}
+
+// DecisionFunction returns the score of every binary classifier: one value
+// for two classes, positive for the larger one, one per class for
+// OneVsRest and one per pair of classes for OneVsOne, positive for the
+// larger class of the pair and ordered like the pairs of ensemble.OneVsOne.
+func (m *KernelModel) DecisionFunction(features []float64) ([]float64, error) {
+	return m.mc.decision(features)
+}
+
+// SupportVectors returns the number of support vectors of every binary
+// classifier.
+func (m *KernelModel) SupportVectors() []int {
+	out := make([]int, len(m.mc.models))
+	for i, model := range m.mc.models {
+		out[i] = len(model.(*kernelBinary).vectors)
+	}
+	return out
+}
@@ -0,0 +1,99 @@
+package svm
+
+import (
+	"math"
+	"math/rand"
+	"mygoml"
+	"testing"
+)
+
+// blobs draws n points around (-c, -c) labelled -1 and n around (c, c)
+// labelled 1, with standard deviation sd.
+func blobs(rnd *rand.Rand, n int, c, sd float64) mygoml.Points {
+	d := mygoml.Blobs(rnd, n, sd, []float64{-c, -c}, []float64{c, c})
+	for i := range d {
+		d[i].Y = 2*d[i].Y - 1
+	}
+	return d
+}
+
+// xor draws n points around every corner of the square (±1, ±1), labelled
+// with the sign of the product of their coordinates.
+func xor(rnd *rand.Rand, n int) mygoml.Points {
+	var d mygoml.Points
+	for i := 0; i < n; i++ {
+		for _, corner := range [][2]float64{{-1, -1}, {-1, 1}, {1, -1}, {1, 1}} {
+			x := []float64{corner[0] + 0.2*rnd.NormFloat64(), corner[1] + 0.2*rnd.NormFloat64()}
+			d = append(d, mygoml.Point{X: x, Y: corner[0] * corner[1]})
+		}
+	}
+	return d
+}
+
+func TestKernelSeparable(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	d := blobs(rnd, 50, 3, 0.5)
+	m := &KernelModel{Kernel: LinearKernel(), C: 100}
+	if err := m.Train(d); err != nil {
+		t.Fatal(err)
+	}
+	if !m.Converged() {
+		t.Error("SMO did not converge")
+	}
+	mygoml.FloatEqual(t, "accuracy", 1, mygoml.ModelAccuracy(t, m, d))
+
+	// a hard margin puts the support vectors of both classes on ±1
+	model := m.mc.models[0].(*kernelBinary)
+	for i, v := range model.vectors {
+		y := math.Copysign(1, model.coefficients[i])
+		if got := model.decision(v); math.Abs(got-y) > 1e-2 {
+			t.Errorf("support vector %v has decision %f, expected %f", v, got, y)
+		}
+	}
+}
+
+func TestKernelXOR(t *testing.T) {
+	rnd := rand.New(rand.NewSource(2))
+	d := xor(rnd, 25)
+	t.Run("rbf", func(t *testing.T) {
+		m := &KernelModel{Kernel: RBFKernel(1), C: 10}
+		if err := m.Train(d); err != nil {
+			t.Fatal(err)
+		}
+		mygoml.FloatEqual(t, "training accuracy", 1, mygoml.ModelAccuracy(t, m, d))
+		mygoml.FloatEqual(t, "test accuracy", 1, mygoml.ModelAccuracy(t, m, xor(rnd, 25)))
+	})
+	t.Run("linear", func(t *testing.T) {
+		m := &KernelModel{Kernel: LinearKernel(), C: 10}
+		if err := m.Train(d); err != nil {
+			t.Fatal(err)
+		}
+		if got := mygoml.ModelAccuracy(t, m, d); got > 0.75 {
+			t.Errorf("a linear kernel separates xor with accuracy %f", got)
+		}
+	})
+}
+
+func TestKernelNotConverged(t *testing.T) {
+	rnd := rand.New(rand.NewSource(3))
+	d := blobs(rnd, 50, 1, 1)
+	m := &KernelModel{MaxIter: 2}
+	if err := m.Train(d); err != mygoml.ErrNotConverged {
+		t.Fatalf("expected %v, got %v", mygoml.ErrNotConverged, err)
+	}
+	if m.Converged() {
+		t.Error("converged after 2 iterations")
+	}
+	// the model of the last iteration is kept
+	if _, err := m.Predict(d[0].X); err != nil {
+		t.Error(err)
+	}
+
+	m.MaxIter = 0
+	if err := m.Train(d); err != nil {
+		t.Fatal(err)
+	}
+	if !m.Converged() {
+		t.Error("SMO did not converge")
+	}
+}
@@ -0,0 +1,155 @@
+package svm
+
+import (
+	"math"
+	"mygoml"
+	"mygoml/graddesc"
+	"mygoml/helpers"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// LinearModel is a soft-margin linear SVM trained with Pegasos, stochastic
+// sub-gradient descent on
+//
+//	λ/2·|w|² + mean(max(0, 1 - y·(w·x + b)))
+//
+// with λ = 1/(C·n) for n data points. The bias is not regularized.
+type LinearModel struct {
+	// C trades margin width for training errors, 1 when 0
+	C float64
+	// MaxStep is the number of epochs, 100 when 0
+	MaxStep int
+	// BatchSize is the number of data points per update, 0 picks one from
+	// the size of the data set
+	BatchSize int
+	Strategy  Strategy
+	mc        multiclass
+}
+
+// linear is a trained binary linear SVM, weights holding the bias last.
+type linear struct {
+	weights []float64
+}
+
+func (l *linear) decision(x []float64) float64 {
+	n := len(l.weights) - 1
+	return floats.Dot(l.weights[:n], x) + l.weights[n]
+}
+
+// pegasosUpdater takes steps of 1/(λt) at the t-th update and projects the
+// weights back onto the ball of radius 1/sqrt(λ), which holds the optimum.
+type pegasosUpdater struct {
+	lambda float64
+	t      int
+}
+
+func (u *pegasosUpdater) Update(x []float64, f graddesc.Function, learningRate float64) {
+	u.t++
+	grad := f.Gradient(x)
+	floats.AddScaled(x, -learningRate/(u.lambda*float64(u.t)), grad)
+
+	w := x[:len(x)-1]
+	if norm := floats.Norm(w, 2); norm > 1/math.Sqrt(u.lambda) {
+		floats.Scale(1/(math.Sqrt(u.lambda)*norm), w)
+	}
+}
+
+func (u *pegasosUpdater) Reset() {
+	u.t = 0
+}
+
+func (m *LinearModel) fit(X [][]float64, y []float64) (binary, error) {
+	n, p := len(X), len(X[0])
+	C := m.C
+	if C <= 0 {
+		C = 1
+	}
+	maxStep := m.MaxStep
+	if maxStep <= 0 {
+		maxStep = 100
+	}
+	lambda := 1 / (C * float64(n))
+
+	// one centered data point per row with a trailing 1 for the bias,
+	// centering keeps the unregularized bias close to 0
+	mean := make([]float64, p)
+	for _, row := range X {
+		floats.AddScaled(mean, 1/float64(n), row)
+	}
+	Xb := mat.NewDense(n, p+1, nil)
+	for i, row := range X {
+		floats.SubTo(Xb.RawRowView(i)[:p], row, mean)
+		Xb.Set(i, p, 1)
+	}
+
+	gen := func(indices []int) graddesc.Function {
+		return graddesc.Function{
+			InputSize: p + 1,
+			Mapper: func(w []float64) []float64 {
+				loss := 0.0
+				for _, i := range indices {
+					loss = loss + math.Max(0, 1-y[i]*floats.Dot(w, Xb.RawRowView(i)))
+				}
+				return []float64{lambda/2*floats.Dot(w[:p], w[:p]) + loss/float64(len(indices))}
+			},
+			Gradient: func(w []float64) []float64 {
+				grad := make([]float64, p+1)
+				for _, i := range indices {
+					if xi := Xb.RawRowView(i); y[i]*floats.Dot(w, xi) < 1 {
+						floats.AddScaled(grad, -y[i], xi)
+					}
+				}
+				floats.Scale(1/float64(len(indices)), grad)
+				floats.AddScaled(grad[:p], lambda, w[:p])
+				return grad
+			},
+		}
+	}
+
+	op := graddesc.Optimizer{
+		EpochProvider: helpers.NewEpochProvider(n, m.BatchSize, gen),
+		LearningRate:  1,
+		MaxStep:       maxStep,
+		Updater:       &pegasosUpdater{lambda: lambda},
+	}
+	result, err := op.Optimize(make([]float64, p+1))
+	if result.X == nil {
+		return nil, err
+	}
+	w := result.X
+	w[p] = w[p] - floats.Dot(w[:p], mean)
+	return &linear{weights: w}, err
+}
+
+func (m *LinearModel) Train(dataset mygoml.SupervisedDataSet) error {
+	return m.mc.train(dataset, m.Strategy, m.fit)
+}
+
+// Predict returns the predicted class label.
+func (m *LinearModel) Predict(features []float64) ([]float64, error) {
+	return m.mc.predict(features)
+}
+
+// DecisionFunction returns the signed distance-like score of every binary
+// classifier: one value for two classes, positive for the larger one, one
+// per class for OneVsRest and one per pair of classes for OneVsOne,
+// positive for the larger class of the pair and ordered like the pairs of
+// ensemble.OneVsOne.
+func (m *LinearModel) DecisionFunction(features []float64) ([]float64, error) {
+	return m.mc.decision(features)
+}
+
+// Weights returns the weights of every binary classifier, one per column
+// with the bias in the last row.
+func (m *LinearModel) Weights() mat.Matrix {
+	if m.mc.models == nil {
+		return nil
+	}
+	out := mat.NewDense(m.mc.features+1, len(m.mc.models), nil)
+	for j, model := range m.mc.models {
+		out.SetCol(j, model.(*linear).weights)
+	}
+	return out
+}
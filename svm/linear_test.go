@@ -0,0 +1,55 @@
+package svm
+
+import (
+	"math"
+	"math/rand"
+	"mygoml"
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// TestPegasosAgainstSMO solves the same linear problem with Pegasos and
+// with SMO on a linear kernel, whose weights are Σ αi·yi·xi.
+func TestPegasosAgainstSMO(t *testing.T) {
+	rnd := rand.New(rand.NewSource(4))
+	d := blobs(rnd, 100, 1, 1)
+
+	smo := &KernelModel{Kernel: LinearKernel(), C: 1}
+	if err := smo.Train(d); err != nil {
+		t.Fatal(err)
+	}
+	model := smo.mc.models[0].(*kernelBinary)
+	expected := make([]float64, 3)
+	for i, v := range model.vectors {
+		floats.AddScaled(expected[:2], model.coefficients[i], v)
+	}
+	expected[2] = model.bias
+
+	pegasos := &LinearModel{C: 1, MaxStep: 200}
+	if err := pegasos.Train(d); err != nil {
+		t.Fatal(err)
+	}
+	got := mat.Col(nil, 0, pegasos.Weights())
+	for i := range expected {
+		if math.Abs(expected[i]-got[i]) > 0.05*floats.Norm(expected, 2) {
+			t.Errorf("weights: expected %v, got %v", expected, got)
+			break
+		}
+	}
+
+	test := blobs(rnd, 100, 1, 1)
+	agree := 0
+	for _, p := range test {
+		a, _ := smo.Predict(p.X)
+		b, _ := pegasos.Predict(p.X)
+		if a[0] == b[0] {
+			agree++
+		}
+	}
+	if rate := float64(agree) / float64(len(test)); rate < 0.97 {
+		t.Errorf("the models agree on %f of the test points", rate)
+	}
+	mygoml.FloatEqual(t, "agreement", mygoml.ModelAccuracy(t, smo, test), mygoml.ModelAccuracy(t, pegasos, test))
+}
@@ -0,0 +1,169 @@
+package svm
+
+import (
+	"fmt"
+	"mygoml"
+	"mygoml/ensemble"
+	"sort"
+)
+
+// Strategy chooses how more than two classes are reduced to binary
+// problems.
+type Strategy int
+
+const (
+	// OneVsRest trains one classifier per class against all other classes
+	// and predicts the class with the highest decision value
+	OneVsRest Strategy = iota
+	// OneVsOne trains one classifier per pair of classes and predicts the
+	// class with the most votes, ties going to the most confident class
+	OneVsOne
+)
+
+// binary is a trained two-class classifier, positive decision values
+// meaning the positive class.
+type binary interface {
+	decision(x []float64) float64
+}
+
+// fitFunc trains a binary classifier on rows X with targets y in {-1, 1}.
+type fitFunc func(X [][]float64, y []float64) (binary, error)
+
+// binaryModel lets the multi-class wrappers of the ensemble package train
+// binary classifiers: it trains on the targets 1 and -1 and predicts the
+// decision value.
+type binaryModel struct {
+	fit fitFunc
+	binary
+}
+
+func (b *binaryModel) Train(dataset mygoml.SupervisedDataSet) error {
+	dps := dataset.DataPoints()
+	X := make([][]float64, len(dps))
+	y := make([]float64, len(dps))
+	for i, dp := range dps {
+		X[i] = dp.Features()
+		y[i] = dp.Target()[0]
+	}
+	model, err := b.fit(X, y)
+	if err != nil {
+		return err
+	}
+	b.binary = model
+	return nil
+}
+
+func (b *binaryModel) Predict(features []float64) ([]float64, error) {
+	return []float64{b.decision(features)}, nil
+}
+
+// multiclass holds the binary classifiers of a model and turns their
+// decision values into class labels. The label of a data point is the first
+// component of its target. Two classes need a single classifier, more are
+// handled by ensemble.OneVsRest or ensemble.OneVsOne.
+type multiclass struct {
+	classes []float64
+	// wrapper is nil with two classes
+	wrapper  mygoml.SupervisedModel
+	models   []binary
+	features int
+}
+
+func (mc *multiclass) train(dataset mygoml.SupervisedDataSet, strategy Strategy, fit fitFunc) error {
+	dps := dataset.DataPoints()
+	if len(dps) == 0 {
+		return mygoml.ErrDatasetEmpty
+	}
+
+	X := make([][]float64, len(dps))
+	labels := make([]float64, len(dps))
+	seen := make(map[float64]bool)
+	var classes []float64
+	for i, dp := range dps {
+		X[i] = dp.Features()
+		labels[i] = dp.Target()[0]
+		if !seen[labels[i]] {
+			seen[labels[i]] = true
+			classes = append(classes, labels[i])
+		}
+	}
+	if len(classes) < 2 {
+		return mygoml.ErrIncompatibleDataAndModel("svm needs at least two classes")
+	}
+	sort.Float64s(classes)
+
+	*mc = multiclass{classes: classes, features: len(X[0])}
+	if len(classes) == 2 {
+		model, err := fit(X, signs(labels, classes[1]))
+		if err != nil {
+			return err
+		}
+		mc.models = []binary{model}
+		return nil
+	}
+
+	factory := func() mygoml.SupervisedModel {
+		return &binaryModel{fit: fit}
+	}
+	var models func() []mygoml.SupervisedModel
+	switch strategy {
+	case OneVsRest:
+		w := &ensemble.OneVsRest{New: factory, Negative: -1}
+		mc.wrapper, models = w, w.Models
+	case OneVsOne:
+		w := &ensemble.OneVsOne{New: factory, Negative: -1}
+		mc.wrapper, models = w, w.Models
+	default:
+		return mygoml.ErrIncompatibleDataAndModel(fmt.Sprintf("unknown strategy %d", strategy))
+	}
+	if err := mc.wrapper.Train(dataset); err != nil {
+		mc.wrapper = nil
+		return err
+	}
+	for _, model := range models() {
+		mc.models = append(mc.models, model.(*binaryModel).binary)
+	}
+	return nil
+}
+
+// signs maps the positive class to 1 and every other label to -1.
+func signs(labels []float64, positive float64) []float64 {
+	y := make([]float64, len(labels))
+	for i, label := range labels {
+		y[i] = -1
+		if label == positive {
+			y[i] = 1
+		}
+	}
+	return y
+}
+
+// decision returns the decision value of every binary classifier.
+func (mc *multiclass) decision(features []float64) ([]float64, error) {
+	if mc.models == nil {
+		return nil, mygoml.ErrIncompatibleDataAndModel("model is not trained")
+	}
+	if len(features) != mc.features {
+		msg := fmt.Sprintf("model expects %d features but got %d features", mc.features, len(features))
+		return nil, mygoml.ErrIncompatibleDataAndModel(msg)
+	}
+	out := make([]float64, len(mc.models))
+	for i, model := range mc.models {
+		out[i] = model.decision(features)
+	}
+	return out, nil
+}
+
+func (mc *multiclass) predict(features []float64) ([]float64, error) {
+	decisions, err := mc.decision(features)
+	if err != nil {
+		return nil, err
+	}
+	if mc.wrapper != nil {
+		return mc.wrapper.Predict(features)
+	}
+	if decisions[0] > 0 {
+		return []float64{mc.classes[1]}, nil
+	}
+	return []float64{mc.classes[0]}, nil
+}
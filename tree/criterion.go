@@ -0,0 +1,155 @@
+package tree
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Criterion measures the impurity of the targets of a node. Gini and
+// Entropy grow classification trees, MSE and MAE regression trees.
+type Criterion int
+
+const (
+	Gini Criterion = iota
+	Entropy
+	MSE
+	MAE
+)
+
+func (c Criterion) String() string {
+	switch c {
+	case Gini:
+		return "gini"
+	case Entropy:
+		return "entropy"
+	case MSE:
+		return "mse"
+	case MAE:
+		return "mae"
+	}
+	return fmt.Sprintf("Criterion(%d)", int(c))
+}
+
+func (c Criterion) classification() bool {
+	return c == Gini || c == Entropy
+}
+
+// accumulator keeps the impurity of a set of targets that grows and
+// shrinks one target at a time, which lets a split search sweep over the
+// sorted values of a feature.
+type accumulator interface {
+	add(y float64)
+	remove(y float64)
+	impurity() float64
+}
+
+func (c Criterion) accumulator(classes int) accumulator {
+	switch c {
+	case Gini, Entropy:
+		return &classCounts{counts: make([]float64, classes), entropy: c == Entropy}
+	case MAE:
+		return &absoluteError{}
+	}
+	return &squaredError{}
+}
+
+// classCounts works on targets holding class indices.
+type classCounts struct {
+	counts  []float64
+	total   float64
+	entropy bool
+}
+
+func (a *classCounts) add(y float64) {
+	a.counts[int(y)]++
+	a.total++
+}
+
+func (a *classCounts) remove(y float64) {
+	a.counts[int(y)]--
+	a.total--
+}
+
+func (a *classCounts) impurity() float64 {
+	if a.total == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, count := range a.counts {
+		p := count / a.total
+		if a.entropy {
+			if p > 0 {
+				sum = sum - p*math.Log2(p)
+			}
+		} else {
+			sum = sum + p*p
+		}
+	}
+	if a.entropy {
+		return sum
+	}
+	return 1 - sum
+}
+
+type squaredError struct {
+	sum, squares, total float64
+}
+
+func (a *squaredError) add(y float64) {
+	a.sum = a.sum + y
+	a.squares = a.squares + y*y
+	a.total++
+}
+
+func (a *squaredError) remove(y float64) {
+	a.sum = a.sum - y
+	a.squares = a.squares - y*y
+	a.total--
+}
+
+func (a *squaredError) impurity() float64 {
+	if a.total == 0 {
+		return 0
+	}
+	mean := a.sum / a.total
+	return math.Max(0, a.squares/a.total-mean*mean)
+}
+
+// absoluteError keeps its targets sorted to find their median.
+type absoluteError struct {
+	values []float64
+}
+
+func (a *absoluteError) add(y float64) {
+	i := sort.SearchFloat64s(a.values, y)
+	a.values = append(a.values, 0)
+	copy(a.values[i+1:], a.values[i:])
+	a.values[i] = y
+}
+
+func (a *absoluteError) remove(y float64) {
+	i := sort.SearchFloat64s(a.values, y)
+	a.values = append(a.values[:i], a.values[i+1:]...)
+}
+
+func (a *absoluteError) impurity() float64 {
+	if len(a.values) == 0 {
+		return 0
+	}
+	m := median(a.values)
+	sum := 0.0
+	for _, v := range a.values {
+		sum = sum + math.Abs(v-m)
+	}
+	return sum / float64(len(a.values))
+}
+
+// median of sorted values.
+func median(sorted []float64) float64 {
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
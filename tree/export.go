@@ -0,0 +1,85 @@
+package tree
+
+import (
+	"fmt"
+	"strings"
+)
+
+func featureName(names []string, feature int) string {
+	if feature < len(names) {
+		return names[feature]
+	}
+	return fmt.Sprintf("x[%d]", feature)
+}
+
+func (m *Model) leafText(n *node) string {
+	if m.Criterion.classification() {
+		return fmt.Sprintf("class: %v", n.value)
+	}
+	return fmt.Sprintf("value: %.4g", n.value)
+}
+
+// Text renders the tree as indented rules, one line per node. names
+// labels the features, x[i] is used for features without a name.
+func (m *Model) Text(names []string) string {
+	if m.root == nil {
+		return ""
+	}
+	var sb strings.Builder
+	var walk func(n *node, depth int)
+	walk = func(n *node, depth int) {
+		indent := strings.Repeat("|   ", depth) + "|--- "
+		if n.leaf() {
+			sb.WriteString(indent + m.leafText(n) + "\n")
+			return
+		}
+		name := featureName(names, n.feature)
+		fmt.Fprintf(&sb, "%s%s <= %.4g\n", indent, name, n.threshold)
+		walk(n.left, depth+1)
+		fmt.Fprintf(&sb, "%s%s >  %.4g\n", indent, name, n.threshold)
+		walk(n.right, depth+1)
+	}
+	walk(m.root, 0)
+	return sb.String()
+}
+
+// DOT renders the tree in the Graphviz DOT language, e.g. for
+// `dot -Tpng tree.dot -o tree.png`. names labels the features, x[i] is used
+// for features without a name.
+func (m *Model) DOT(names []string) string {
+	var sb strings.Builder
+	sb.WriteString("digraph Tree {\n")
+	sb.WriteString("\tnode [shape=box, fontname=\"helvetica\"];\n")
+	sb.WriteString("\tedge [fontname=\"helvetica\"];\n")
+	if m.root == nil {
+		sb.WriteString("}\n")
+		return sb.String()
+	}
+
+	id := 0
+	var walk func(n *node) int
+	walk = func(n *node) int {
+		current := id
+		id++
+		var label []string
+		if !n.leaf() {
+			label = append(label, fmt.Sprintf("%s <= %.4g", featureName(names, n.feature), n.threshold))
+		}
+		label = append(label,
+			fmt.Sprintf("%s = %.4g", m.Criterion, n.impurity),
+			fmt.Sprintf("samples = %d", n.samples),
+			m.leafText(n))
+		fmt.Fprintf(&sb, "\t%d [label=%q];\n", current, strings.Join(label, "\n"))
+		if n.leaf() {
+			return current
+		}
+		left := walk(n.left)
+		fmt.Fprintf(&sb, "\t%d -> %d [label=\"true\"];\n", current, left)
+		right := walk(n.right)
+		fmt.Fprintf(&sb, "\t%d -> %d [label=\"false\"];\n", current, right)
+		return current
+	}
+	walk(m.root)
+	sb.WriteString("}\n")
+	return sb.String()
+}
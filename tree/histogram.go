@@ -0,0 +1,131 @@
+package tree
+
+import (
+	"fmt"
+	"mygoml"
+	"sort"
+)
+
+// Histogram holds data points whose features are binned for histogram-based
+// split finding. Binning sorts every feature, so models grown on the same
+// data points, or on subsets of them, can share one Histogram through
+// FitHistogram instead of binning again with MaxBins.
+type Histogram struct {
+	// bins[f][i] is the bin of feature f of data point i and edges[f][k]
+	// separates bin k from bin k+1
+	bins  [][]int
+	edges [][]float64
+}
+
+// NewHistogram puts the value of every feature of every row of X in one of
+// at most maxBins bins. Bin edges lie halfway between two distinct values,
+// so that a split between bins is an ordinary threshold.
+func NewHistogram(X [][]float64, maxBins int) (*Histogram, error) {
+	if len(X) == 0 {
+		return nil, mygoml.ErrDatasetEmpty
+	}
+	if maxBins < 1 {
+		msg := fmt.Sprintf("a histogram needs at least 1 bin but got %d", maxBins)
+		return nil, mygoml.ErrIncompatibleDataAndModel(msg)
+	}
+	n, features := len(X), len(X[0])
+	h := &Histogram{bins: make([][]int, features), edges: make([][]float64, features)}
+	values := make([]float64, n)
+	for f := 0; f < features; f++ {
+		for i, row := range X {
+			if len(row) != features {
+				msg := fmt.Sprintf("data points have %d and %d features", features, len(row))
+				return nil, mygoml.ErrIncompatibleDataAndModel(msg)
+			}
+			values[i] = row[f]
+		}
+		sort.Float64s(values)
+		var distinct []float64
+		for i, v := range values {
+			if i == 0 || v != values[i-1] {
+				distinct = append(distinct, v)
+			}
+		}
+
+		// keep the edges after evenly spaced quantiles of the data
+		var edges []float64
+		if len(distinct) <= maxBins {
+			for k := 0; k+1 < len(distinct); k++ {
+				edges = append(edges, (distinct[k]+distinct[k+1])/2)
+			}
+		} else {
+			for k := 1; k < maxBins; k++ {
+				q := values[k*n/maxBins]
+				j := sort.SearchFloat64s(distinct, q)
+				if j+1 >= len(distinct) {
+					break
+				}
+				edge := (distinct[j] + distinct[j+1]) / 2
+				if len(edges) == 0 || edge > edges[len(edges)-1] {
+					edges = append(edges, edge)
+				}
+			}
+		}
+
+		h.edges[f] = edges
+		h.bins[f] = make([]int, n)
+		for i, row := range X {
+			h.bins[f][i] = sort.SearchFloat64s(edges, row[f])
+		}
+	}
+	return h, nil
+}
+
+// Rows returns the histogram of the data points rows, in that order, with
+// the same bin edges.
+func (h *Histogram) Rows(rows []int) *Histogram {
+	sub := &Histogram{bins: make([][]int, len(h.bins)), edges: h.edges}
+	for f, bins := range h.bins {
+		sub.bins[f] = make([]int, len(rows))
+		for k, i := range rows {
+			sub.bins[f][k] = bins[i]
+		}
+	}
+	return sub
+}
+
+// size returns the number of data points and features of h.
+func (h *Histogram) size() (int, int) {
+	if len(h.bins) == 0 {
+		return 0, 0
+	}
+	return len(h.bins[0]), len(h.bins)
+}
+
+// histogramSplit groups the data points of a node by the bin of feature f
+// and offers consider every bin edge as a threshold. left must be empty and
+// right is filled here.
+func (b *builder) histogramSplit(f int, indices []int, left, right accumulator, consider func(f int, threshold, score float64)) {
+	edges := b.histogram.edges[f]
+	groups := make([][]int, len(edges)+1)
+	for _, i := range indices {
+		bin := b.histogram.bins[f][i]
+		groups[bin] = append(groups[bin], i)
+		right.add(b.y[i])
+	}
+
+	nl := 0
+	for k := 0; k < len(edges); k++ {
+		if len(groups[k]) == 0 {
+			continue
+		}
+		for _, i := range groups[k] {
+			left.add(b.y[i])
+			right.remove(b.y[i])
+		}
+		nl = nl + len(groups[k])
+		nr := len(indices) - nl
+		if nr == 0 {
+			break
+		}
+		if nl < b.minLeaf || nr < b.minLeaf {
+			continue
+		}
+		consider(f, edges[k], float64(nl)*left.impurity()+float64(nr)*right.impurity())
+	}
+}
@@ -0,0 +1,99 @@
+package tree
+
+import "math"
+
+// Minimal cost-complexity pruning measures a tree T by
+//
+//	R(T) + α·|leaves(T)|
+//
+// where R(T) is the impurity of the leaves weighted by the fraction of the
+// data points reaching them. The subtree under a node t stops paying off
+// once α reaches (R(t) - R(T_t)) / (|leaves(T_t)| - 1), and the weakest
+// links, the nodes with the smallest such α, are pruned first.
+
+// cost is the weighted impurity of n alone.
+func cost(n *node, total int) float64 {
+	return float64(n.samples) / float64(total) * n.impurity
+}
+
+// subtreeCost returns the weighted impurity of the leaves under n and
+// their number.
+func subtreeCost(n *node, total int) (float64, int) {
+	if n.leaf() {
+		return cost(n, total), 1
+	}
+	lc, ll := subtreeCost(n.left, total)
+	rc, rl := subtreeCost(n.right, total)
+	return lc + rc, ll + rl
+}
+
+// weakestLink returns the smallest effective alpha of the internal nodes
+// under n, +Inf when n is a leaf.
+func weakestLink(n *node, total int) float64 {
+	if n.leaf() {
+		return math.Inf(1)
+	}
+	r, l := subtreeCost(n, total)
+	alpha := (cost(n, total) - r) / float64(l-1)
+	return math.Min(alpha, math.Min(weakestLink(n.left, total), weakestLink(n.right, total)))
+}
+
+// collapse turns every internal node under n whose effective alpha is at
+// most alpha into a leaf.
+func collapse(n *node, alpha float64, total int) {
+	if n.leaf() {
+		return
+	}
+	r, l := subtreeCost(n, total)
+	if (cost(n, total)-r)/float64(l-1) <= alpha {
+		n.left, n.right = nil, nil
+		return
+	}
+	collapse(n.left, alpha, total)
+	collapse(n.right, alpha, total)
+}
+
+// prune removes weakest links until every remaining one costs more than
+// alpha. total is the number of data points the tree was grown on.
+func prune(root *node, alpha float64, total int) {
+	for {
+		weakest := weakestLink(root, total)
+		if weakest > alpha {
+			return
+		}
+		collapse(root, weakest+1e-12, total)
+	}
+}
+
+func (n *node) clone() *node {
+	c := *n
+	c.distribution = append([]float64(nil), n.distribution...)
+	if !n.leaf() {
+		c.left, c.right = n.left.clone(), n.right.clone()
+	}
+	return &c
+}
+
+// PruningPath returns the effective alphas at which cost-complexity
+// pruning of the trained tree removes subtrees, starting with 0 for the
+// unpruned tree, and the weighted leaf impurity of the tree pruned at each
+// of them. Setting CCPAlpha between two consecutive alphas and training
+// again yields the tree of the first one.
+func (m *Model) PruningPath() (alphas, impurities []float64) {
+	if m.root == nil {
+		return nil, nil
+	}
+	root := m.root.clone()
+	total := root.samples
+	alpha := 0.0
+	for {
+		r, _ := subtreeCost(root, total)
+		alphas = append(alphas, alpha)
+		impurities = append(impurities, r)
+		if root.leaf() {
+			return alphas, impurities
+		}
+		alpha = math.Max(alpha, weakestLink(root, total))
+		collapse(root, alpha+1e-12, total)
+	}
+}
@@ -0,0 +1,471 @@
+package tree
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"mygoml"
+	"sort"
+)
+
+// Model is a CART decision tree. Gini and Entropy criteria grow a
+// classification tree predicting the class label, the first component of
+// the targets. MSE and MAE grow a regression tree predicting the mean or
+// the median of the first component of the targets.
+type Model struct {
+	Criterion Criterion
+	// MaxDepth limits the depth of the tree, unlimited when 0
+	MaxDepth int
+	// MinSamplesSplit is the number of data points a node needs to be
+	// split, 2 when below 2
+	MinSamplesSplit int
+	// MinSamplesLeaf is the number of data points each child of a split
+	// needs at least, 1 when 0
+	MinSamplesLeaf int
+	// CCPAlpha prunes every subtree whose cost-complexity measure is at
+	// most CCPAlpha after the tree is grown, no pruning when 0
+	CCPAlpha float64
+	// MaxFeatures is the number of features drawn at random and searched
+	// at every split, all features when 0
+	MaxFeatures int
+	Splitter    Splitter
+	// Rand is the source of MaxFeatures and of the Random splitter, the
+	// math/rand default source when nil
+	Rand *rand.Rand
+	// MaxBins, when set, bins every feature into at most MaxBins quantile
+	// bins before the tree grows and the Best splitter only searches the
+	// bin edges, which is much faster on large data sets
+	MaxBins     int
+	root        *node
+	leafNodes   []*node
+	classes     []float64
+	features    int
+	importances []float64
+}
+
+// Splitter chooses the threshold of every split.
+type Splitter int
+
+const (
+	// Best searches every threshold of a feature
+	Best Splitter = iota
+	// Random draws one threshold per feature uniformly between its lowest
+	// and highest value in the node, as extremely randomized trees do
+	Random
+)
+
+// node is a leaf when it has no children. Every node keeps the prediction
+// it would make as a leaf so that pruning only drops children.
+type node struct {
+	feature     int
+	threshold   float64
+	left, right *node
+	value       float64
+	// distribution holds the fraction of each class in the node
+	distribution []float64
+	samples      int
+	impurity     float64
+	// id numbers the leaves from left to right
+	id int
+}
+
+func (n *node) leaf() bool {
+	return n.left == nil
+}
+
+// builder holds the training data while the tree grows.
+type builder struct {
+	m *Model
+	X [][]float64
+	// y holds class indices for classification trees
+	y        []float64
+	minSplit int
+	minLeaf  int
+	// histogram holds the binned features when MaxBins is set or the
+	// tree is grown by FitHistogram
+	histogram *Histogram
+}
+
+func (m *Model) Train(dataset mygoml.SupervisedDataSet) error {
+	dps := dataset.DataPoints()
+	if len(dps) == 0 {
+		return mygoml.ErrDatasetEmpty
+	}
+	X := make([][]float64, len(dps))
+	y := make([]float64, len(dps))
+	for i, dp := range dps {
+		X[i] = dp.Features()
+		y[i] = dp.Target()[0]
+	}
+	return m.Fit(X, y)
+}
+
+// Fit grows the tree on rows X with targets y, the first components of
+// the targets Train would use.
+func (m *Model) Fit(X [][]float64, y []float64) error {
+	return m.fit(X, y, nil)
+}
+
+// FitHistogram is Fit with the features already binned in histogram, row i
+// of X being data point i of histogram. MaxBins is ignored and the Best
+// splitter only searches the bin edges of histogram.
+func (m *Model) FitHistogram(X [][]float64, y []float64, histogram *Histogram) error {
+	if n, features := histogram.size(); n != len(X) || (n > 0 && features != len(X[0])) {
+		msg := fmt.Sprintf("histogram has %d data points of %d features", n, features)
+		return mygoml.ErrIncompatibleDataAndModel(msg)
+	}
+	return m.fit(X, y, histogram)
+}
+
+func (m *Model) fit(X [][]float64, y []float64, histogram *Histogram) error {
+	if len(X) == 0 {
+		return mygoml.ErrDatasetEmpty
+	}
+	if len(X) != len(y) {
+		msg := fmt.Sprintf("%d data points but %d targets", len(X), len(y))
+		return mygoml.ErrIncompatibleDataAndModel(msg)
+	}
+	m.features = len(X[0])
+	m.classes = nil
+	for _, row := range X {
+		if len(row) != m.features {
+			msg := fmt.Sprintf("data points have %d and %d features", m.features, len(row))
+			return mygoml.ErrIncompatibleDataAndModel(msg)
+		}
+	}
+
+	b := &builder{m: m, X: X, y: y, minSplit: m.MinSamplesSplit, minLeaf: m.MinSamplesLeaf}
+	if b.minSplit < 2 {
+		b.minSplit = 2
+	}
+	if b.minLeaf < 1 {
+		b.minLeaf = 1
+	}
+	if m.Criterion.classification() {
+		index := make(map[float64]int)
+		for _, label := range y {
+			if _, ok := index[label]; !ok {
+				index[label] = 0
+				m.classes = append(m.classes, label)
+			}
+		}
+		sort.Float64s(m.classes)
+		for i, class := range m.classes {
+			index[class] = i
+		}
+		b.y = make([]float64, len(y))
+		for i, label := range y {
+			b.y[i] = float64(index[label])
+		}
+	}
+
+	b.histogram = histogram
+	if histogram == nil && m.MaxBins > 0 {
+		var err error
+		if b.histogram, err = NewHistogram(X, m.MaxBins); err != nil {
+			return err
+		}
+	}
+
+	indices := make([]int, len(X))
+	for i := range indices {
+		indices[i] = i
+	}
+	m.root = b.grow(indices, 0)
+	if m.CCPAlpha > 0 {
+		prune(m.root, m.CCPAlpha, len(X))
+	}
+	m.numberLeaves()
+	m.importances = importances(m.root, m.features)
+	return nil
+}
+
+// numberLeaves sets the id of every leaf, from left to right.
+func (m *Model) numberLeaves() {
+	m.leafNodes = nil
+	var walk func(n *node)
+	walk = func(n *node) {
+		if n.leaf() {
+			n.id = len(m.leafNodes)
+			m.leafNodes = append(m.leafNodes, n)
+			return
+		}
+		walk(n.left)
+		walk(n.right)
+	}
+	walk(m.root)
+}
+
+func (b *builder) grow(indices []int, depth int) *node {
+	n := b.leaf(indices)
+	if len(indices) < b.minSplit || len(indices) < 2*b.minLeaf || n.impurity == 0 {
+		return n
+	}
+	if b.m.MaxDepth > 0 && depth >= b.m.MaxDepth {
+		return n
+	}
+	feature, threshold, ok := b.split(indices)
+	if !ok {
+		return n
+	}
+
+	var left, right []int
+	for _, i := range indices {
+		if b.X[i][feature] <= threshold {
+			left = append(left, i)
+		} else {
+			right = append(right, i)
+		}
+	}
+	n.feature, n.threshold = feature, threshold
+	n.left = b.grow(left, depth+1)
+	n.right = b.grow(right, depth+1)
+	return n
+}
+
+// leaf computes the prediction and the impurity of a node.
+func (b *builder) leaf(indices []int) *node {
+	n := &node{samples: len(indices)}
+	acc := b.m.Criterion.accumulator(len(b.m.classes))
+	for _, i := range indices {
+		acc.add(b.y[i])
+	}
+	n.impurity = acc.impurity()
+
+	switch b.m.Criterion {
+	case Gini, Entropy:
+		n.distribution = make([]float64, len(b.m.classes))
+		for _, i := range indices {
+			n.distribution[int(b.y[i])] += 1 / float64(len(indices))
+		}
+		best := 0
+		for c, p := range n.distribution {
+			if p > n.distribution[best] {
+				best = c
+			}
+		}
+		n.value = b.m.classes[best]
+	case MAE:
+		n.value = median(acc.(*absoluteError).values)
+	default:
+		sum := 0.0
+		for _, i := range indices {
+			sum = sum + b.y[i]
+		}
+		n.value = sum / float64(len(indices))
+	}
+	return n
+}
+
+// split finds the feature and threshold minimizing the impurity of the
+// children weighted by their size. With MaxFeatures only that many
+// features, not constant within the node, are searched.
+func (b *builder) split(indices []int) (int, float64, bool) {
+	bestFeature, bestThreshold, bestScore := -1, 0.0, 0.0
+	consider := func(f int, threshold, score float64) {
+		if bestFeature < 0 || score < bestScore {
+			bestFeature, bestThreshold, bestScore = f, threshold, score
+		}
+	}
+
+	order := b.features()
+	searched := 0
+	sorted := make([]int, len(indices))
+	for _, f := range order {
+		if b.m.MaxFeatures > 0 && searched >= b.m.MaxFeatures {
+			break
+		}
+		low, high := b.X[indices[0]][f], b.X[indices[0]][f]
+		for _, i := range indices {
+			low, high = math.Min(low, b.X[i][f]), math.Max(high, b.X[i][f])
+		}
+		if low == high {
+			continue
+		}
+		searched++
+
+		left := b.m.Criterion.accumulator(len(b.m.classes))
+		right := b.m.Criterion.accumulator(len(b.m.classes))
+		if b.histogram != nil && b.m.Splitter == Best {
+			b.histogramSplit(f, indices, left, right, consider)
+			continue
+		}
+		if b.m.Splitter == Random {
+			threshold := low + b.float64()*(high-low)
+			nl, nr := 0, 0
+			for _, i := range indices {
+				if b.X[i][f] <= threshold {
+					left.add(b.y[i])
+					nl++
+				} else {
+					right.add(b.y[i])
+					nr++
+				}
+			}
+			if nl >= b.minLeaf && nr >= b.minLeaf {
+				consider(f, threshold, float64(nl)*left.impurity()+float64(nr)*right.impurity())
+			}
+			continue
+		}
+
+		copy(sorted, indices)
+		sort.Slice(sorted, func(p, q int) bool {
+			return b.X[sorted[p]][f] < b.X[sorted[q]][f]
+		})
+		for _, i := range sorted {
+			right.add(b.y[i])
+		}
+		for k := 0; k < len(sorted)-1; k++ {
+			i, next := sorted[k], sorted[k+1]
+			left.add(b.y[i])
+			right.remove(b.y[i])
+			nl, nr := k+1, len(sorted)-k-1
+			if nl < b.minLeaf || nr < b.minLeaf || b.X[i][f] == b.X[next][f] {
+				continue
+			}
+			score := float64(nl)*left.impurity() + float64(nr)*right.impurity()
+			consider(f, (b.X[i][f]+b.X[next][f])/2, score)
+		}
+	}
+	return bestFeature, bestThreshold, bestFeature >= 0
+}
+
+// features returns the order in which split searches features, shuffled
+// when only some of them are searched.
+func (b *builder) features() []int {
+	if b.m.MaxFeatures <= 0 || b.m.MaxFeatures >= b.m.features {
+		order := make([]int, b.m.features)
+		for f := range order {
+			order[f] = f
+		}
+		return order
+	}
+	if b.m.Rand != nil {
+		return b.m.Rand.Perm(b.m.features)
+	}
+	return rand.Perm(b.m.features)
+}
+
+func (b *builder) float64() float64 {
+	if b.m.Rand != nil {
+		return b.m.Rand.Float64()
+	}
+	return rand.Float64()
+}
+
+func (m *Model) find(features []float64) (*node, error) {
+	if m.root == nil {
+		return nil, mygoml.ErrIncompatibleDataAndModel("model is not trained")
+	}
+	if len(features) != m.features {
+		msg := fmt.Sprintf("model expects %d features but got %d features", m.features, len(features))
+		return nil, mygoml.ErrIncompatibleDataAndModel(msg)
+	}
+	n := m.root
+	for !n.leaf() {
+		if features[n.feature] <= n.threshold {
+			n = n.left
+		} else {
+			n = n.right
+		}
+	}
+	return n, nil
+}
+
+// Predict returns the class label or the regression value of the leaf
+// features fall into.
+func (m *Model) Predict(features []float64) ([]float64, error) {
+	n, err := m.find(features)
+	if err != nil {
+		return nil, err
+	}
+	return []float64{n.value}, nil
+}
+
+// PredictProba returns the fraction of each class, in the order of
+// Classes, in the leaf features fall into.
+func (m *Model) PredictProba(features []float64) ([]float64, error) {
+	if !m.Criterion.classification() {
+		return nil, mygoml.ErrIncompatibleDataAndModel("regression trees have no class probabilities")
+	}
+	n, err := m.find(features)
+	if err != nil {
+		return nil, err
+	}
+	return append([]float64(nil), n.distribution...), nil
+}
+
+// LeafIndex returns the index of the leaf features fall into, the leaves
+// being numbered from left to right starting at 0.
+func (m *Model) LeafIndex(features []float64) (int, error) {
+	n, err := m.find(features)
+	if err != nil {
+		return 0, err
+	}
+	return n.id, nil
+}
+
+// SetLeafValue replaces the prediction of the leaf with the given index,
+// as gradient boosting does to minimize its loss within every leaf.
+func (m *Model) SetLeafValue(leaf int, value float64) {
+	m.leafNodes[leaf].value = value
+}
+
+// Classes returns the sorted class labels of a classification tree.
+func (m *Model) Classes() []float64 {
+	return append([]float64(nil), m.classes...)
+}
+
+// FeatureImportances returns the total impurity decrease brought by each
+// feature, weighted by the number of data points reaching the split and
+// normalized to sum to 1.
+func (m *Model) FeatureImportances() []float64 {
+	return append([]float64(nil), m.importances...)
+}
+
+// Depth returns the length of the longest path from the root to a leaf.
+func (m *Model) Depth() int {
+	var depth func(n *node) int
+	depth = func(n *node) int {
+		if n == nil || n.leaf() {
+			return 0
+		}
+		l, r := depth(n.left), depth(n.right)
+		if l > r {
+			return l + 1
+		}
+		return r + 1
+	}
+	return depth(m.root)
+}
+
+// Leaves returns the number of leaves of the tree.
+func (m *Model) Leaves() int {
+	return len(m.leafNodes)
+}
+
+func importances(root *node, features int) []float64 {
+	out := make([]float64, features)
+	var walk func(n *node)
+	walk = func(n *node) {
+		if n.leaf() {
+			return
+		}
+		l, r := n.left, n.right
+		out[n.feature] += float64(n.samples)*n.impurity -
+			float64(l.samples)*l.impurity - float64(r.samples)*r.impurity
+		walk(l)
+		walk(r)
+	}
+	walk(root)
+	total := 0.0
+	for _, v := range out {
+		total = total + v
+	}
+	if total > 0 {
+		for i := range out {
+			out[i] = out[i] / total
+		}
+	}
+	return out
+}
@@ -0,0 +1,187 @@
+package tree
+
+import (
+	"math"
+	"math/rand"
+	"mygoml"
+	"testing"
+)
+
+// quadrants labels points of the unit square 0, 1 or 2 by the quadrant
+// they fall in, the upper quadrants sharing class 2. A fraction noise of
+// the labels is replaced at random.
+func quadrants(rnd *rand.Rand, n int, noise float64) mygoml.Points {
+	var d mygoml.Points
+	for i := 0; i < n; i++ {
+		x, y := rnd.Float64(), rnd.Float64()
+		class := 2.0
+		if y < 0.5 {
+			class = 0
+			if x >= 0.5 {
+				class = 1
+			}
+		}
+		if rnd.Float64() < noise {
+			class = float64(rnd.Intn(3))
+		}
+		d = append(d, mygoml.Point{X: []float64{x, y}, Y: class})
+	}
+	return d
+}
+
+func TestClassification(t *testing.T) {
+	d := quadrants(rand.New(rand.NewSource(1)), 200, 0)
+	for _, criterion := range []Criterion{Gini, Entropy} {
+		t.Run(criterion.String(), func(t *testing.T) {
+			m := &Model{Criterion: criterion}
+			if err := m.Train(d); err != nil {
+				t.Fatal(err)
+			}
+			if m.Leaves() != 3 || m.Depth() != 2 {
+				t.Errorf("expected 3 leaves at depth 2, got %d leaves at depth %d", m.Leaves(), m.Depth())
+			}
+			tests := map[float64][]float64{0: {0.2, 0.1}, 1: {0.8, 0.3}, 2: {0.4, 0.9}}
+			for class, features := range tests {
+				out, err := m.Predict(features)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if out[0] != class {
+					t.Errorf("expected class %g for %v, got %g", class, features, out[0])
+				}
+				proba, err := m.PredictProba(features)
+				if err != nil {
+					t.Fatal(err)
+				}
+				mygoml.FloatEqual(t, "probability", 1, proba[int(class)])
+			}
+			if _, err := m.Predict([]float64{1}); err == nil {
+				t.Error("expected an error for the wrong number of features")
+			}
+		})
+	}
+}
+
+func TestRegression(t *testing.T) {
+	var d mygoml.Points
+	for i := 0; i < 100; i++ {
+		x := float64(i) / 100
+		y := 1.0
+		if x >= 0.3 {
+			y = 5
+		}
+		d = append(d, mygoml.Point{X: []float64{x}, Y: y})
+	}
+	for _, criterion := range []Criterion{MSE, MAE} {
+		t.Run(criterion.String(), func(t *testing.T) {
+			m := &Model{Criterion: criterion, MaxDepth: 1}
+			if err := m.Train(d); err != nil {
+				t.Fatal(err)
+			}
+			for x, y := range map[float64]float64{0.1: 1, 0.29: 1, 0.31: 5, 0.9: 5} {
+				out, err := m.Predict([]float64{x})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if out[0] != y {
+					t.Errorf("expected %g at %g, got %g", y, x, out[0])
+				}
+			}
+		})
+	}
+}
+
+func TestPruning(t *testing.T) {
+	d := quadrants(rand.New(rand.NewSource(2)), 300, 0.2)
+	full := &Model{}
+	if err := full.Train(d); err != nil {
+		t.Fatal(err)
+	}
+	alphas, impurities := full.PruningPath()
+	if len(alphas) < 3 || alphas[0] != 0 {
+		t.Fatalf("expected a pruning path starting at 0, got %v", alphas)
+	}
+	for k := 1; k < len(alphas); k++ {
+		if alphas[k] < alphas[k-1] || impurities[k] < impurities[k-1]-1e-12 {
+			t.Errorf("expected non-decreasing alphas and impurities, got %v and %v", alphas, impurities)
+			break
+		}
+	}
+	if last := impurities[len(impurities)-1]; math.Abs(last-impurities[0]) < 1e-9 {
+		t.Error("expected pruning to increase the leaf impurity")
+	}
+
+	// pruning the leaves grown on noisy labels gives a smaller tree that
+	// predicts clean data better than the overgrown one
+	pruned := &Model{CCPAlpha: 0.02}
+	if err := pruned.Train(d); err != nil {
+		t.Fatal(err)
+	}
+	if pruned.Leaves() >= full.Leaves() {
+		t.Errorf("expected fewer than %d leaves, got %d", full.Leaves(), pruned.Leaves())
+	}
+	clean := quadrants(rand.New(rand.NewSource(3)), 300, 0)
+	if a, b := mygoml.ModelAccuracy(t, pruned, clean), mygoml.ModelAccuracy(t, full, clean); a <= b {
+		t.Errorf("expected the pruned accuracy %g to beat the unpruned %g", a, b)
+	}
+
+	stump := &Model{CCPAlpha: alphas[len(alphas)-1] + 1}
+	if err := stump.Train(d); err != nil {
+		t.Fatal(err)
+	}
+	if stump.Leaves() != 1 {
+		t.Errorf("expected a single leaf, got %d", stump.Leaves())
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	d := quadrants(rand.New(rand.NewSource(4)), 200, 0.1)
+	X := make([][]float64, len(d))
+	y := make([]float64, len(d))
+	for i, p := range d {
+		X[i], y[i] = p.X, p.Y
+	}
+
+	binned := &Model{MaxBins: 16}
+	if err := binned.Fit(X, y); err != nil {
+		t.Fatal(err)
+	}
+	h, err := NewHistogram(X, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shared := &Model{}
+	if err := shared.FitHistogram(X, y, h); err != nil {
+		t.Fatal(err)
+	}
+	if a, b := binned.Text(nil), shared.Text(nil); a != b {
+		t.Errorf("expected the same tree from MaxBins and a shared histogram, got\n%s\nand\n%s", a, b)
+	}
+
+	// a subset grown on the rows of the shared histogram only splits at
+	// its edges
+	rows := []int{}
+	for i := 0; i < len(X); i = i + 2 {
+		rows = append(rows, i)
+	}
+	Xs, ys := make([][]float64, len(rows)), make([]float64, len(rows))
+	for k, i := range rows {
+		Xs[k], ys[k] = X[i], y[i]
+	}
+	subset := &Model{MaxDepth: 1}
+	if err := subset.FitHistogram(Xs, ys, h.Rows(rows)); err != nil {
+		t.Fatal(err)
+	}
+	threshold := subset.root.threshold
+	found := false
+	for _, edge := range h.edges[subset.root.feature] {
+		found = found || edge == threshold
+	}
+	if !found {
+		t.Errorf("expected the threshold %g to be an edge of the histogram", threshold)
+	}
+
+	if err := subset.FitHistogram(Xs, ys, h); err == nil {
+		t.Error("expected an error for a histogram of other data points")
+	}
+}
@@ -14,6 +14,24 @@ type Cluster interface {
 	Members() []UnsupervisedDataPoint
 }
 
+// BasicCluster is a Cluster that only keeps its members. Models whose
+// clusters carry more, like a center, embed it.
+type BasicCluster struct {
+	members []UnsupervisedDataPoint
+}
+
+func (c *BasicCluster) Add(p UnsupervisedDataPoint) {
+	c.members = append(c.members, p)
+}
+
+func (c *BasicCluster) Reset() {
+	c.members = nil
+}
+
+func (c *BasicCluster) Members() []UnsupervisedDataPoint {
+	return append([]UnsupervisedDataPoint(nil), c.members...)
+}
+
 type UnsupervisedModel interface {
 	Clustering(UnsupervisedDataSet) []Cluster
 }